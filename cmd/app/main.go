@@ -2,12 +2,17 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"main/internal/cli"
 	"main/internal/config"
 	"main/internal/db"
 	"main/internal/handler"
+	"main/internal/rpc"
 	"main/internal/subscription"
+	"main/internal/webhook"
 	"main/pkg/logger"
 	"main/pkg/postgres"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -19,6 +24,18 @@ func main() {
 	logger := logger.NewLogger()
 	config := config.GetConfig()
 
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+			fmt.Print(cli.Usage())
+			return
+		}
+		err := cli.Run(context.Background(), os.Args[1:], config, logger)
+		if err != nil {
+			logger.Fatalln(err)
+		}
+		return
+	}
+
 	pgxPool, err := postgres.NewPool(context.Background(), 5, *config)
 
 	if err != nil {
@@ -36,17 +53,34 @@ func main() {
 	router := gin.Default()
 	storage := db.NewDataBase(pgxPool, logger)
 
-	service := subscription.NewService(storage, logger)
+	service := subscription.NewService(storage, config, logger)
 
 	handler := handler.NewHandler(router, service, logger)
 	handler.Register()
 
+	grpcServer := rpc.NewServer(service, logger)
+	listener, err := net.Listen("tcp", config.GRPC.Addr)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	go func() {
+		logger.Infof("grpc server listening on %s", config.GRPC.Addr)
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.Fatalln(err)
+		}
+	}()
+
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	go webhook.NewDispatcher(storage, service, config, logger).Run(dispatchCtx)
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		<-sigChan
 		logger.Infoln("Interrupt signal received. Exiting...")
+		grpcServer.GracefulStop()
+		stopDispatch()
 		pgxPool.Close()
 		os.Exit(0)
 	}()
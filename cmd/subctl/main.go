@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"main/internal/subctl"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	env := &subctl.Env{
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		ConfigPath: subctl.DefaultConfigPath(),
+	}
+	if err := subctl.Run(ctx, env, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "subctl:", err)
+		stop()
+		os.Exit(1)
+	}
+}
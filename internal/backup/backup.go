@@ -0,0 +1,444 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"main/internal/db"
+	"main/internal/model"
+	"main/internal/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Archive layout, one JSON document per line:
+//
+//	{"type":"header","format":"sub_service-backup","version":2,"created_at":"..."}
+//	{"type":"record","table":"subscriptions","data":{...}}
+//	...
+//	{"type":"footer","counts":{"subscriptions":2,...},"checksum":"sha256:..."}
+//
+// The checksum covers every byte before the footer line. Records hold plain
+// values only, so an archive written from one storage can be restored into
+// any other db.Storage implementation.
+//
+// Version 2 added the webhooks and subscription_expirations tables, version 3
+// the user_erasures tombstones. Older archives restore without them.
+const (
+	Format  = "sub_service-backup"
+	Version = 3
+)
+
+// Tables are the tables of an archive in the order they are restored.
+var Tables = []string{model.TableSubscriptions, model.TableScheduledPrices, model.TableEvents,
+	model.TableWebhooks, model.TableExpirations, model.TableErasures}
+
+const (
+	lineHeader = "header"
+	lineRecord = "record"
+	lineFooter = "footer"
+)
+
+const maxLineSize = 1 << 20
+
+type line struct {
+	Type      string          `json:"type"`
+	Format    string          `json:"format,omitempty"`
+	Version   int             `json:"version,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+	Table     string          `json:"table,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Counts    map[string]int  `json:"counts,omitempty"`
+	Checksum  string          `json:"checksum,omitempty"`
+}
+
+type Manifest struct {
+	Version   int
+	CreatedAt time.Time
+	Counts    map[string]int
+	Checksum  string
+}
+
+type writer struct {
+	w      *bufio.Writer
+	hash   hash.Hash
+	counts map[string]int
+}
+
+// Write streams every table of the storage into w, all read from one
+// snapshot of the data.
+func Write(ctx context.Context, storage db.Storage, w io.Writer) (manifest Manifest, err error) {
+	aw := &writer{
+		w:      bufio.NewWriter(w),
+		hash:   sha256.New(),
+		counts: make(map[string]int),
+	}
+	manifest.Version = Version
+	manifest.CreatedAt = time.Now().UTC()
+	err = aw.writeLine(line{Type: lineHeader, Format: Format, Version: Version, CreatedAt: &manifest.CreatedAt})
+	if err != nil {
+		return manifest, err
+	}
+
+	err = storage.ReadSnapshot(ctx, func(snapshot db.Snapshot) error {
+		err := snapshot.IterateList(ctx, model.ListFilter{}, func(dto model.SubscriptionDTO) error {
+			return aw.writeRecord(model.TableSubscriptions, subscriptionToRecord(dto))
+		})
+		if err != nil {
+			return err
+		}
+		err = snapshot.IterateScheduledPrices(ctx, func(dto model.ScheduledPriceDTO) error {
+			return aw.writeRecord(model.TableScheduledPrices, scheduledPriceToRecord(dto))
+		})
+		if err != nil {
+			return err
+		}
+		err = snapshot.IterateEvents(ctx, func(dto model.EventDTO) error {
+			return aw.writeRecord(model.TableEvents, eventToRecord(dto))
+		})
+		if err != nil {
+			return err
+		}
+		webhooks, err := snapshot.LoadWebhooks(ctx)
+		if err != nil {
+			return err
+		}
+		for _, dto := range webhooks {
+			if err = aw.writeRecord(model.TableWebhooks, webhookToRecord(dto)); err != nil {
+				return err
+			}
+		}
+		err = snapshot.IterateExpirations(ctx, func(dto model.ExpirationDTO) error {
+			return aw.writeRecord(model.TableExpirations, expirationToRecord(dto))
+		})
+		if err != nil {
+			return err
+		}
+		return snapshot.IterateErasures(ctx, func(dto model.ErasureDTO) error {
+			return aw.writeRecord(model.TableErasures, erasureToRecord(dto))
+		})
+	})
+	if err != nil {
+		return manifest, err
+	}
+
+	manifest.Counts = aw.counts
+	manifest.Checksum = "sha256:" + hex.EncodeToString(aw.hash.Sum(nil))
+	footer, err := json.Marshal(line{Type: lineFooter, Counts: aw.counts, Checksum: manifest.Checksum})
+	if err != nil {
+		return manifest, err
+	}
+	if _, err = aw.w.Write(append(footer, '\n')); err != nil {
+		return manifest, fmt.Errorf("writing archive error: %v", err)
+	}
+	if err = aw.w.Flush(); err != nil {
+		return manifest, fmt.Errorf("writing archive error: %v", err)
+	}
+	return manifest, nil
+}
+
+func (aw *writer) writeRecord(table string, record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	aw.counts[table]++
+	return aw.writeLine(line{Type: lineRecord, Table: table, Data: data})
+}
+
+func (aw *writer) writeLine(l line) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	aw.hash.Write(data)
+	if _, err = aw.w.Write(data); err != nil {
+		return fmt.Errorf("writing archive error: %v", err)
+	}
+	return nil
+}
+
+// Read parses and validates a whole archive. Nothing is returned unless the
+// format, version, checksum, record counts and every record are valid.
+func Read(r io.Reader) (data model.BackupDTO, manifest Manifest, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	h := sha256.New()
+	counts := make(map[string]int)
+	footer := false
+	n := 0
+	for scanner.Scan() {
+		n++
+		raw := scanner.Bytes()
+		if footer {
+			return data, manifest, fmt.Errorf("line %d: data after the archive footer", n)
+		}
+		l := line{}
+		if err = json.Unmarshal(raw, &l); err != nil {
+			return data, manifest, fmt.Errorf("line %d: invalid json: %v", n, err)
+		}
+		if n == 1 {
+			if l.Type != lineHeader || l.Format != Format {
+				return data, manifest, fmt.Errorf("not a %s archive", Format)
+			}
+			if l.Version < 1 || l.Version > Version {
+				return data, manifest, fmt.Errorf("unsupported archive version %d, up to %d supported", l.Version, Version)
+			}
+			manifest.Version = l.Version
+			if l.CreatedAt != nil {
+				manifest.CreatedAt = *l.CreatedAt
+			}
+			h.Write(raw)
+			h.Write([]byte{'\n'})
+			continue
+		}
+
+		switch l.Type {
+		case lineRecord:
+			if err = addRecord(&data, l.Table, l.Data); err != nil {
+				return data, manifest, fmt.Errorf("line %d: %v", n, err)
+			}
+			counts[l.Table]++
+			h.Write(raw)
+			h.Write([]byte{'\n'})
+		case lineFooter:
+			footer = true
+			manifest.Counts = l.Counts
+			manifest.Checksum = l.Checksum
+		default:
+			return data, manifest, fmt.Errorf("line %d: unknown line type %q", n, l.Type)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return data, manifest, fmt.Errorf("reading archive error: %v", err)
+	}
+	if n == 0 {
+		return data, manifest, fmt.Errorf("archive is empty")
+	}
+	if !footer {
+		return data, manifest, fmt.Errorf("archive footer is missing, the archive is truncated")
+	}
+	if checksum := "sha256:" + hex.EncodeToString(h.Sum(nil)); checksum != manifest.Checksum {
+		return data, manifest, fmt.Errorf("archive checksum mismatch")
+	}
+	for _, table := range Tables {
+		if counts[table] != manifest.Counts[table] {
+			return data, manifest, fmt.Errorf("%s has %d records, footer expects %d", table, counts[table], manifest.Counts[table])
+		}
+	}
+	if err = validate(data); err != nil {
+		return data, manifest, err
+	}
+	return data, manifest, nil
+}
+
+func addRecord(data *model.BackupDTO, table string, raw json.RawMessage) error {
+	switch table {
+	case model.TableSubscriptions:
+		r := subscriptionRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record %d: %v", table, r.Id, err)
+		}
+		data.Subscriptions = append(data.Subscriptions, dto)
+	case model.TableScheduledPrices:
+		r := scheduledPriceRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record %d: %v", table, r.Id, err)
+		}
+		data.ScheduledPrices = append(data.ScheduledPrices, dto)
+	case model.TableEvents:
+		r := eventRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record %d: %v", table, r.Id, err)
+		}
+		data.Events = append(data.Events, dto)
+	case model.TableWebhooks:
+		r := webhookRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record %d: %v", table, r.Id, err)
+		}
+		data.Webhooks = append(data.Webhooks, dto)
+	case model.TableExpirations:
+		r := expirationRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record of subscription %d: %v", table, r.SubscriptionId, err)
+		}
+		data.Expirations = append(data.Expirations, dto)
+	case model.TableErasures:
+		r := erasureRecord{}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("invalid %s record: %v", table, err)
+		}
+		dto, err := r.toDTO()
+		if err != nil {
+			return fmt.Errorf("invalid %s record %d: %v", table, r.Id, err)
+		}
+		data.Erasures = append(data.Erasures, dto)
+	default:
+		return fmt.Errorf("unknown table %q", table)
+	}
+	return nil
+}
+
+func validate(data model.BackupDTO) error {
+	subs := make(map[int]bool, len(data.Subscriptions))
+	for _, dto := range data.Subscriptions {
+		if subs[dto.Id] {
+			return fmt.Errorf("duplicate %s id %d", model.TableSubscriptions, dto.Id)
+		}
+		subs[dto.Id] = true
+	}
+	prices := make(map[int]bool, len(data.ScheduledPrices))
+	for _, dto := range data.ScheduledPrices {
+		if prices[dto.Id] {
+			return fmt.Errorf("duplicate %s id %d", model.TableScheduledPrices, dto.Id)
+		}
+		prices[dto.Id] = true
+		if !subs[dto.SubscriptionId] {
+			return fmt.Errorf("%s id %d references missing subscription %d", model.TableScheduledPrices, dto.Id, dto.SubscriptionId)
+		}
+	}
+	events := make(map[int]bool, len(data.Events))
+	for _, dto := range data.Events {
+		if events[dto.Id] {
+			return fmt.Errorf("duplicate %s id %d", model.TableEvents, dto.Id)
+		}
+		events[dto.Id] = true
+	}
+	webhooks := make(map[int]bool, len(data.Webhooks))
+	for _, dto := range data.Webhooks {
+		if webhooks[dto.Id] {
+			return fmt.Errorf("duplicate %s id %d", model.TableWebhooks, dto.Id)
+		}
+		webhooks[dto.Id] = true
+	}
+	expirations := make(map[model.ExpirationDTO]bool, len(data.Expirations))
+	for _, dto := range data.Expirations {
+		key := model.ExpirationDTO{SubscriptionId: dto.SubscriptionId, EndDate: dto.EndDate}
+		if expirations[key] {
+			return fmt.Errorf("duplicate %s of subscription %d", model.TableExpirations, dto.SubscriptionId)
+		}
+		expirations[key] = true
+		if !subs[dto.SubscriptionId] {
+			return fmt.Errorf("%s of subscription %d references a missing subscription", model.TableExpirations, dto.SubscriptionId)
+		}
+	}
+	erasures := make(map[int]bool, len(data.Erasures))
+	for _, dto := range data.Erasures {
+		if erasures[dto.Id] {
+			return fmt.Errorf("duplicate %s id %d", model.TableErasures, dto.Id)
+		}
+		erasures[dto.Id] = true
+	}
+	return nil
+}
+
+// Restore loads a validated archive into the storage. Erased users stay
+// erased: the records of users with a tombstone in the archive or the storage
+// are skipped, which takes the signing key their user ids were hashed with.
+func Restore(ctx context.Context, storage db.Storage, data model.BackupDTO, policy string, signingKey string) (counts []model.RestoreCount, err error) {
+	switch policy {
+	case model.RestoreFail, model.RestoreSkip, model.RestoreOverwrite:
+	default:
+		return counts, fmt.Errorf("unknown conflict policy %q, fail, skip or overwrite required", policy)
+	}
+	erased := make(map[string]bool, len(data.Erasures))
+	for _, dto := range data.Erasures {
+		erased[dto.UserHash] = true
+	}
+	err = storage.IterateErasures(ctx, func(dto model.ErasureDTO) error {
+		erased[dto.UserHash] = true
+		return nil
+	})
+	if err != nil {
+		return counts, err
+	}
+	if len(erased) > 0 && signingKey == "" {
+		return counts, fmt.Errorf("erased users must not be restored, the privacy signing key is required to recognize them")
+	}
+
+	data, skipped := withoutErased(data, erased, signingKey)
+	counts, err = storage.Restore(ctx, data, policy)
+	if err != nil {
+		return counts, err
+	}
+	for i := range counts {
+		counts[i].Skipped += skipped[counts[i].Table]
+	}
+	return counts, nil
+}
+
+// withoutErased drops the subscriptions and events of erased users, with the
+// scheduled prices and expirations of those subscriptions, and returns the
+// number of dropped records by table.
+func withoutErased(data model.BackupDTO, erased map[string]bool, signingKey string) (model.BackupDTO, map[string]int) {
+	skipped := make(map[string]int)
+	if len(erased) == 0 {
+		return data, skipped
+	}
+	users := make(map[uuid.UUID]bool)
+	isErased := func(userId uuid.UUID) bool {
+		result, ok := users[userId]
+		if !ok {
+			result = erased[subscription.UserHash(signingKey, userId)]
+			users[userId] = result
+		}
+		return result
+	}
+	subs := make(map[int]bool)
+	data.Subscriptions, skipped[model.TableSubscriptions] = without(data.Subscriptions, func(dto model.SubscriptionDTO) bool {
+		subs[dto.Id] = isErased(dto.UserId)
+		return subs[dto.Id]
+	})
+	data.ScheduledPrices, skipped[model.TableScheduledPrices] = without(data.ScheduledPrices, func(dto model.ScheduledPriceDTO) bool {
+		return subs[dto.SubscriptionId]
+	})
+	data.Events, skipped[model.TableEvents] = without(data.Events, func(dto model.EventDTO) bool {
+		return isErased(dto.UserId)
+	})
+	data.Expirations, skipped[model.TableExpirations] = without(data.Expirations, func(dto model.ExpirationDTO) bool {
+		return subs[dto.SubscriptionId]
+	})
+	return data, skipped
+}
+
+// without returns the items that drop rejects in a new slice and the number
+// of dropped items, the archive data itself is left as it is.
+func without[T any](items []T, drop func(T) bool) (kept []T, dropped int) {
+	kept = make([]T, 0, len(items))
+	for _, item := range items {
+		if drop(item) {
+			dropped++
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept, dropped
+}
@@ -0,0 +1,272 @@
+package backup
+
+import (
+	"fmt"
+	"main/internal/model"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+type subscriptionRecord struct {
+	Id            int    `json:"id"`
+	ServiceName   string `json:"service_name"`
+	Price         int    `json:"price"`
+	UserId        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date,omitempty"`
+	BillingPeriod string `json:"billing_period"`
+	TrialEndDate  string `json:"trial_end_date,omitempty"`
+}
+
+type scheduledPriceRecord struct {
+	Id             int    `json:"id"`
+	SubscriptionId int    `json:"subscription_id"`
+	Price          int    `json:"price"`
+	EffectiveDate  string `json:"effective_date"`
+}
+
+type eventRecord struct {
+	Id             int       `json:"id"`
+	Type           string    `json:"type"`
+	SubscriptionId int       `json:"subscription_id"`
+	UserId         string    `json:"user_id"`
+	ServiceName    string    `json:"service_name"`
+	OldPrice       int       `json:"old_price"`
+	NewPrice       int       `json:"new_price"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type webhookRecord struct {
+	Id         int       `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type expirationRecord struct {
+	SubscriptionId int       `json:"subscription_id"`
+	EndDate        string    `json:"end_date"`
+	NotifiedAt     time.Time `json:"notified_at"`
+}
+
+type erasureRecord struct {
+	Id       int                 `json:"id"`
+	UserHash string              `json:"user_hash"`
+	Removed  []model.ErasedTable `json:"removed"`
+	ErasedAt time.Time           `json:"erased_at"`
+}
+
+func subscriptionToRecord(dto model.SubscriptionDTO) subscriptionRecord {
+	return subscriptionRecord{
+		Id:            dto.Id,
+		ServiceName:   dto.ServiceName,
+		Price:         dto.Price,
+		UserId:        dto.UserId.String(),
+		StartDate:     formatDate(dto.StartDate),
+		EndDate:       formatDate(dto.EndDate),
+		BillingPeriod: dto.BillingPeriod,
+		TrialEndDate:  formatDate(dto.TrialEndDate),
+	}
+}
+
+func (r subscriptionRecord) toDTO() (dto model.SubscriptionDTO, err error) {
+	if r.Id <= 0 {
+		return dto, fmt.Errorf("positive id required")
+	}
+	if strings.TrimSpace(r.ServiceName) == "" {
+		return dto, fmt.Errorf("service name is required")
+	}
+	if r.BillingPeriod != model.BillingMonthly && r.BillingPeriod != model.BillingYearly {
+		return dto, fmt.Errorf("invalid billing period %q", r.BillingPeriod)
+	}
+	dto = model.SubscriptionDTO{
+		Id:            r.Id,
+		ServiceName:   r.ServiceName,
+		Price:         r.Price,
+		BillingPeriod: r.BillingPeriod,
+	}
+	if dto.UserId, err = uuid.Parse(r.UserId); err != nil {
+		return dto, fmt.Errorf("invalid user id %q", r.UserId)
+	}
+	if dto.StartDate, err = parseDate(r.StartDate, true); err != nil {
+		return dto, err
+	}
+	if dto.EndDate, err = parseDate(r.EndDate, false); err != nil {
+		return dto, err
+	}
+	if dto.TrialEndDate, err = parseDate(r.TrialEndDate, false); err != nil {
+		return dto, err
+	}
+	return dto, nil
+}
+
+func scheduledPriceToRecord(dto model.ScheduledPriceDTO) scheduledPriceRecord {
+	return scheduledPriceRecord{
+		Id:             dto.Id,
+		SubscriptionId: dto.SubscriptionId,
+		Price:          dto.Price,
+		EffectiveDate:  formatDate(dto.EffectiveDate),
+	}
+}
+
+func (r scheduledPriceRecord) toDTO() (dto model.ScheduledPriceDTO, err error) {
+	if r.Id <= 0 {
+		return dto, fmt.Errorf("positive id required")
+	}
+	dto = model.ScheduledPriceDTO{
+		Id:             r.Id,
+		SubscriptionId: r.SubscriptionId,
+		Price:          r.Price,
+	}
+	if dto.EffectiveDate, err = parseDate(r.EffectiveDate, true); err != nil {
+		return dto, err
+	}
+	return dto, nil
+}
+
+func eventToRecord(dto model.EventDTO) eventRecord {
+	return eventRecord{
+		Id:             dto.Id,
+		Type:           dto.Type,
+		SubscriptionId: dto.SubscriptionId,
+		UserId:         dto.UserId.String(),
+		ServiceName:    dto.ServiceName,
+		OldPrice:       dto.OldPrice,
+		NewPrice:       dto.NewPrice,
+		CreatedAt:      dto.CreatedAt.UTC(),
+	}
+}
+
+func (r eventRecord) toDTO() (dto model.EventDTO, err error) {
+	if r.Id <= 0 {
+		return dto, fmt.Errorf("positive id required")
+	}
+	if r.Type == "" {
+		return dto, fmt.Errorf("event type is required")
+	}
+	dto = model.EventDTO{
+		Id:             r.Id,
+		Type:           r.Type,
+		SubscriptionId: r.SubscriptionId,
+		ServiceName:    r.ServiceName,
+		OldPrice:       r.OldPrice,
+		NewPrice:       r.NewPrice,
+		CreatedAt:      r.CreatedAt,
+	}
+	if dto.UserId, err = uuid.Parse(r.UserId); err != nil {
+		return dto, fmt.Errorf("invalid user id %q", r.UserId)
+	}
+	return dto, nil
+}
+
+func webhookToRecord(dto model.WebhookDTO) webhookRecord {
+	return webhookRecord{
+		Id:         dto.Id,
+		URL:        dto.URL,
+		Secret:     dto.Secret,
+		EventTypes: dto.EventTypes,
+		Active:     dto.Active,
+		CreatedAt:  dto.CreatedAt.UTC(),
+	}
+}
+
+func (r webhookRecord) toDTO() (dto model.WebhookDTO, err error) {
+	if r.Id <= 0 {
+		return dto, fmt.Errorf("positive id required")
+	}
+	if r.URL == "" {
+		return dto, fmt.Errorf("webhook url is required")
+	}
+	for _, t := range r.EventTypes {
+		if !slices.Contains(model.WebhookEventTypes, t) {
+			return dto, fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	if r.EventTypes == nil {
+		r.EventTypes = []string{}
+	}
+	return model.WebhookDTO{
+		Id:         r.Id,
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: r.EventTypes,
+		Active:     r.Active,
+		CreatedAt:  r.CreatedAt,
+	}, nil
+}
+
+func expirationToRecord(dto model.ExpirationDTO) expirationRecord {
+	return expirationRecord{
+		SubscriptionId: dto.SubscriptionId,
+		EndDate:        formatDate(dto.EndDate),
+		NotifiedAt:     dto.NotifiedAt.UTC(),
+	}
+}
+
+func (r expirationRecord) toDTO() (dto model.ExpirationDTO, err error) {
+	dto = model.ExpirationDTO{
+		SubscriptionId: r.SubscriptionId,
+		NotifiedAt:     r.NotifiedAt,
+	}
+	if dto.EndDate, err = parseDate(r.EndDate, true); err != nil {
+		return dto, err
+	}
+	return dto, nil
+}
+
+func erasureToRecord(dto model.ErasureDTO) erasureRecord {
+	return erasureRecord{
+		Id:       dto.Id,
+		UserHash: dto.UserHash,
+		Removed:  dto.Removed,
+		ErasedAt: dto.ErasedAt.UTC(),
+	}
+}
+
+func (r erasureRecord) toDTO() (dto model.ErasureDTO, err error) {
+	if r.Id <= 0 {
+		return dto, fmt.Errorf("positive id required")
+	}
+	if r.UserHash == "" {
+		return dto, fmt.Errorf("user hash is required")
+	}
+	if r.Removed == nil {
+		r.Removed = []model.ErasedTable{}
+	}
+	return model.ErasureDTO{
+		Id:       r.Id,
+		UserHash: r.UserHash,
+		Removed:  r.Removed,
+		ErasedAt: r.ErasedAt,
+	}, nil
+}
+
+// formatDate keeps zero dates out of the archive, open-ended end dates are
+// stored as 0001-01-01 by the Postgres storage.
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
+
+func parseDate(s string, required bool) (date time.Time, err error) {
+	if s == "" {
+		if required {
+			return date, fmt.Errorf("date is required")
+		}
+		return date, nil
+	}
+	date, err = time.Parse(dateLayout, s)
+	if err != nil {
+		return date, fmt.Errorf("invalid date %q, YYYY-MM-DD format required", s)
+	}
+	return date, nil
+}
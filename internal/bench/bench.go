@@ -0,0 +1,235 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RouteCreate = "create"
+	RouteRead   = "read"
+	RouteList   = "list"
+	RouteCost   = "cost"
+)
+
+var Routes = []string{RouteCreate, RouteRead, RouteList, RouteCost}
+
+// MaxRate is the highest request rate Run paces, one request per microsecond
+// is far beyond what the workers can send.
+const MaxRate = 1e6
+
+type Options struct {
+	BaseURL  string
+	Workers  int
+	Rate     float64
+	Duration time.Duration
+	// Grace is how long requests in flight at the end of the duration may
+	// still take, the ones it cuts off count as errors.
+	Grace time.Duration
+	Mix   map[string]int
+	Users int
+	Seed  uint64
+}
+
+type RouteReport struct {
+	Route      string
+	Requests   int
+	Errors     int
+	ErrorRate  float64
+	Throughput float64
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+}
+
+type Report struct {
+	Duration time.Duration
+	Routes   []RouteReport
+	Total    RouteReport
+	Created  []int
+}
+
+type sample struct {
+	route   string
+	latency time.Duration
+	failed  bool
+}
+
+// ParseMix reads weights such as "create=1,read=4,list=2,cost=3".
+func ParseMix(s string) (mix map[string]int, err error) {
+	mix = make(map[string]int)
+	for _, part := range strings.Split(s, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !slices.Contains(Routes, name) {
+			return nil, fmt.Errorf("invalid mix entry %q, route=weight required, routes: %s", part, strings.Join(Routes, ", "))
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight in mix entry %q", part)
+		}
+		mix[name] = w
+	}
+	total := 0
+	for _, w := range mix {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mix has no routes with positive weight")
+	}
+	return mix, nil
+}
+
+// Run drives requests against the server until the duration ends. With a
+// positive rate the requests are started at that rate in total, otherwise
+// every worker sends them back to back.
+func Run(ctx context.Context, client *http.Client, opts Options) (report Report, err error) {
+	if opts.Workers <= 0 {
+		return report, fmt.Errorf("workers must be positive")
+	}
+	if opts.Duration <= 0 {
+		return report, fmt.Errorf("duration must be positive")
+	}
+	if opts.Rate < 0 || opts.Rate > MaxRate {
+		return report, fmt.Errorf("rate must be between 0 and %.0f", float64(MaxRate))
+	}
+	if opts.Grace < 0 {
+		return report, fmt.Errorf("grace must not be negative")
+	}
+	if opts.Users <= 0 {
+		opts.Users = 10
+	}
+	// No request starts after runCtx ends, the ones in flight finish within
+	// the grace period so that the slowest of them are not left out.
+	runCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+	reqCtx, cancelReq := context.WithTimeout(ctx, opts.Duration+opts.Grace)
+	defer cancelReq()
+
+	t := newTarget(client, opts)
+	samples := make(chan sample, opts.Workers*16)
+	var tokens chan struct{}
+	if opts.Rate > 0 {
+		tokens = make(chan struct{}, opts.Workers)
+		go pace(runCtx, opts.Rate, tokens)
+	}
+
+	var wg sync.WaitGroup
+	for i := range opts.Workers {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(opts.Seed, uint64(worker)))
+			for {
+				if tokens != nil {
+					select {
+					case <-runCtx.Done():
+						return
+					case <-tokens:
+					}
+				} else if runCtx.Err() != nil {
+					return
+				}
+				route := t.pick(rng)
+				start := time.Now()
+				err := t.do(reqCtx, rng, route)
+				if ctx.Err() != nil {
+					// The run itself was canceled, not timed out.
+					return
+				}
+				samples <- sample{route: route, latency: time.Since(start), failed: err != nil}
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	start := time.Now()
+	latencies := make(map[string][]time.Duration)
+	errors := make(map[string]int)
+	for s := range samples {
+		latencies[s.route] = append(latencies[s.route], s.latency)
+		if s.failed {
+			errors[s.route]++
+		}
+	}
+	report.Duration = time.Since(start)
+	report.Created = t.createdIds()
+
+	all := []time.Duration{}
+	totalErrors := 0
+	for _, route := range Routes {
+		if len(latencies[route]) == 0 {
+			continue
+		}
+		report.Routes = append(report.Routes, summarize(route, latencies[route], errors[route], report.Duration))
+		all = append(all, latencies[route]...)
+		totalErrors += errors[route]
+	}
+	report.Total = summarize("total", all, totalErrors, report.Duration)
+	return report, nil
+}
+
+func pace(ctx context.Context, rate float64, tokens chan<- struct{}) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			select {
+			case tokens <- struct{}{}:
+			default:
+				// All workers are busy, the request is dropped and the
+				// achieved throughput shows the shortfall.
+			}
+		}
+	}
+}
+
+func summarize(route string, latencies []time.Duration, errors int, elapsed time.Duration) (r RouteReport) {
+	r = RouteReport{Route: route, Requests: len(latencies), Errors: errors}
+	if r.Requests == 0 {
+		return r
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	r.ErrorRate = float64(errors) / float64(r.Requests)
+	r.Throughput = float64(r.Requests) / elapsed.Seconds()
+	r.P50 = percentile(latencies, 0.50)
+	r.P95 = percentile(latencies, 0.95)
+	r.P99 = percentile(latencies, 0.99)
+	return r
+}
+
+// percentile uses the nearest-rank method on sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p+0.999999) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// userIds are derived from the seed so repeated runs touch the same users.
+func userIds(seed uint64, n int) (ids []uuid.UUID) {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	for range n {
+		var id uuid.UUID
+		for i := range id {
+			id[i] = byte(rng.UintN(256))
+		}
+		id[6] = id[6]&0x0f | 0x40
+		id[8] = id[8]&0x3f | 0x80
+		ids = append(ids, id)
+	}
+	return ids
+}
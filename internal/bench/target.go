@@ -0,0 +1,152 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var benchServices = []string{"Yandex Plus", "Kinopoisk", "VK Music", "Okko", "IVI"}
+
+type target struct {
+	client  *http.Client
+	baseURL string
+	routes  []string
+	weights []int
+	total   int
+	users   []uuid.UUID
+
+	mu      sync.Mutex
+	created []int
+}
+
+func newTarget(client *http.Client, opts Options) *target {
+	t := &target{
+		client:  client,
+		baseURL: strings.TrimRight(opts.BaseURL, "/"),
+		users:   userIds(opts.Seed, opts.Users),
+	}
+	for _, route := range Routes {
+		if w := opts.Mix[route]; w > 0 {
+			t.routes = append(t.routes, route)
+			t.weights = append(t.weights, w)
+			t.total += w
+		}
+	}
+	return t
+}
+
+func (t *target) pick(rng *rand.Rand) string {
+	r := rng.IntN(t.total)
+	for i, w := range t.weights {
+		if r < w {
+			return t.routes[i]
+		}
+		r -= w
+	}
+	return t.routes[len(t.routes)-1]
+}
+
+func (t *target) do(ctx context.Context, rng *rand.Rand, route string) error {
+	userId := t.users[rng.IntN(len(t.users))]
+	service := benchServices[rng.IntN(len(benchServices))]
+	switch route {
+	case RouteCreate:
+		body, _ := json.Marshal(map[string]any{
+			"service_name": service,
+			"price":        100 + rng.IntN(900),
+			"user_id":      userId,
+			"start_date":   fmt.Sprintf("%02d-2025", 1+rng.IntN(12)),
+		})
+		resp, err := t.request(ctx, http.MethodPost, "/api/v1/subscriptions", body)
+		if err != nil {
+			return err
+		}
+		created := struct {
+			Data struct {
+				Id int `json:"id"`
+			} `json:"data"`
+		}{}
+		if json.Unmarshal(resp, &created) == nil && created.Data.Id > 0 {
+			t.mu.Lock()
+			t.created = append(t.created, created.Data.Id)
+			t.mu.Unlock()
+		}
+		return nil
+	case RouteRead:
+		_, err := t.request(ctx, http.MethodGet, fmt.Sprintf("/api/v1/subscriptions/%d", t.readId(rng)), nil)
+		return err
+	case RouteList:
+		_, err := t.request(ctx, http.MethodGet, "/api/v1/subscriptions?user_id="+userId.String(), nil)
+		return err
+	}
+	query := url.Values{
+		"user_id":      {userId.String()},
+		"service_name": {service},
+		"start":        {"01-2025"},
+		"end":          {"12-2025"},
+	}
+	_, err := t.request(ctx, http.MethodGet, "/api/v1/subscriptions/cost?"+query.Encode(), nil)
+	return err
+}
+
+// Delete removes the subscriptions created during the run.
+func Delete(ctx context.Context, client *http.Client, baseURL string, ids []int) (deleted int, err error) {
+	t := &target{client: client, baseURL: strings.TrimRight(baseURL, "/")}
+	for _, id := range ids {
+		if _, err = t.request(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/subscriptions/%d", id), nil); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (t *target) readId(rng *rand.Rand) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.created) == 0 {
+		return 1
+	}
+	return t.created[rng.IntN(len(t.created))]
+}
+
+func (t *target) createdIds() []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]int(nil), t.created...)
+}
+
+// request fails on any 4xx or 5xx status, except that GET requests fail only
+// on 5xx: reads of ids created by other runs may answer 404.
+func (t *target) request(ctx context.Context, method, path string, body []byte) (resp []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	resp, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 500 || (res.StatusCode >= 400 && method != http.MethodGet) {
+		return resp, fmt.Errorf("%s %s: status %d", method, path, res.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"main/internal/bench"
+	"main/internal/handler"
+	"net"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func runBench(ctx context.Context, app *App, args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	target := flags.String("url", "", "base URL of a running server, empty starts one in-process on the configured database")
+	workers := flags.Int("workers", 8, "number of concurrent workers")
+	rate := flags.Float64("rate", 0, "target requests per second in total, 0 for as fast as possible")
+	duration := flags.Duration("duration", 30*time.Second, "how long to send requests")
+	grace := flags.Duration("grace", 5*time.Second, "how long requests in flight at the end may take, the rest count as errors")
+	mix := flags.String("mix", "create=1,read=4,list=2,cost=3", "route weights")
+	users := flags.Int("users", 50, "number of distinct user ids")
+	seedValue := flags.Uint64("seed", 1, "random seed for user ids and request order")
+	cleanup := flags.Bool("cleanup", true, "delete the subscriptions created during the run")
+	maxErrorRate := flags.Float64("max-error-rate", -1, "fail if the total error rate is above this fraction, negative disables")
+	maxP99 := flags.Duration("max-p99", 0, "fail if the p99 latency of any route is above this, 0 disables")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *rate < 0 || *rate > bench.MaxRate {
+		return fmt.Errorf("rate must be between 0 and %.0f", float64(bench.MaxRate))
+	}
+	routeMix, err := bench.ParseMix(*mix)
+	if err != nil {
+		return err
+	}
+
+	baseURL := *target
+	if baseURL == "" {
+		baseURL, err = startServer(ctx, app)
+		if err != nil {
+			return err
+		}
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        *workers,
+			MaxIdleConnsPerHost: *workers,
+		},
+	}
+
+	fmt.Fprintf(os.Stderr, "running %s against %s with %d workers\n", *duration, baseURL, *workers)
+	report, err := bench.Run(ctx, client, bench.Options{
+		BaseURL:  baseURL,
+		Workers:  *workers,
+		Rate:     *rate,
+		Duration: *duration,
+		Grace:    *grace,
+		Mix:      routeMix,
+		Users:    *users,
+		Seed:     *seedValue,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ROUTE\tREQUESTS\tERRORS\tERROR RATE\tRPS\tP50\tP95\tP99\t")
+	for _, r := range append(report.Routes, report.Total) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\t%.1f\t%s\t%s\t%s\t\n", r.Route, r.Requests, r.Errors,
+			r.ErrorRate*100, r.Throughput, r.P50.Round(time.Microsecond),
+			r.P95.Round(time.Microsecond), r.P99.Round(time.Microsecond))
+	}
+	w.Flush()
+
+	if *cleanup && len(report.Created) > 0 {
+		deleted, err := bench.Delete(ctx, client, baseURL, report.Created)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cleanup stopped after %d of %d subscriptions: %v\n", deleted, len(report.Created), err)
+		}
+	}
+
+	if *maxErrorRate >= 0 && report.Total.ErrorRate > *maxErrorRate {
+		return fmt.Errorf("error rate %.2f%% is above the %.2f%% threshold", report.Total.ErrorRate*100, *maxErrorRate*100)
+	}
+	if *maxP99 > 0 {
+		for _, r := range report.Routes {
+			if r.P99 > *maxP99 {
+				return fmt.Errorf("%s p99 latency %s is above the %s threshold", r.Route, r.P99.Round(time.Microsecond), *maxP99)
+			}
+		}
+	}
+	return nil
+}
+
+// startServer serves the regular handlers on a random local port, backed by
+// the same pool settings as the application.
+func startServer(ctx context.Context, app *App) (baseURL string, err error) {
+	service, err := app.Service(ctx)
+	if err != nil {
+		return "", err
+	}
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	handler.NewHandler(router, service, app.Logger).Register()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	server := &http.Server{Handler: router}
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return "http://" + listener.Addr().String(), nil
+}
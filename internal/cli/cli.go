@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"main/internal/config"
+	"main/internal/db"
+	"main/internal/subscription"
+	"main/pkg/logger"
+	"main/pkg/postgres"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, app *App, args []string) error
+}
+
+var commands = []command{
+	{name: "statement", usage: "detect recurring charges in a bank statement", run: runStatement},
+	{name: "backup", usage: "write a checksummed archive of all data", run: runBackup},
+	{name: "restore", usage: "validate and load a backup archive", run: runRestore},
+	{name: "export", usage: "write Parquet files for the data warehouse", run: runExport},
+	{name: "seed", usage: "insert generated subscriptions for development", run: runSeed},
+	{name: "bench", usage: "load test the HTTP endpoints and report latency", run: runBench},
+	{name: "relay", usage: "publish outbox events to the configured sink", run: runRelay},
+}
+
+type App struct {
+	Config *config.Config
+	Logger *logger.Logger
+	pool   *pgxpool.Pool
+}
+
+func Run(ctx context.Context, args []string, cfg *config.Config, logger *logger.Logger) error {
+	app := &App{
+		Config: cfg,
+		Logger: logger,
+	}
+	defer app.close()
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(ctx, app, args[1:])
+		}
+	}
+	names := []string{"serve"}
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	return fmt.Errorf("unknown command %q, available commands: %s", args[0], strings.Join(names, ", "))
+}
+
+func Usage() string {
+	var b strings.Builder
+	b.WriteString("usage: sub_service [command] [flags]\n\ncommands:\n")
+	b.WriteString(fmt.Sprintf("  %-10s %s\n", "serve", "run the HTTP server (default)"))
+	for _, c := range commands {
+		b.WriteString(fmt.Sprintf("  %-10s %s\n", c.name, c.usage))
+	}
+	return b.String()
+}
+
+func (a *App) Storage(ctx context.Context) (db.Storage, error) {
+	if a.pool == nil {
+		pool, err := postgres.NewPool(ctx, 5, *a.Config)
+		if err != nil {
+			return nil, err
+		}
+		if err = pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		a.pool = pool
+	}
+	return db.NewDataBase(a.pool, a.Logger), nil
+}
+
+func (a *App) Service(ctx context.Context) (subscription.SubscriptionInterface, error) {
+	storage, err := a.Storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return subscription.NewService(storage, a.Config, a.Logger), nil
+}
+
+func (a *App) close() {
+	if a.pool != nil {
+		a.pool.Close()
+	}
+}
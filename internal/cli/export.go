@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"main/internal/model"
+	"os"
+	"text/tabwriter"
+)
+
+func runExport(ctx context.Context, app *App, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "parquet", "export format, only parquet is supported")
+	dir := flags.String("dir", app.Config.Warehouse.Dir, "directory the export is written to")
+	from := flags.String("from", "", "first spend month, MM-YYYY (default: earliest start date)")
+	to := flags.String("to", "", "last spend month, MM-YYYY (default: current month)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "parquet" {
+		return fmt.Errorf("unsupported export format: %s", *format)
+	}
+	app.Config.Warehouse.Dir = *dir
+
+	service, err := app.Service(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := service.ExportWarehouse(ctx, model.WarehouseExportRequest{From: *from, To: *to})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("export written to %s\n", result.Path)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tFILE\tROWS")
+	for _, f := range result.Files {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", f.Dataset, f.Path, f.Rows)
+	}
+	return w.Flush()
+}
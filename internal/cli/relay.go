@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"main/internal/outbox"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func runRelay(ctx context.Context, app *App, args []string) error {
+	flags := flag.NewFlagSet("relay", flag.ContinueOnError)
+	sink := flags.String("sink", app.Config.Outbox.Sink, "where events are published: stdout, file, nats or kafka")
+	file := flags.String("file", app.Config.Outbox.File, "output file of the file sink")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	app.Config.Outbox.Sink = *sink
+	app.Config.Outbox.File = *file
+
+	storage, err := app.Storage(ctx)
+	if err != nil {
+		return err
+	}
+	publisher, err := outbox.NewPublisher(app.Config)
+	if err != nil {
+		return err
+	}
+	defer publisher.Close()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app.Logger.Infof("relaying outbox events to %s", *sink)
+	outbox.NewRelay(storage, publisher, app.Config, app.Logger).Run(ctx)
+	return nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+func runStatement(ctx context.Context, app *App, args []string) error {
+	flags := flag.NewFlagSet("statement", flag.ContinueOnError)
+	file := flags.String("file", "", "path to an OFX or CSV bank statement")
+	user := flags.String("user", "", "user ID the candidates belong to")
+	accept := flags.String("accept", "", "comma separated candidate numbers to create, or all")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("statement file is required")
+	}
+	userId, err := uuid.Parse(*user)
+	if err != nil {
+		return fmt.Errorf("wrong uuid")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	service, err := app.Service(ctx)
+	if err != nil {
+		return err
+	}
+	candidates, err := service.DetectCharges(ctx, f, path.Base(*file), userId)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSERVICE\tPRICE\tPERIOD\tSTART\tEND\tCHARGES\tCONFIDENCE\tEXISTING")
+	for i, c := range candidates {
+		existing := ""
+		if c.ExistingId != 0 {
+			existing = strconv.Itoa(c.ExistingId)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%d\t%.2f\t%s\n", i+1, c.Subscription.ServiceName,
+			c.Subscription.Price, c.Subscription.BillingPeriod, c.Subscription.StartDate,
+			c.Subscription.EndDate, c.Charges, c.Confidence, existing)
+	}
+	w.Flush()
+
+	if *accept == "" {
+		return nil
+	}
+	selected := make(map[int]bool)
+	for _, s := range strings.Split(*accept, ",") {
+		s = strings.TrimSpace(s)
+		if s == "all" {
+			for i := range candidates {
+				selected[i] = true
+			}
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > len(candidates) {
+			return fmt.Errorf("incorrect candidate number: %q", s)
+		}
+		selected[n-1] = true
+	}
+	for i, c := range candidates {
+		if !selected[i] {
+			continue
+		}
+		id, _, err := service.Save(ctx, c.Subscription)
+		if err != nil {
+			return fmt.Errorf("creating candidate %d error: %v", i+1, err)
+		}
+		fmt.Printf("created new sub with id: %d (%s)\n", id, c.Subscription.ServiceName)
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"main/pkg/logger"
 	"sync"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -20,6 +22,56 @@ type Config struct {
 		Username string `env:"PSQL_USER"`
 		Password string `env:"PSQL_PASSWORD"`
 	}
+	GRPC struct {
+		Addr string
+		Port string `env:"GRPC_PORT" env-default:"9000"`
+	}
+	API struct {
+		LegacyRoutes bool `env:"LEGACY_ROUTES" env-default:"true"`
+	}
+	OpenAPI struct {
+		Validate bool `env:"OPENAPI_VALIDATE" env-default:"true"`
+		Strict   bool `env:"OPENAPI_STRICT" env-default:"false"`
+	}
+	GraphQL struct {
+		MaxDepth      int `env:"GRAPHQL_MAX_DEPTH" env-default:"10"`
+		MaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" env-default:"2000"`
+	}
+	Subscriptions struct {
+		OverlapPolicy string `env:"OVERLAP_POLICY" env-default:"warn"`
+	}
+	Alerts struct {
+		PriceIncreasePercent float64 `env:"PRICE_INCREASE_PERCENT" env-default:"10"`
+	}
+	Privacy struct {
+		SigningKey string `env:"PRIVACY_SIGNING_KEY"`
+	}
+	Warehouse struct {
+		Dir   string `env:"WAREHOUSE_EXPORT_DIR" env-default:"exports"`
+		Token string `env:"WAREHOUSE_EXPORT_TOKEN"`
+	}
+	Webhooks struct {
+		PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"5s"`
+		Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+		MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+		Backoff      time.Duration `env:"WEBHOOK_BACKOFF" env-default:"30s"`
+		Token        string        `env:"WEBHOOK_TOKEN"`
+	}
+	Events struct {
+		Retention time.Duration `env:"EVENTS_RETENTION" env-default:"24h"`
+		Heartbeat time.Duration `env:"EVENTS_HEARTBEAT" env-default:"15s"`
+	}
+	Outbox struct {
+		Sink         string        `env:"OUTBOX_SINK" env-default:"stdout"`
+		File         string        `env:"OUTBOX_FILE" env-default:"outbox.jsonl"`
+		PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+		BatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+		Retention    time.Duration `env:"OUTBOX_RETENTION" env-default:"168h"`
+		NATSURL      string        `env:"OUTBOX_NATS_URL" env-default:"nats://127.0.0.1:4222"`
+		NATSSubject  string        `env:"OUTBOX_NATS_SUBJECT" env-default:"subscriptions"`
+		KafkaBrokers []string      `env:"OUTBOX_KAFKA_BROKERS" env-default:"127.0.0.1:9092" env-separator:","`
+		KafkaTopic   string        `env:"OUTBOX_KAFKA_TOPIC" env-default:"subscription-events"`
+	}
 }
 
 var instance *Config
@@ -35,8 +87,24 @@ func GetConfig() *Config {
 		if err != nil {
 			l.Fatalln("read app configuration error")
 		}
+		if err = instance.validate(); err != nil {
+			l.Fatalln(err)
+		}
 		instance.Listen.Addr = instance.Listen.BindIP + ":" + instance.Listen.Port
+		instance.GRPC.Addr = instance.Listen.BindIP + ":" + instance.GRPC.Port
 		l.Infoln("reading config OK")
 	})
 	return instance
 }
+
+// validate rejects the settings that would otherwise fall back to a default
+// unnoticed.
+func (cfg *Config) validate() error {
+	switch cfg.Subscriptions.OverlapPolicy {
+	case "warn", "reject":
+	default:
+		return fmt.Errorf("read app configuration error, unknown OVERLAP_POLICY %q, warn or reject required",
+			cfg.Subscriptions.OverlapPolicy)
+	}
+	return nil
+}
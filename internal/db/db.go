@@ -2,10 +2,14 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"main/internal/model"
 	"main/pkg/logger"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,20 +26,29 @@ func NewDataBase(pool *pgxpool.Pool, logger *logger.Logger) Storage {
 }
 
 func (d *db) Save(ctx context.Context, dto model.SubscriptionDTO) (id int, err error) {
-	query := `
+	query := withOutbox(`
 		INSERT INTO subscriptions (
 			service_name,
 			price,
 			user_id,
 			start_date,
-			end_date
+			end_date,
+			billing_period,
+			trial_end_date
 		)
-		VALUES ($1, $2, $3, $4, $5)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING 
-			id
-	`
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+	`, model.OutboxSubscriptionCreated)
 	err = d.conn.QueryRow(ctx, query, dto.ServiceName, dto.Price, dto.UserId,
-		dto.StartDate, dto.EndDate).Scan(&id)
+		dto.StartDate, dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate)).Scan(&id)
 	if id == 0 || err != nil {
 		return id, fmt.Errorf("database error, failed to save sub: %v", err)
 	}
@@ -50,44 +63,38 @@ func (d *db) Load(ctx context.Context, subID int) (dto model.SubscriptionDTO, er
 			price,
 			user_id,
 			start_date,
-			end_date
+			end_date,
+			billing_period,
+			trial_end_date
 		FROM 
 			subscriptions
 		WHERE
 			id = $1
 	`
+	var trialEnd *time.Time
 	row := d.conn.QueryRow(ctx, query, subID)
 	err = row.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
-		&dto.StartDate, &dto.EndDate)
+		&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto, ErrNotFound
+	}
 	if err != nil {
-		return dto, err
+		return dto, fmt.Errorf("database error, failed to load sub: %v", err)
+	}
+	if trialEnd != nil {
+		dto.TrialEndDate = *trialEnd
 	}
 	return dto, nil
 }
 
-func (d *db) LoadList(ctx context.Context) (dtoList []model.SubscriptionDTO, err error) {
-	query := `
-		SELECT 
-			id,
-			service_name,
-			price,
-			user_id,
-			start_date,
-			end_date
-		FROM 
-			subscriptions
-	`
-	rows, err := d.conn.Query(ctx, query)
+func (d *db) LoadList(ctx context.Context, filter model.ListFilter) (dtoList []model.SubscriptionDTO, err error) {
+	err = d.IterateList(ctx, filter, func(dto model.SubscriptionDTO) error {
+		dtoList = append(dtoList, dto)
+		return nil
+	})
 	if err != nil {
 		return dtoList, err
 	}
-	for rows.Next() {
-		dto := model.SubscriptionDTO{}
-		rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
-			&dto.StartDate, &dto.EndDate)
-		dtoList = append(dtoList, dto)
-	}
-
 	if len(dtoList) == 0 {
 		return dtoList, fmt.Errorf("database error, sub list is empty")
 	}
@@ -95,25 +102,37 @@ func (d *db) LoadList(ctx context.Context) (dtoList []model.SubscriptionDTO, err
 }
 
 func (d *db) Delete(ctx context.Context, subID int) (err error) {
-	query := `
+	query := withOutbox(`
 		DELETE 
 		FROM 
 			subscriptions
 		WHERE
 			id = $1
 		RETURNING 
-			id
-	`
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+	`, model.OutboxSubscriptionDeleted)
 	var tempId int
-	d.conn.QueryRow(ctx, query, subID).Scan(&tempId)
-	if tempId != subID {
-		return fmt.Errorf("database delete error, sub not found")
+	err = d.conn.QueryRow(ctx, query, subID).Scan(&tempId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("database error, failed to delete sub: %v", err)
 	}
 	return nil
 }
 
-func (d *db) Update(ctx context.Context, dto model.SubscriptionDTO) (err error) {
-	query := `
+// Update saves the sub and, when set, the price change event of the update in
+// one transaction.
+func (d *db) Update(ctx context.Context, dto model.SubscriptionDTO, event *model.EventDTO) (err error) {
+	query := withOutbox(`
 		UPDATE
 			subscriptions
 		SET
@@ -121,17 +140,42 @@ func (d *db) Update(ctx context.Context, dto model.SubscriptionDTO) (err error)
 			price = $3,
 			user_id = $4,
 			start_date = $5,
-			end_date = $6
+			end_date = $6,
+			billing_period = $7,
+			trial_end_date = $8
 		WHERE
 			id = $1
-	`
-	res, err := d.conn.Exec(ctx, query, dto.Id, dto.ServiceName, dto.Price, dto.UserId,
-		dto.StartDate, dto.EndDate)
+		RETURNING 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+	`, model.OutboxSubscriptionUpdated)
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, query, dto.Id, dto.ServiceName, dto.Price, dto.UserId,
+		dto.StartDate, dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate))
 	if err != nil {
 		return fmt.Errorf("database error, failed to update sub: %v", err)
 	}
 	if res.RowsAffected() == 0 {
-		return fmt.Errorf("database error, no rows updated")
+		return ErrNotFound
+	}
+	if event != nil {
+		if _, err = saveEvent(ctx, tx, *event); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("database error, failed to commit sub update: %v", err)
 	}
 	return nil
 }
@@ -159,3 +203,31 @@ func (d *db) Cost(ctx context.Context, data model.CostDTO) (cost int, err error)
 	}
 	return cost, nil
 }
+
+func nullableDate(date time.Time) any {
+	if date.IsZero() {
+		return nil
+	}
+	return date
+}
+
+func nullableUserId(id uuid.UUID) any {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id.String()
+}
+
+func nullableString(str string) any {
+	if str == "" {
+		return nil
+	}
+	return str
+}
+
+func nullableInt(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+)
+
+func (d *db) PopularServices(ctx context.Context, data model.AnalyticsDTO) (list []model.ServicePopularity, err error) {
+	query := `
+		WITH months AS (
+			SELECT
+				generate_series(
+					date_trunc('month', $1::date),
+					date_trunc('month', $2::date),
+					interval '1 month'
+				)::date AS month
+		),
+		ranked AS (
+			SELECT
+				s.service_name,
+				count(DISTINCT s.user_id) AS subscribers,
+				COALESCE(sum(COALESCE(p.price, s.price)) FILTER (WHERE c.charged), 0) AS revenue
+			FROM
+				months m
+			JOIN
+				subscriptions s
+				ON s.start_date <= m.month
+				AND (s.end_date IS NULL OR s.end_date < s.start_date OR s.end_date >= m.month)
+			CROSS JOIN LATERAL (
+				SELECT
+					(s.billing_period <> 'yearly' OR extract(month FROM s.start_date) = extract(month FROM m.month))
+					AND (s.trial_end_date IS NULL OR s.trial_end_date < m.month) AS charged
+			) c
+			LEFT JOIN LATERAL (
+				SELECT
+					price
+				FROM
+					scheduled_prices
+				WHERE
+					subscription_id = s.id
+					AND effective_date <= m.month
+				ORDER BY
+					effective_date DESC, id DESC
+				LIMIT
+					1
+			) p ON true
+			GROUP BY
+				s.service_name
+		)
+		SELECT
+			service_name,
+			subscribers,
+			revenue
+		FROM
+			ranked
+		ORDER BY
+			CASE WHEN $3 = 'revenue' THEN revenue ELSE subscribers END DESC,
+			service_name
+		LIMIT
+			$4
+	`
+	rows, err := d.conn.Query(ctx, query, data.StartDate, data.EndDate, data.OrderBy, data.Limit)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load popular services: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.ServicePopularity{}
+		err = rows.Scan(&item.ServiceName, &item.Subscribers, &item.Revenue)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan popular services: %v", err)
+		}
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load popular services: %v", err)
+	}
+	return list, nil
+}
+
+func (d *db) PriceStats(ctx context.Context, data model.AnalyticsDTO) (list []model.ServicePriceStats, err error) {
+	query := `
+		SELECT
+			service_name,
+			count(*),
+			avg(price)::float8,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY price)
+		FROM
+			subscriptions
+		WHERE
+			start_date < $2::date + interval '1 month'
+			AND (end_date IS NULL OR end_date < start_date OR end_date >= $1)
+		GROUP BY
+			service_name
+		ORDER BY
+			service_name
+	`
+	rows, err := d.conn.Query(ctx, query, data.StartDate, data.EndDate)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load price stats: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.ServicePriceStats{}
+		err = rows.Scan(&item.ServiceName, &item.Subscriptions, &item.AveragePrice, &item.MedianPrice)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan price stats: %v", err)
+		}
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load price stats: %v", err)
+	}
+	return list, nil
+}
+
+func (d *db) Churn(ctx context.Context, data model.AnalyticsDTO) (list []model.MonthChurnDTO, err error) {
+	query := `
+		WITH months AS (
+			SELECT
+				generate_series(
+					date_trunc('month', $1::date),
+					date_trunc('month', $2::date),
+					interval '1 month'
+				)::date AS month
+		),
+		started AS (
+			SELECT
+				date_trunc('month', start_date)::date AS month,
+				count(*) AS total
+			FROM
+				subscriptions
+			WHERE
+				start_date >= date_trunc('month', $1::date)
+				AND start_date < $2::date + interval '1 month'
+			GROUP BY
+				1
+		),
+		cancelled AS (
+			SELECT
+				date_trunc('month', end_date)::date AS month,
+				count(*) AS total
+			FROM
+				subscriptions
+			WHERE
+				end_date >= start_date
+				AND end_date >= date_trunc('month', $1::date)
+				AND end_date < $2::date + interval '1 month'
+			GROUP BY
+				1
+		)
+		SELECT
+			m.month,
+			COALESCE(st.total, 0),
+			COALESCE(c.total, 0)
+		FROM
+			months m
+		LEFT JOIN
+			started st ON st.month = m.month
+		LEFT JOIN
+			cancelled c ON c.month = m.month
+		ORDER BY
+			m.month
+	`
+	rows, err := d.conn.Query(ctx, query, data.StartDate, data.EndDate)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load churn: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.MonthChurnDTO{}
+		err = rows.Scan(&dto.Month, &dto.New, &dto.Cancelled)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan churn: %v", err)
+		}
+		list = append(list, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load churn: %v", err)
+	}
+	return list, nil
+}
+
+func (d *db) Lifetime(ctx context.Context, data model.AnalyticsDTO) (lifetime model.SubscriptionLifetime, err error) {
+	query := `
+		SELECT
+			count(*),
+			COALESCE(
+				avg(
+					extract(year FROM age(end_date, start_date)) * 12
+					+ extract(month FROM age(end_date, start_date))
+					+ 1
+				),
+				0
+			)::float8
+		FROM
+			subscriptions
+		WHERE
+			end_date >= start_date
+			AND end_date >= date_trunc('month', $1::date)
+			AND end_date < $2::date + interval '1 month'
+	`
+	row := d.conn.QueryRow(ctx, query, data.StartDate, data.EndDate)
+	err = row.Scan(&lifetime.Subscriptions, &lifetime.AverageMonths)
+	if err != nil {
+		return lifetime, fmt.Errorf("database error, failed to load lifetime: %v", err)
+	}
+	return lifetime, nil
+}
@@ -0,0 +1,356 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// querier runs queries on the pool or in a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// reader implements the Snapshot reads on the pool for the storage itself,
+// or in the transaction of ReadSnapshot.
+type reader struct {
+	conn querier
+}
+
+// ReadSnapshot calls fn with reads that all see the data as of the same
+// moment, from one repeatable read, read only transaction, so a backup taken
+// while the data changes still holds consistent references.
+func (d *db) ReadSnapshot(ctx context.Context, fn func(snapshot Snapshot) error) (err error) {
+	tx, err := d.conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = fn(reader{conn: tx}); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("database error, failed to commit snapshot: %v", err)
+	}
+	return nil
+}
+
+func (d *db) IterateScheduledPrices(ctx context.Context, fn func(price model.ScheduledPriceDTO) error) (err error) {
+	return reader{conn: d.conn}.IterateScheduledPrices(ctx, fn)
+}
+
+func (r reader) IterateScheduledPrices(ctx context.Context, fn func(price model.ScheduledPriceDTO) error) (err error) {
+	query := `
+		SELECT 
+			id,
+			subscription_id,
+			price,
+			effective_date
+		FROM 
+			scheduled_prices
+		ORDER BY
+			id
+	`
+	rows, err := r.conn.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("database error, failed to load scheduled prices: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.ScheduledPriceDTO{}
+		err = rows.Scan(&dto.Id, &dto.SubscriptionId, &dto.Price, &dto.EffectiveDate)
+		if err != nil {
+			return fmt.Errorf("database error, failed to scan scheduled price: %v", err)
+		}
+		if err = fn(dto); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("database error, failed to load scheduled prices: %v", err)
+	}
+	return nil
+}
+
+func (d *db) IterateEvents(ctx context.Context, fn func(event model.EventDTO) error) (err error) {
+	return reader{conn: d.conn}.IterateEvents(ctx, fn)
+}
+
+func (r reader) IterateEvents(ctx context.Context, fn func(event model.EventDTO) error) (err error) {
+	query := `
+		SELECT 
+			id,
+			type,
+			subscription_id,
+			user_id,
+			service_name,
+			COALESCE(old_price, 0),
+			COALESCE(new_price, 0),
+			created_at
+		FROM 
+			subscription_events
+		ORDER BY
+			id
+	`
+	rows, err := r.conn.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("database error, failed to load events: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.EventDTO{}
+		err = rows.Scan(&dto.Id, &dto.Type, &dto.SubscriptionId, &dto.UserId,
+			&dto.ServiceName, &dto.OldPrice, &dto.NewPrice, &dto.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("database error, failed to scan event: %v", err)
+		}
+		if err = fn(dto); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("database error, failed to load events: %v", err)
+	}
+	return nil
+}
+
+func (d *db) IterateExpirations(ctx context.Context, fn func(expiration model.ExpirationDTO) error) (err error) {
+	return reader{conn: d.conn}.IterateExpirations(ctx, fn)
+}
+
+func (r reader) IterateExpirations(ctx context.Context, fn func(expiration model.ExpirationDTO) error) (err error) {
+	query := `
+		SELECT 
+			subscription_id,
+			end_date,
+			notified_at
+		FROM 
+			subscription_expirations
+		ORDER BY
+			subscription_id, end_date
+	`
+	rows, err := r.conn.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("database error, failed to load expirations: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.ExpirationDTO{}
+		err = rows.Scan(&dto.SubscriptionId, &dto.EndDate, &dto.NotifiedAt)
+		if err != nil {
+			return fmt.Errorf("database error, failed to scan expiration: %v", err)
+		}
+		if err = fn(dto); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("database error, failed to load expirations: %v", err)
+	}
+	return nil
+}
+
+func (d *db) IterateErasures(ctx context.Context, fn func(erasure model.ErasureDTO) error) (err error) {
+	return reader{conn: d.conn}.IterateErasures(ctx, fn)
+}
+
+func (r reader) IterateErasures(ctx context.Context, fn func(erasure model.ErasureDTO) error) (err error) {
+	query := `
+		SELECT 
+			id,
+			user_hash,
+			removed,
+			erased_at
+		FROM 
+			user_erasures
+		ORDER BY
+			id
+	`
+	rows, err := r.conn.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("database error, failed to load erasures: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.ErasureDTO{}
+		err = rows.Scan(&dto.Id, &dto.UserHash, &dto.Removed, &dto.ErasedAt)
+		if err != nil {
+			return fmt.Errorf("database error, failed to scan erasure: %v", err)
+		}
+		if err = fn(dto); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("database error, failed to load erasures: %v", err)
+	}
+	return nil
+}
+
+// restoreTable describes a table to restore. The first keys columns are its
+// primary key, a serial id has its sequence moved past the restored rows.
+type restoreTable struct {
+	name    string
+	columns []string
+	keys    int
+	rows    [][]any
+}
+
+// Restore inserts archived rows with their original ids in one transaction.
+// Rows whose id already exists fail the restore, are skipped or are
+// overwritten depending on the policy. Inserted and overwritten subscriptions
+// write their created and updated outbox events like any other change.
+func (d *db) Restore(ctx context.Context, data model.BackupDTO, policy string) (counts []model.RestoreCount, err error) {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return counts, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	subs := make([][]any, 0, len(data.Subscriptions))
+	for _, dto := range data.Subscriptions {
+		subs = append(subs, []any{dto.Id, dto.ServiceName, dto.Price, dto.UserId, dto.StartDate,
+			dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate)})
+	}
+	prices := make([][]any, 0, len(data.ScheduledPrices))
+	for _, dto := range data.ScheduledPrices {
+		prices = append(prices, []any{dto.Id, dto.SubscriptionId, dto.Price, dto.EffectiveDate})
+	}
+	events := make([][]any, 0, len(data.Events))
+	for _, dto := range data.Events {
+		events = append(events, []any{dto.Id, dto.Type, dto.SubscriptionId, dto.UserId,
+			dto.ServiceName, dto.OldPrice, dto.NewPrice, dto.CreatedAt})
+	}
+	webhooks := make([][]any, 0, len(data.Webhooks))
+	for _, dto := range data.Webhooks {
+		webhooks = append(webhooks, []any{dto.Id, dto.URL, dto.Secret, dto.EventTypes, dto.Active, dto.CreatedAt})
+	}
+	expirations := make([][]any, 0, len(data.Expirations))
+	for _, dto := range data.Expirations {
+		expirations = append(expirations, []any{dto.SubscriptionId, dto.EndDate, dto.NotifiedAt})
+	}
+	erasures := make([][]any, 0, len(data.Erasures))
+	for _, dto := range data.Erasures {
+		removed, err := json.Marshal(dto.Removed)
+		if err != nil {
+			return counts, err
+		}
+		erasures = append(erasures, []any{dto.Id, dto.UserHash, removed, dto.ErasedAt})
+	}
+
+	tables := []restoreTable{
+		{model.TableSubscriptions, []string{"id", "service_name", "price", "user_id", "start_date",
+			"end_date", "billing_period", "trial_end_date"}, 1, subs},
+		{model.TableScheduledPrices, []string{"id", "subscription_id", "price", "effective_date"}, 1, prices},
+		{model.TableEvents, []string{"id", "type", "subscription_id", "user_id", "service_name",
+			"old_price", "new_price", "created_at"}, 1, events},
+		{model.TableWebhooks, []string{"id", "url", "secret", "event_types", "active", "created_at"}, 1, webhooks},
+		{model.TableExpirations, []string{"subscription_id", "end_date", "notified_at"}, 2, expirations},
+		{model.TableErasures, []string{"id", "user_hash", "removed", "erased_at"}, 1, erasures},
+	}
+	for _, t := range tables {
+		count, err := t.restore(ctx, tx, policy)
+		if err != nil {
+			return counts, err
+		}
+		counts = append(counts, count)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return counts, fmt.Errorf("database error, failed to commit restore: %v", err)
+	}
+	return counts, nil
+}
+
+func (t restoreTable) restore(ctx context.Context, tx pgx.Tx, policy string) (count model.RestoreCount, err error) {
+	count.Table = t.name
+	query := t.query(policy)
+	batch := &pgx.Batch{}
+	for _, row := range t.rows {
+		batch.Queue(query, row...)
+	}
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for _, row := range t.rows {
+		inserted := false
+		err = br.QueryRow().Scan(&inserted)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows) && policy == model.RestoreSkip:
+			count.Skipped++
+		case errors.Is(err, pgx.ErrNoRows):
+			return count, fmt.Errorf("database error, %s row with %s already exists", t.name, t.key(row))
+		case err != nil:
+			return count, fmt.Errorf("database error, failed to restore %s row with %s: %v", t.name, t.key(row), err)
+		case inserted:
+			count.Inserted++
+		default:
+			count.Updated++
+		}
+	}
+	if err = br.Close(); err != nil {
+		return count, fmt.Errorf("database error, failed to restore %s: %v", t.name, err)
+	}
+	if t.columns[0] != "id" {
+		return count, nil
+	}
+
+	query = fmt.Sprintf(`
+		SELECT 
+			setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE(max(id), 0) + 1, false)
+		FROM 
+			%[1]s
+	`, t.name)
+	if _, err = tx.Exec(ctx, query); err != nil {
+		return count, fmt.Errorf("database error, failed to reset %s id sequence: %v", t.name, err)
+	}
+	return count, nil
+}
+
+func (t restoreTable) query(policy string) string {
+	placeholders := make([]string, 0, len(t.columns))
+	updates := make([]string, 0, len(t.columns))
+	for i, c := range t.columns {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		if i >= t.keys {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", c, c))
+		}
+	}
+	conflict := "DO NOTHING"
+	if policy == model.RestoreOverwrite {
+		conflict = "DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (%s)
+		ON CONFLICT (%s) %s
+		RETURNING 
+			xmax = 0
+	`, t.name, strings.Join(t.columns, ", "), strings.Join(placeholders, ", "),
+		strings.Join(t.columns[:t.keys], ", "), conflict)
+	if t.name != model.TableSubscriptions {
+		return query
+	}
+	eventType := fmt.Sprintf("CASE WHEN inserted THEN '%s' ELSE '%s' END",
+		model.OutboxSubscriptionCreated, model.OutboxSubscriptionUpdated)
+	return outboxStatement(strings.Replace(query, "xmax = 0", "*, xmax = 0 AS inserted", 1), eventType, outboxPayload, "inserted")
+}
+
+// key formats the primary key of the row for errors, as in id 5.
+func (t restoreTable) key(row []any) string {
+	parts := make([]string, 0, t.keys)
+	for i := range t.keys {
+		parts = append(parts, fmt.Sprintf("%s %v", t.columns[i], row[i]))
+	}
+	return strings.Join(parts, ", ")
+}
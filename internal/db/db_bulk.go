@@ -0,0 +1,396 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveList saves the subs in one transaction. events[i], when set, is the
+// price change event of dtoList[i] and is saved with the id of the new sub.
+func (d *db) SaveList(ctx context.Context, dtoList []model.SubscriptionDTO, events []*model.EventDTO) (ids []int, err error) {
+	query := withOutbox(`
+		INSERT INTO subscriptions (
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+	`, model.OutboxSubscriptionCreated)
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return ids, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, dto := range dtoList {
+		batch.Queue(query, dto.ServiceName, dto.Price, dto.UserId, dto.StartDate,
+			dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate))
+	}
+	results := tx.SendBatch(ctx, batch)
+	for range dtoList {
+		var id int
+		err = results.QueryRow().Scan(&id)
+		if err != nil {
+			results.Close()
+			return nil, fmt.Errorf("database error, failed to save sub list: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = results.Close(); err != nil {
+		return nil, fmt.Errorf("database error, failed to save sub list: %v", err)
+	}
+	for i, event := range events {
+		if event == nil {
+			continue
+		}
+		dto := *event
+		dto.SubscriptionId = ids[i]
+		if _, err = saveEvent(ctx, tx, dto); err != nil {
+			return nil, err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("database error, failed to commit sub list: %v", err)
+	}
+	return ids, nil
+}
+
+func (d *db) IterateList(ctx context.Context, filter model.ListFilter, fn func(dto model.SubscriptionDTO) error) (err error) {
+	return reader{conn: d.conn}.IterateList(ctx, filter, fn)
+}
+
+func (r reader) IterateList(ctx context.Context, filter model.ListFilter, fn func(dto model.SubscriptionDTO) error) (err error) {
+	query := `
+		SELECT 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+		FROM 
+			subscriptions
+		WHERE
+			($1::text IS NULL OR user_id = $1)
+			AND ($2::text IS NULL OR service_name = $2)
+		ORDER BY
+			id
+		LIMIT $3
+		OFFSET $4
+	`
+	rows, err := r.conn.Query(ctx, query, nullableUserId(filter.UserId), nullableString(filter.ServiceName),
+		nullableInt(filter.Limit), filter.Offset)
+	if err != nil {
+		return fmt.Errorf("database error, failed to load sub list: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.SubscriptionDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
+			&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+		if err != nil {
+			return fmt.Errorf("database error, failed to scan sub: %v", err)
+		}
+		if trialEnd != nil {
+			dto.TrialEndDate = *trialEnd
+		}
+		if err = fn(dto); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("database error, failed to load sub list: %v", err)
+	}
+	return nil
+}
+
+func (d *db) CountList(ctx context.Context, filter model.ListFilter) (count int, err error) {
+	query := `
+		SELECT 
+			count(*)
+		FROM 
+			subscriptions
+		WHERE
+			($1::text IS NULL OR user_id = $1)
+			AND ($2::text IS NULL OR service_name = $2)
+	`
+	err = d.conn.QueryRow(ctx, query, nullableUserId(filter.UserId), nullableString(filter.ServiceName)).Scan(&count)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to count subs: %v", err)
+	}
+	return count, nil
+}
+
+func (d *db) LoadListByIds(ctx context.Context, ids []int) (dtoList []model.SubscriptionDTO, err error) {
+	query := `
+		SELECT 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+		FROM 
+			subscriptions
+		WHERE
+			id = ANY($1)
+		ORDER BY
+			id
+	`
+	rows, err := d.conn.Query(ctx, query, ids)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load subs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.SubscriptionDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
+			&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan sub: %v", err)
+		}
+		if trialEnd != nil {
+			dto.TrialEndDate = *trialEnd
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load subs: %v", err)
+	}
+	return dtoList, nil
+}
+
+func (d *db) LoadListByUsers(ctx context.Context, userIds []uuid.UUID) (dtoList []model.SubscriptionDTO, err error) {
+	query := `
+		SELECT 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+		FROM 
+			subscriptions
+		WHERE
+			user_id = ANY($1)
+		ORDER BY
+			id
+	`
+	rows, err := d.conn.Query(ctx, query, userIds)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load user subs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.SubscriptionDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
+			&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan user sub: %v", err)
+		}
+		if trialEnd != nil {
+			dto.TrialEndDate = *trialEnd
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load user subs: %v", err)
+	}
+	return dtoList, nil
+}
+
+func (d *db) ApplyBatch(ctx context.Context, ops []model.BatchOperationDTO, atomic bool) (results []model.BatchResultDTO, err error) {
+	if !atomic {
+		results = make([]model.BatchResultDTO, len(ops))
+		for i, op := range ops {
+			results[i].Id, results[i].Err = d.applyOperation(ctx, op)
+		}
+		return results, nil
+	}
+
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return results, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, op := range ops {
+		query, args := batchQuery(op)
+		batch.Queue(query, args...)
+		if op.Event != nil {
+			batch.Queue(saveEventQuery, eventArgs(*op.Event)...)
+		}
+	}
+	br := tx.SendBatch(ctx, batch)
+	results = make([]model.BatchResultDTO, len(ops))
+	failed := false
+	for i, op := range ops {
+		results[i].Id, results[i].Err = scanBatchResult(br.QueryRow())
+		if results[i].Err == nil && op.Event != nil {
+			var eventId int
+			if err = br.QueryRow().Scan(&eventId); err != nil {
+				results[i].Err = fmt.Errorf("database error, failed to save event: %v", err)
+			}
+		}
+		if results[i].Err != nil {
+			failed = true
+			break
+		}
+	}
+	if err = br.Close(); err != nil && !failed {
+		return results, fmt.Errorf("database error, failed to apply batch: %v", err)
+	}
+	if failed {
+		return results, nil
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return results, fmt.Errorf("database error, failed to commit batch: %v", err)
+	}
+	return results, nil
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// applyOperation applies one operation of a non-atomic batch, in a
+// transaction of its own when it comes with a price change event.
+func (d *db) applyOperation(ctx context.Context, op model.BatchOperationDTO) (id int, err error) {
+	query, args := batchQuery(op)
+	if op.Event == nil {
+		return scanBatchResult(d.conn.QueryRow(ctx, query, args...))
+	}
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return id, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if id, err = scanBatchResult(tx.QueryRow(ctx, query, args...)); err != nil {
+		return id, err
+	}
+	if _, err = saveEvent(ctx, tx, *op.Event); err != nil {
+		return id, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return id, fmt.Errorf("database error, failed to commit operation: %v", err)
+	}
+	return id, nil
+}
+
+func batchQuery(op model.BatchOperationDTO) (query string, args []any) {
+	dto := op.Sub
+	switch op.Op {
+	case model.BatchCreate:
+		query = withOutbox(`
+			INSERT INTO subscriptions (
+				service_name,
+				price,
+				user_id,
+				start_date,
+				end_date,
+				billing_period,
+				trial_end_date
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING 
+				id,
+				service_name,
+				price,
+				user_id,
+				start_date,
+				end_date,
+				billing_period,
+				trial_end_date
+		`, model.OutboxSubscriptionCreated)
+		return query, []any{dto.ServiceName, dto.Price, dto.UserId, dto.StartDate,
+			dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate)}
+	case model.BatchUpdate:
+		query = withOutbox(`
+			UPDATE
+				subscriptions
+			SET
+				service_name = $2,
+				price = $3,
+				user_id = $4,
+				start_date = $5,
+				end_date = $6,
+				billing_period = $7,
+				trial_end_date = $8
+			WHERE
+				id = $1
+			RETURNING 
+				id,
+				service_name,
+				price,
+				user_id,
+				start_date,
+				end_date,
+				billing_period,
+				trial_end_date
+		`, model.OutboxSubscriptionUpdated)
+		return query, []any{dto.Id, dto.ServiceName, dto.Price, dto.UserId, dto.StartDate,
+			dto.EndDate, dto.BillingPeriod, nullableDate(dto.TrialEndDate)}
+	}
+	query = withOutbox(`
+		DELETE 
+		FROM 
+			subscriptions
+		WHERE
+			id = $1
+		RETURNING 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+	`, model.OutboxSubscriptionDeleted)
+	return query, []any{dto.Id}
+}
+
+func scanBatchResult(row pgx.Row) (id int, err error) {
+	err = row.Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return id, ErrNotFound
+	}
+	if err != nil {
+		return id, fmt.Errorf("database error, failed to apply operation: %v", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const changesChannel = "subscription_changes"
+
+// ListenChanges listens on the channel of the subscriptions trigger and
+// calls notify with the id of every committed change until the context is
+// done or the connection fails. listening is called once the listener is
+// registered, changes committed after that are not missed.
+func (d *db) ListenChanges(ctx context.Context, listening func(), notify func(id int64)) (err error) {
+	conn, err := d.conn.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("database error, failed to acquire listener connection: %v", err)
+	}
+	// The connection keeps its LISTEN state, so it is not returned to the pool.
+	defer conn.Hijack().Close(context.WithoutCancel(ctx))
+
+	if _, err = conn.Exec(ctx, "LISTEN "+changesChannel); err != nil {
+		return fmt.Errorf("database error, failed to listen for changes: %v", err)
+	}
+	listening()
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("database error, failed to wait for changes: %v", err)
+		}
+		id, err := strconv.ParseInt(notification.Payload, 10, 64)
+		if err != nil {
+			d.logger.Warnf("invalid change notification %q", notification.Payload)
+			continue
+		}
+		notify(id)
+	}
+}
+
+func (d *db) LoadChanges(ctx context.Context, filter model.ChangeFilter) (list []model.SubscriptionChange, err error) {
+	query := `
+		SELECT
+			id,
+			type,
+			subscription_id,
+			COALESCE(user_id, ''),
+			changed_at,
+			payload
+		FROM
+			subscription_changes
+		WHERE
+			id > $1
+			AND ($2::bigint[] IS NULL OR id = ANY($2))
+			AND ($3::text IS NULL OR user_id = $3)
+		ORDER BY
+			id
+		LIMIT $4
+	`
+	var ids any
+	if filter.Ids != nil {
+		ids = filter.Ids
+	}
+	rows, err := d.conn.Query(ctx, query, filter.AfterId, ids, nullableUserId(filter.UserId), filter.Limit)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load changes: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.SubscriptionChange{}
+		var userId string
+		err = rows.Scan(&item.Id, &item.Type, &item.SubscriptionId, &userId, &item.ChangedAt, &item.Data)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan change: %v", err)
+		}
+		item.UserId, _ = uuid.Parse(userId)
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load changes: %v", err)
+	}
+	return list, nil
+}
+
+func (d *db) PruneChanges(ctx context.Context, before time.Time) (count int, err error) {
+	query := `
+		DELETE
+		FROM
+			subscription_changes
+		WHERE
+			changed_at < $1
+	`
+	res, err := d.conn.Exec(ctx, query, before)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to prune changes: %v", err)
+	}
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+)
+
+func (d *db) SaveEvent(ctx context.Context, dto model.EventDTO) (id int, err error) {
+	return saveEvent(ctx, d.conn, dto)
+}
+
+const saveEventQuery = `
+	INSERT INTO subscription_events (
+		type,
+		subscription_id,
+		user_id,
+		service_name,
+		old_price,
+		new_price
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING 
+		id
+`
+
+// saveEvent saves the event on the pool or in the transaction of the change
+// it reports.
+func saveEvent(ctx context.Context, conn queryRower, dto model.EventDTO) (id int, err error) {
+	err = conn.QueryRow(ctx, saveEventQuery, eventArgs(dto)...).Scan(&id)
+	if id == 0 || err != nil {
+		return id, fmt.Errorf("database error, failed to save event: %v", err)
+	}
+	return id, nil
+}
+
+func eventArgs(dto model.EventDTO) []any {
+	return []any{dto.Type, dto.SubscriptionId, dto.UserId, dto.ServiceName, dto.OldPrice, dto.NewPrice}
+}
+
+func (d *db) PriceIncreases(ctx context.Context, data model.PriceAlertDTO) (list []model.PriceIncrease, err error) {
+	query := `
+		SELECT
+			subscription_id,
+			user_id,
+			service_name,
+			old_price,
+			new_price,
+			round((new_price - old_price) * 100.0 / old_price, 2)::float8,
+			created_at
+		FROM
+			subscription_events
+		WHERE
+			type = $1
+			AND created_at >= $2
+			AND ($3::text IS NULL OR user_id = $3)
+			AND ($4::text IS NULL OR service_name = $4)
+		ORDER BY
+			created_at DESC, id DESC
+	`
+	rows, err := d.conn.Query(ctx, query, model.EventPriceChange, data.Since,
+		nullableUserId(data.UserId), nullableString(data.ServiceName))
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load price increases: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.PriceIncrease{}
+		err = rows.Scan(&item.SubscriptionId, &item.UserId, &item.ServiceName, &item.OldPrice,
+			&item.NewPrice, &item.Percent, &item.DetectedAt)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan price increase: %v", err)
+		}
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load price increases: %v", err)
+	}
+	return list, nil
+}
+
+func (d *db) PriceOutliers(ctx context.Context, data model.PriceAlertDTO) (list []model.PriceOutlier, err error) {
+	query := `
+		WITH active AS (
+			SELECT
+				id,
+				user_id,
+				service_name,
+				price
+			FROM
+				subscriptions
+			WHERE
+				end_date IS NULL
+				OR end_date < start_date
+				OR end_date >= date_trunc('month', now())
+		),
+		medians AS (
+			SELECT
+				service_name,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS median,
+				count(DISTINCT user_id) AS users
+			FROM
+				active
+			GROUP BY
+				service_name
+		)
+		SELECT
+			a.id,
+			a.user_id,
+			a.service_name,
+			a.price,
+			m.median,
+			round(((a.price - m.median) * 100 / m.median)::numeric, 2)::float8
+		FROM
+			active a
+		JOIN
+			medians m ON m.service_name = a.service_name
+		WHERE
+			m.users > 1
+			AND m.median > 0
+			AND a.price > m.median * (1 + $1::float8 / 100)
+			AND ($2::text IS NULL OR a.user_id = $2)
+			AND ($3::text IS NULL OR a.service_name = $3)
+		ORDER BY
+			a.price / m.median DESC, a.id
+	`
+	rows, err := d.conn.Query(ctx, query, data.Threshold,
+		nullableUserId(data.UserId), nullableString(data.ServiceName))
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load price outliers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.PriceOutlier{}
+		err = rows.Scan(&item.SubscriptionId, &item.UserId, &item.ServiceName, &item.Price,
+			&item.MedianPrice, &item.Percent)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan price outlier: %v", err)
+		}
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load price outliers: %v", err)
+	}
+	return list, nil
+}
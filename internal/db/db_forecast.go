@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (d *db) LoadListByUser(ctx context.Context, userId uuid.UUID) (dtoList []model.SubscriptionDTO, err error) {
+	query := `
+		SELECT 
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date
+		FROM 
+			subscriptions
+		WHERE
+			user_id = $1
+		ORDER BY
+			id
+	`
+	rows, err := d.conn.Query(ctx, query, userId)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load user subs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.SubscriptionDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
+			&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan user sub: %v", err)
+		}
+		if trialEnd != nil {
+			dto.TrialEndDate = *trialEnd
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load user subs: %v", err)
+	}
+	return dtoList, nil
+}
+
+func (d *db) SaveScheduledPrice(ctx context.Context, dto model.ScheduledPriceDTO) (id int, err error) {
+	query := `
+		INSERT INTO scheduled_prices (
+			subscription_id,
+			price,
+			effective_date
+		)
+		VALUES ($1, $2, $3)
+		RETURNING 
+			id
+	`
+	err = d.conn.QueryRow(ctx, query, dto.SubscriptionId, dto.Price, dto.EffectiveDate).Scan(&id)
+	if id == 0 || err != nil {
+		return id, fmt.Errorf("database error, failed to save scheduled price: %v", err)
+	}
+	return id, nil
+}
+
+func (d *db) LoadScheduledPrices(ctx context.Context, subID int) (dtoList []model.ScheduledPriceDTO, err error) {
+	query := `
+		SELECT 
+			id,
+			subscription_id,
+			price,
+			effective_date
+		FROM 
+			scheduled_prices
+		WHERE
+			subscription_id = $1
+		ORDER BY
+			effective_date, id
+	`
+	return d.loadScheduledPrices(ctx, query, subID)
+}
+
+func (d *db) LoadScheduledPricesByUser(ctx context.Context, userId uuid.UUID) (dtoList []model.ScheduledPriceDTO, err error) {
+	query := `
+		SELECT 
+			p.id,
+			p.subscription_id,
+			p.price,
+			p.effective_date
+		FROM 
+			scheduled_prices p
+		JOIN
+			subscriptions s ON s.id = p.subscription_id
+		WHERE
+			s.user_id = $1
+		ORDER BY
+			p.effective_date, p.id
+	`
+	return d.loadScheduledPrices(ctx, query, userId)
+}
+
+func (d *db) loadScheduledPrices(ctx context.Context, query string, args ...any) (dtoList []model.ScheduledPriceDTO, err error) {
+	rows, err := d.conn.Query(ctx, query, args...)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load scheduled prices: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.ScheduledPriceDTO{}
+		err = rows.Scan(&dto.Id, &dto.SubscriptionId, &dto.Price, &dto.EffectiveDate)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan scheduled price: %v", err)
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load scheduled prices: %v", err)
+	}
+	return dtoList, nil
+}
@@ -3,13 +3,69 @@ package db
 import (
 	"context"
 	"main/internal/model"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Storage interface {
 	Save(ctx context.Context, sub model.SubscriptionDTO) (id int, err error)
+	SaveList(ctx context.Context, subList []model.SubscriptionDTO, events []*model.EventDTO) (ids []int, err error)
+	ApplyBatch(ctx context.Context, ops []model.BatchOperationDTO, atomic bool) (results []model.BatchResultDTO, err error)
 	Load(ctx context.Context, subID int) (sub model.SubscriptionDTO, err error)
-	LoadList(ctx context.Context) (subList []model.SubscriptionDTO, err error)
+	LoadList(ctx context.Context, filter model.ListFilter) (subList []model.SubscriptionDTO, err error)
+	IterateList(ctx context.Context, filter model.ListFilter, fn func(sub model.SubscriptionDTO) error) (err error)
+	CountList(ctx context.Context, filter model.ListFilter) (count int, err error)
+	Search(ctx context.Context, filter model.SearchDTO) (list []model.SearchResultDTO, err error)
+	LoadListByUser(ctx context.Context, userId uuid.UUID) (subList []model.SubscriptionDTO, err error)
+	LoadListByIds(ctx context.Context, ids []int) (subList []model.SubscriptionDTO, err error)
+	LoadListByUsers(ctx context.Context, userIds []uuid.UUID) (subList []model.SubscriptionDTO, err error)
 	Delete(ctx context.Context, subID int) (err error)
-	Update(ctx context.Context, sub model.SubscriptionDTO) (err error)
+	Update(ctx context.Context, sub model.SubscriptionDTO, event *model.EventDTO) (err error)
 	Cost(ctx context.Context, data model.CostDTO) (cost int, err error)
+	SpendReport(ctx context.Context, data model.SpendReportDTO) (report []model.MonthSpendDTO, err error)
+	PopularServices(ctx context.Context, data model.AnalyticsDTO) (list []model.ServicePopularity, err error)
+	PriceStats(ctx context.Context, data model.AnalyticsDTO) (list []model.ServicePriceStats, err error)
+	Churn(ctx context.Context, data model.AnalyticsDTO) (list []model.MonthChurnDTO, err error)
+	Lifetime(ctx context.Context, data model.AnalyticsDTO) (lifetime model.SubscriptionLifetime, err error)
+	SaveScheduledPrice(ctx context.Context, price model.ScheduledPriceDTO) (id int, err error)
+	LoadScheduledPrices(ctx context.Context, subID int) (prices []model.ScheduledPriceDTO, err error)
+	LoadScheduledPricesByUser(ctx context.Context, userId uuid.UUID) (prices []model.ScheduledPriceDTO, err error)
+	SaveEvent(ctx context.Context, event model.EventDTO) (id int, err error)
+	PriceIncreases(ctx context.Context, data model.PriceAlertDTO) (list []model.PriceIncrease, err error)
+	PriceOutliers(ctx context.Context, data model.PriceAlertDTO) (list []model.PriceOutlier, err error)
+	IterateScheduledPrices(ctx context.Context, fn func(price model.ScheduledPriceDTO) error) (err error)
+	IterateEvents(ctx context.Context, fn func(event model.EventDTO) error) (err error)
+	IterateExpirations(ctx context.Context, fn func(expiration model.ExpirationDTO) error) (err error)
+	IterateErasures(ctx context.Context, fn func(erasure model.ErasureDTO) error) (err error)
+	LoadEventsByUser(ctx context.Context, userId uuid.UUID) (events []model.EventDTO, err error)
+	EraseUser(ctx context.Context, data model.ErasureDTO) (erasure model.ErasureDTO, err error)
+	ReadSnapshot(ctx context.Context, fn func(snapshot Snapshot) error) (err error)
+	Restore(ctx context.Context, data model.BackupDTO, policy string) (counts []model.RestoreCount, err error)
+	SaveWebhook(ctx context.Context, webhook model.WebhookDTO) (id int, err error)
+	LoadWebhook(ctx context.Context, id int) (webhook model.WebhookDTO, err error)
+	LoadWebhooks(ctx context.Context) (webhooks []model.WebhookDTO, err error)
+	UpdateWebhook(ctx context.Context, webhook model.WebhookDTO) (err error)
+	DeleteWebhook(ctx context.Context, id int) (err error)
+	EnqueueDeliveries(ctx context.Context, eventId, eventType string, payload []byte) (count int, err error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (deliveries []model.DeliveryDTO, err error)
+	SaveDeliveryResult(ctx context.Context, result model.DeliveryResultDTO) (err error)
+	LoadDeliveries(ctx context.Context, filter model.DeliveryFilter) (list []model.WebhookDelivery, err error)
+	ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (id int, err error)
+	ExpireSubscriptions(ctx context.Context, now time.Time) (subList []model.SubscriptionDTO, err error)
+	RelayOutbox(ctx context.Context, limit int, fn func(events []model.OutboxEvent) (published []int64)) (count int, err error)
+	PruneOutbox(ctx context.Context, before time.Time) (count int, err error)
+	ListenChanges(ctx context.Context, listening func(), notify func(id int64)) (err error)
+	LoadChanges(ctx context.Context, filter model.ChangeFilter) (list []model.SubscriptionChange, err error)
+	PruneChanges(ctx context.Context, before time.Time) (count int, err error)
+}
+
+// Snapshot holds the reads of a backup, see Storage.ReadSnapshot.
+type Snapshot interface {
+	IterateList(ctx context.Context, filter model.ListFilter, fn func(sub model.SubscriptionDTO) error) (err error)
+	IterateScheduledPrices(ctx context.Context, fn func(price model.ScheduledPriceDTO) error) (err error)
+	IterateEvents(ctx context.Context, fn func(event model.EventDTO) error) (err error)
+	LoadWebhooks(ctx context.Context) (webhooks []model.WebhookDTO, err error)
+	IterateExpirations(ctx context.Context, fn func(expiration model.ExpirationDTO) error) (err error)
+	IterateErasures(ctx context.Context, fn func(erasure model.ErasureDTO) error) (err error)
 }
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"strconv"
+	"time"
+)
+
+// outboxPayload builds the subscription of an outbox event in the format of
+// the API.
+const outboxPayload = `
+	jsonb_build_object(
+		'id', id,
+		'service_name', service_name,
+		'price', price,
+		'user_id', user_id,
+		'start_date', to_char(start_date, 'MM-YYYY'),
+		'end_date', CASE WHEN end_date >= start_date THEN to_char(end_date, 'MM-YYYY') ELSE '' END,
+		'billing_period', billing_period,
+		'trial_end_date', COALESCE(to_char(trial_end_date, 'MM-YYYY'), '')
+	)
+`
+
+// erasedPayload is the data of the deleted events written for erased subs.
+// It holds nothing but the id, so the erasure does not copy the personal data
+// it removes into new outbox rows.
+const erasedPayload = `
+	jsonb_build_object(
+		'id', id
+	)
+`
+
+// outboxRelayLock is the advisory lock key held by the active relay.
+const outboxRelayLock = 7_120_001
+
+// withOutbox wraps a statement that changes subscription rows and returns
+// their columns. The outbox entries are written by the same statement, so
+// they commit or roll back together with the change.
+func withOutbox(change string, eventType string) string {
+	return outboxStatement(change, "'"+eventType+"'", outboxPayload, "id")
+}
+
+// outboxStatement is withOutbox with the event type and the payload computed
+// from the returned columns and the given result column selected.
+func outboxStatement(change, eventType, payload, result string) string {
+	return `
+		WITH changed AS (` + change + `),
+		outboxed AS (
+			INSERT INTO outbox (
+				subscription_id,
+				event_type,
+				schema_version,
+				payload
+			)
+			SELECT
+				id,
+				` + eventType + `,
+				` + strconv.Itoa(model.OutboxSchemaVersion) + `,
+				` + payload + `
+			FROM
+				changed
+		)
+		SELECT
+			` + result + `
+		FROM
+			changed
+	`
+}
+
+// RelayOutbox passes the oldest unpublished events, in the order they were
+// written, to fn and marks the ids it returns as published. Only one relay
+// works at a time: the call returns zero without calling fn while another
+// one holds the lock. Events are marked after fn returns, so an event may be
+// published again if the commit fails.
+func (d *db) RelayOutbox(ctx context.Context, limit int, fn func(events []model.OutboxEvent) (published []int64)) (count int, err error) {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLock).Scan(&locked)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to lock outbox: %v", err)
+	}
+	if !locked {
+		return count, nil
+	}
+
+	query := `
+		SELECT
+			id,
+			event_type,
+			schema_version,
+			subscription_id,
+			created_at,
+			payload
+		FROM
+			outbox
+		WHERE
+			published_at IS NULL
+		ORDER BY
+			id
+		LIMIT $1
+	`
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to load outbox: %v", err)
+	}
+	events := []model.OutboxEvent{}
+	for rows.Next() {
+		event := model.OutboxEvent{}
+		err = rows.Scan(&event.Id, &event.Type, &event.SchemaVersion, &event.SubscriptionId,
+			&event.OccurredAt, &event.Data)
+		if err != nil {
+			rows.Close()
+			return count, fmt.Errorf("database error, failed to scan outbox event: %v", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return count, fmt.Errorf("database error, failed to load outbox: %v", err)
+	}
+	if len(events) == 0 {
+		return count, nil
+	}
+
+	published := fn(events)
+	query = `
+		UPDATE
+			outbox
+		SET
+			published_at = now()
+		WHERE
+			id = ANY($1)
+	`
+	if _, err = tx.Exec(ctx, query, published); err != nil {
+		return count, fmt.Errorf("database error, failed to mark outbox events: %v", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return count, fmt.Errorf("database error, failed to commit outbox: %v", err)
+	}
+	return len(published), nil
+}
+
+func (d *db) PruneOutbox(ctx context.Context, before time.Time) (count int, err error) {
+	query := `
+		DELETE
+		FROM
+			outbox
+		WHERE
+			published_at < $1
+	`
+	res, err := d.conn.Exec(ctx, query, before)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to prune outbox: %v", err)
+	}
+	return int(res.RowsAffected()), nil
+}
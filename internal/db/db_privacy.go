@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func (d *db) LoadEventsByUser(ctx context.Context, userId uuid.UUID) (dtoList []model.EventDTO, err error) {
+	query := `
+		SELECT 
+			id,
+			type,
+			subscription_id,
+			user_id,
+			service_name,
+			COALESCE(old_price, 0),
+			COALESCE(new_price, 0),
+			created_at
+		FROM 
+			subscription_events
+		WHERE
+			user_id = $1
+		ORDER BY
+			created_at, id
+	`
+	rows, err := d.conn.Query(ctx, query, userId)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load events: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.EventDTO{}
+		err = rows.Scan(&dto.Id, &dto.Type, &dto.SubscriptionId, &dto.UserId,
+			&dto.ServiceName, &dto.OldPrice, &dto.NewPrice, &dto.CreatedAt)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan event: %v", err)
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load events: %v", err)
+	}
+	return dtoList, nil
+}
+
+// EraseUser deletes every row tied to the user and leaves a tombstone that
+// holds only a keyed hash of the user id and the removed row counts. The
+// user's outbox events go too, and a subscription.deleted event that carries
+// only the subscription id is written for every erased subscription, so that
+// consumers drop their copies.
+func (d *db) EraseUser(ctx context.Context, dto model.ErasureDTO) (erasure model.ErasureDTO, err error) {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return erasure, fmt.Errorf("database error, failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deletes := []struct {
+		table string
+		query string
+	}{
+		{model.TableScheduledPrices, `
+			DELETE 
+			FROM 
+				scheduled_prices
+			WHERE
+				subscription_id IN (SELECT id FROM subscriptions WHERE user_id = $1)
+		`},
+		{model.TableEvents, `
+			DELETE 
+			FROM 
+				subscription_events
+			WHERE
+				user_id = $1
+		`},
+		{model.TableWebhookDeliveries, `
+			DELETE 
+			FROM 
+				webhook_deliveries
+			WHERE
+				payload->'data'->>'user_id' = $1::uuid::text
+		`},
+		{model.TableOutbox, `
+			DELETE 
+			FROM 
+				outbox
+			WHERE
+				payload->>'user_id' = $1::uuid::text
+		`},
+		{model.TableSubscriptions, outboxStatement(`
+			DELETE 
+			FROM 
+				subscriptions
+			WHERE
+				user_id = $1
+			RETURNING 
+				id
+		`, "'"+model.OutboxSubscriptionDeleted+"'", erasedPayload, "id")},
+		{model.TableChanges, `
+			DELETE 
+			FROM 
+				subscription_changes
+			WHERE
+				user_id = $1
+		`},
+	}
+	erasure = dto
+	erasure.Removed = make([]model.ErasedTable, 0, len(deletes))
+	for _, del := range deletes {
+		tag, err := tx.Exec(ctx, del.query, dto.UserId)
+		if err != nil {
+			return erasure, fmt.Errorf("database error, failed to erase %s: %v", del.table, err)
+		}
+		erasure.Removed = append(erasure.Removed, model.ErasedTable{Table: del.table, Rows: int(tag.RowsAffected())})
+	}
+
+	removed, err := json.Marshal(erasure.Removed)
+	if err != nil {
+		return erasure, err
+	}
+	query := `
+		INSERT INTO user_erasures (
+			user_hash,
+			removed
+		)
+		VALUES ($1, $2)
+		RETURNING 
+			id,
+			erased_at
+	`
+	err = tx.QueryRow(ctx, query, dto.UserHash, removed).Scan(&erasure.Id, &erasure.ErasedAt)
+	if err != nil {
+		return erasure, fmt.Errorf("database error, failed to save erasure: %v", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return erasure, fmt.Errorf("database error, failed to commit erasure: %v", err)
+	}
+	return erasure, nil
+}
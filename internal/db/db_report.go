@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+)
+
+func (d *db) SpendReport(ctx context.Context, data model.SpendReportDTO) (report []model.MonthSpendDTO, err error) {
+	query := `
+		WITH months AS (
+			SELECT
+				generate_series(
+					date_trunc('month', $1::date),
+					date_trunc('month', $2::date),
+					interval '1 month'
+				)::date AS month
+		),
+		spend AS (
+			SELECT
+				m.month,
+				CASE WHEN $4 THEN s.user_id END AS user_id,
+				s.service_name,
+				sum(COALESCE(p.price, s.price)) AS total
+			FROM
+				months m
+			JOIN
+				subscriptions s
+				ON s.start_date <= m.month
+				AND (s.end_date IS NULL OR s.end_date < s.start_date OR s.end_date >= m.month)
+				AND (s.billing_period <> 'yearly' OR extract(month FROM s.start_date) = extract(month FROM m.month))
+				AND (s.trial_end_date IS NULL OR s.trial_end_date < m.month)
+			LEFT JOIN LATERAL (
+				SELECT
+					price
+				FROM
+					scheduled_prices
+				WHERE
+					subscription_id = s.id
+					AND effective_date <= m.month
+				ORDER BY
+					effective_date DESC, id DESC
+				LIMIT
+					1
+			) p ON true
+			WHERE
+				$3::text IS NULL OR s.user_id = $3
+			GROUP BY
+				1, 2, 3
+		)
+		SELECT
+			m.month,
+			sp.user_id,
+			COALESCE(sum(sp.total), 0),
+			COALESCE(
+				json_agg(
+					json_build_object('service_name', sp.service_name, 'total', sp.total)
+					ORDER BY sp.service_name
+				) FILTER (WHERE sp.service_name IS NOT NULL),
+				'[]'::json
+			)
+		FROM
+			months m
+		LEFT JOIN
+			spend sp ON sp.month = m.month
+		GROUP BY
+			m.month, sp.user_id
+		ORDER BY
+			m.month, sp.user_id
+	`
+	rows, err := d.conn.Query(ctx, query, data.StartDate, data.EndDate,
+		nullableUserId(data.UserId), data.GroupByUser)
+	if err != nil {
+		return report, fmt.Errorf("database error, failed to build spend report: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.MonthSpendDTO{}
+		err = rows.Scan(&dto.Month, &dto.UserId, &dto.Total, &dto.Services)
+		if err != nil {
+			return report, fmt.Errorf("database error, failed to scan spend report: %v", err)
+		}
+		report = append(report, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return report, fmt.Errorf("database error, failed to build spend report: %v", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"strings"
+	"time"
+)
+
+// Search ranks the subscriptions by how close their service name is to the
+// query. The fuzzy mode matches names with a word similar to the query, the
+// prefix mode names starting with it, both ignoring case. The similarity
+// thresholds are the pg_trgm settings.
+func (d *db) Search(ctx context.Context, filter model.SearchDTO) (list []model.SearchResultDTO, err error) {
+	query := `
+		SELECT
+			id,
+			service_name,
+			price,
+			user_id,
+			start_date,
+			end_date,
+			billing_period,
+			trial_end_date,
+			word_similarity($1, service_name)::float8 AS score
+		FROM
+			subscriptions
+		WHERE
+			$1 <% service_name
+			AND ($2::text IS NULL OR user_id = $2)
+		ORDER BY
+			score DESC,
+			similarity($1, service_name) DESC,
+			service_name,
+			id
+		LIMIT $3
+	`
+	args := []any{filter.Query, nullableUserId(filter.UserId), filter.Limit}
+	if filter.Prefix {
+		query = `
+			SELECT
+				id,
+				service_name,
+				price,
+				user_id,
+				start_date,
+				end_date,
+				billing_period,
+				trial_end_date,
+				similarity($1, service_name)::float8 AS score
+			FROM
+				subscriptions
+			WHERE
+				service_name ILIKE $4
+				AND ($2::text IS NULL OR user_id = $2)
+			ORDER BY
+				score DESC,
+				service_name,
+				id
+			LIMIT $3
+		`
+		args = append(args, escapeLike(filter.Query)+"%")
+	}
+	rows, err := d.conn.Query(ctx, query, args...)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to search subs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := model.SearchResultDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&result.Sub.Id, &result.Sub.ServiceName, &result.Sub.Price, &result.Sub.UserId,
+			&result.Sub.StartDate, &result.Sub.EndDate, &result.Sub.BillingPeriod, &trialEnd, &result.Score)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan sub: %v", err)
+		}
+		if trialEnd != nil {
+			result.Sub.TrialEndDate = *trialEnd
+		}
+		list = append(list, result)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to search subs: %v", err)
+	}
+	return list, nil
+}
+
+// escapeLike makes the wildcards of a LIKE pattern match themselves.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
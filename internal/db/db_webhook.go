@@ -0,0 +1,391 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (d *db) SaveWebhook(ctx context.Context, dto model.WebhookDTO) (id int, err error) {
+	query := `
+		INSERT INTO webhooks (
+			url,
+			secret,
+			event_types,
+			active
+		)
+		VALUES ($1, $2, $3, $4)
+		RETURNING
+			id
+	`
+	err = d.conn.QueryRow(ctx, query, dto.URL, dto.Secret, dto.EventTypes, dto.Active).Scan(&id)
+	if id == 0 || err != nil {
+		return id, fmt.Errorf("database error, failed to save webhook: %v", err)
+	}
+	return id, nil
+}
+
+func (d *db) LoadWebhook(ctx context.Context, id int) (dto model.WebhookDTO, err error) {
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			event_types,
+			active,
+			created_at
+		FROM
+			webhooks
+		WHERE
+			id = $1
+	`
+	err = d.conn.QueryRow(ctx, query, id).Scan(&dto.Id, &dto.URL, &dto.Secret,
+		&dto.EventTypes, &dto.Active, &dto.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dto, ErrNotFound
+	}
+	if err != nil {
+		return dto, fmt.Errorf("database error, failed to load webhook: %v", err)
+	}
+	return dto, nil
+}
+
+func (d *db) LoadWebhooks(ctx context.Context) (dtoList []model.WebhookDTO, err error) {
+	return reader{conn: d.conn}.LoadWebhooks(ctx)
+}
+
+func (r reader) LoadWebhooks(ctx context.Context) (dtoList []model.WebhookDTO, err error) {
+	query := `
+		SELECT
+			id,
+			url,
+			secret,
+			event_types,
+			active,
+			created_at
+		FROM
+			webhooks
+		ORDER BY
+			id
+	`
+	rows, err := r.conn.Query(ctx, query)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.WebhookDTO{}
+		err = rows.Scan(&dto.Id, &dto.URL, &dto.Secret, &dto.EventTypes, &dto.Active, &dto.CreatedAt)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan webhook: %v", err)
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to load webhooks: %v", err)
+	}
+	return dtoList, nil
+}
+
+func (d *db) UpdateWebhook(ctx context.Context, dto model.WebhookDTO) (err error) {
+	query := `
+		UPDATE
+			webhooks
+		SET
+			url = $2,
+			secret = $3,
+			event_types = $4,
+			active = $5
+		WHERE
+			id = $1
+	`
+	res, err := d.conn.Exec(ctx, query, dto.Id, dto.URL, dto.Secret, dto.EventTypes, dto.Active)
+	if err != nil {
+		return fmt.Errorf("database error, failed to update webhook: %v", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (d *db) DeleteWebhook(ctx context.Context, id int) (err error) {
+	query := `
+		DELETE
+		FROM
+			webhooks
+		WHERE
+			id = $1
+	`
+	res, err := d.conn.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("database error, failed to delete webhook: %v", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries adds a pending delivery of the event for every active
+// webhook subscribed to its type.
+func (d *db) EnqueueDeliveries(ctx context.Context, eventId, eventType string, payload []byte) (count int, err error) {
+	query := `
+		INSERT INTO webhook_deliveries (
+			webhook_id,
+			event_id,
+			event_type,
+			payload
+		)
+		SELECT
+			id,
+			$1,
+			$2,
+			$3
+		FROM
+			webhooks
+		WHERE
+			active
+			AND $2 = ANY(event_types)
+	`
+	res, err := d.conn.Exec(ctx, query, eventId, eventType, payload)
+	if err != nil {
+		return count, fmt.Errorf("database error, failed to enqueue deliveries: %v", err)
+	}
+	return int(res.RowsAffected()), nil
+}
+
+// ClaimDeliveries takes up to limit due deliveries of active webhooks and
+// pushes their next attempt back by the lease, so a dispatcher that dies
+// mid-send only delays the retry. Concurrent dispatchers skip each other's
+// rows.
+func (d *db) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) (dtoList []model.DeliveryDTO, err error) {
+	query := `
+		UPDATE
+			webhook_deliveries d
+		SET
+			attempts = d.attempts + 1,
+			next_attempt_at = now() + make_interval(secs => $2)
+		FROM
+			webhooks w
+		WHERE
+			w.id = d.webhook_id
+			AND d.id IN (
+				SELECT
+					id
+				FROM
+					webhook_deliveries
+				WHERE
+					status = $3
+					AND next_attempt_at <= now()
+					AND webhook_id IN (SELECT id FROM webhooks WHERE active)
+				ORDER BY
+					next_attempt_at, id
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+		RETURNING
+			d.id,
+			d.webhook_id,
+			d.event_id,
+			d.event_type,
+			d.payload,
+			d.attempts,
+			w.url,
+			w.secret
+	`
+	rows, err := d.conn.Query(ctx, query, limit, lease.Seconds(), model.DeliveryPending)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to claim deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.DeliveryDTO{}
+		err = rows.Scan(&dto.Id, &dto.WebhookId, &dto.EventId, &dto.EventType, &dto.Payload,
+			&dto.Attempts, &dto.URL, &dto.Secret)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan delivery: %v", err)
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to claim deliveries: %v", err)
+	}
+	return dtoList, nil
+}
+
+func (d *db) SaveDeliveryResult(ctx context.Context, dto model.DeliveryResultDTO) (err error) {
+	query := `
+		UPDATE
+			webhook_deliveries
+		SET
+			status = $2,
+			response_code = $3,
+			last_error = $4,
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			delivered_at = CASE WHEN $2 = $6 THEN now() END
+		WHERE
+			id = $1
+	`
+	_, err = d.conn.Exec(ctx, query, dto.Id, dto.Status, nullableInt(dto.ResponseCode),
+		nullableString(dto.LastError), nullableDate(dto.NextAttemptAt), model.DeliverySucceeded)
+	if err != nil {
+		return fmt.Errorf("database error, failed to save delivery result: %v", err)
+	}
+	return nil
+}
+
+func (d *db) LoadDeliveries(ctx context.Context, filter model.DeliveryFilter) (list []model.WebhookDelivery, err error) {
+	query := `
+		SELECT
+			id,
+			webhook_id,
+			event_id,
+			event_type,
+			payload,
+			status,
+			attempts,
+			COALESCE(response_code, 0),
+			COALESCE(last_error, ''),
+			next_attempt_at,
+			created_at,
+			delivered_at
+		FROM
+			webhook_deliveries
+		WHERE
+			webhook_id = $1
+			AND ($2::text IS NULL OR status = $2)
+		ORDER BY
+			id DESC
+		LIMIT $3
+		OFFSET $4
+	`
+	rows, err := d.conn.Query(ctx, query, filter.WebhookId, nullableString(filter.Status),
+		filter.Limit, filter.Offset)
+	if err != nil {
+		return list, fmt.Errorf("database error, failed to load deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := model.WebhookDelivery{}
+		var nextAttempt time.Time
+		err = rows.Scan(&item.Id, &item.WebhookId, &item.EventId, &item.EventType, &item.Payload,
+			&item.Status, &item.Attempts, &item.ResponseCode, &item.LastError, &nextAttempt,
+			&item.CreatedAt, &item.DeliveredAt)
+		if err != nil {
+			return list, fmt.Errorf("database error, failed to scan delivery: %v", err)
+		}
+		if item.Status == model.DeliveryPending {
+			item.NextAttemptAt = &nextAttempt
+		}
+		list = append(list, item)
+	}
+	if err = rows.Err(); err != nil {
+		return list, fmt.Errorf("database error, failed to load deliveries: %v", err)
+	}
+	return list, nil
+}
+
+// ReplayDelivery queues a new pending copy of a delivery. The copy keeps the
+// event id, so receivers can tell a replay from a new event.
+func (d *db) ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (id int, err error) {
+	query := `
+		INSERT INTO webhook_deliveries (
+			webhook_id,
+			event_id,
+			event_type,
+			payload
+		)
+		SELECT
+			webhook_id,
+			event_id,
+			event_type,
+			payload
+		FROM
+			webhook_deliveries
+		WHERE
+			id = $1
+			AND webhook_id = $2
+		RETURNING
+			id
+	`
+	err = d.conn.QueryRow(ctx, query, deliveryId, webhookId).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return id, ErrNotFound
+	}
+	if err != nil {
+		return id, fmt.Errorf("database error, failed to replay delivery: %v", err)
+	}
+	return id, nil
+}
+
+// ExpireSubscriptions records the subs whose last month ended within the
+// month before now and returns those not recorded before. Older endings are
+// left alone, so enabling webhooks does not replay the whole history.
+func (d *db) ExpireSubscriptions(ctx context.Context, now time.Time) (dtoList []model.SubscriptionDTO, err error) {
+	query := `
+		WITH expired AS (
+			INSERT INTO subscription_expirations (
+				subscription_id,
+				end_date
+			)
+			SELECT
+				id,
+				end_date
+			FROM
+				subscriptions
+			WHERE
+				end_date >= start_date
+				AND end_date + interval '1 month' <= $1
+				AND end_date + interval '2 months' > $1
+			ON CONFLICT DO NOTHING
+			RETURNING
+				subscription_id
+		)
+		SELECT
+			s.id,
+			s.service_name,
+			s.price,
+			s.user_id,
+			s.start_date,
+			s.end_date,
+			s.billing_period,
+			s.trial_end_date
+		FROM
+			subscriptions s
+		JOIN
+			expired e ON e.subscription_id = s.id
+		ORDER BY
+			s.id
+	`
+	rows, err := d.conn.Query(ctx, query, now)
+	if err != nil {
+		return dtoList, fmt.Errorf("database error, failed to expire subs: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		dto := model.SubscriptionDTO{}
+		var trialEnd *time.Time
+		err = rows.Scan(&dto.Id, &dto.ServiceName, &dto.Price, &dto.UserId,
+			&dto.StartDate, &dto.EndDate, &dto.BillingPeriod, &trialEnd)
+		if err != nil {
+			return dtoList, fmt.Errorf("database error, failed to scan sub: %v", err)
+		}
+		if trialEnd != nil {
+			dto.TrialEndDate = *trialEnd
+		}
+		dtoList = append(dtoList, dto)
+	}
+	if err = rows.Err(); err != nil {
+		return dtoList, fmt.Errorf("database error, failed to expire subs: %v", err)
+	}
+	return dtoList, nil
+}
@@ -0,0 +1,7 @@
+package db
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("database error, sub not found")
+)
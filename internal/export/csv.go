@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+const csvFlushRows = 100
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{
+		w: csv.NewWriter(w),
+	}
+}
+
+func (c *csvWriter) WriteHeader(columns []Column) error {
+	record := make([]string, 0, len(columns))
+	for _, col := range columns {
+		record = append(record, col.Name)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) WriteRow(values ...any) error {
+	record := make([]string, 0, len(values))
+	for _, v := range values {
+		record = append(record, c.format(v))
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Abort() {}
+
+func (c *csvWriter) format(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("01-2006")
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Integer
+	Number
+	Month
+)
+
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+type Writer interface {
+	WriteHeader(columns []Column) error
+	WriteRow(values ...any) error
+	Close() error
+	Abort()
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return ""
+}
+
+func FormatFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.Split(part, ";")[0])
+		switch mediaType {
+		case "text/csv":
+			return FormatCSV
+		case ContentType(FormatXLSX):
+			return FormatXLSX
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSheet = "Sheet1"
+
+type xlsxWriter struct {
+	out        io.Writer
+	file       *excelize.File
+	stream     *excelize.StreamWriter
+	columns    []Column
+	row        int
+	monthStyle int
+	numStyle   int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	monthFormat := "mm-yyyy"
+	monthStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: &monthFormat})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	numStyle, err := file.NewStyle(&excelize.Style{NumFmt: 2})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{
+		out:        w,
+		file:       file,
+		stream:     stream,
+		monthStyle: monthStyle,
+		numStyle:   numStyle,
+	}, nil
+}
+
+func (x *xlsxWriter) WriteHeader(columns []Column) error {
+	x.columns = columns
+	record := make([]any, 0, len(columns))
+	for _, col := range columns {
+		record = append(record, col.Name)
+	}
+	return x.writeRecord(record)
+}
+
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	record := make([]any, 0, len(values))
+	for i, v := range values {
+		record = append(record, x.cell(i, v))
+	}
+	return x.writeRecord(record)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
+
+func (x *xlsxWriter) Abort() {
+	x.file.Close()
+}
+
+func (x *xlsxWriter) writeRecord(record []any) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, record)
+}
+
+func (x *xlsxWriter) cell(i int, v any) any {
+	if t, ok := v.(time.Time); ok && t.IsZero() {
+		return nil
+	}
+	if v == nil || i >= len(x.columns) {
+		return v
+	}
+	switch x.columns[i].Type {
+	case Month:
+		return excelize.Cell{StyleID: x.monthStyle, Value: v}
+	case Number:
+		return excelize.Cell{StyleID: x.numStyle, Value: v}
+	}
+	return v
+}
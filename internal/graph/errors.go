@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"errors"
+	"main/internal/subscription"
+)
+
+const (
+	CodeBadRequest      = "bad_request"
+	CodeValidation      = "validation_error"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeInternal        = "internal_error"
+	CodeQueryTooDeep    = "query_too_deep"
+	CodeQueryTooComplex = "query_too_complex"
+)
+
+// Error is returned by resolvers; its code is sent in the "extensions"
+// member of the GraphQL error.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+func badRequest(message string) error {
+	return &Error{Code: CodeBadRequest, Message: message}
+}
+
+// fail maps the service error kinds to error codes the same way the REST
+// handlers map them to HTTP statuses, and hides the text of unexpected
+// errors, which may contain database details.
+func (g *Graph) fail(err error) error {
+	switch {
+	case errors.Is(err, subscription.ErrValidation), errors.Is(err, subscription.ErrInvalidQuery):
+		return &Error{Code: CodeValidation, Message: err.Error()}
+	case errors.Is(err, subscription.ErrNotFound):
+		return &Error{Code: CodeNotFound, Message: "sub not found"}
+	case errors.Is(err, subscription.ErrOverlap):
+		return &Error{Code: CodeConflict, Message: err.Error()}
+	default:
+		g.logger.Errorln(err)
+		return &Error{Code: CodeInternal, Message: "internal error"}
+	}
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/subscription"
+	"main/pkg/logger"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+var ErrRejected = errors.New("graphql request rejected")
+
+type Graph struct {
+	schema        graphql.Schema
+	subService    subscription.SubscriptionInterface
+	logger        *logger.Logger
+	maxDepth      int
+	maxComplexity int
+}
+
+func NewGraph(s subscription.SubscriptionInterface, cfg *config.Config, logger *logger.Logger) (*Graph, error) {
+	g := &Graph{
+		subService:    s,
+		logger:        logger,
+		maxDepth:      cfg.GraphQL.MaxDepth,
+		maxComplexity: cfg.GraphQL.MaxComplexity,
+	}
+	schema, err := g.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	g.schema = schema
+	return g, nil
+}
+
+// Execute runs one GraphQL request. Requests that do not parse, fail
+// validation or exceed the depth and complexity limits are not executed:
+// the result only carries the errors and err is ErrRejected.
+func (g *Graph) Execute(ctx context.Context, req model.GraphQLRequest) (result *graphql.Result, err error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return rejected(gqlerrors.FormatErrors(err)), ErrRejected
+	}
+	validation := graphql.ValidateDocument(&g.schema, doc, nil)
+	if !validation.IsValid {
+		return rejected(validation.Errors), ErrRejected
+	}
+	if err = g.checkLimits(doc, req.OperationName, req.Variables); err != nil {
+		return rejected(gqlerrors.FormatErrors(err)), ErrRejected
+	}
+	result = graphql.Execute(graphql.ExecuteParams{
+		Schema:        g.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, g.subService),
+	})
+	return result, nil
+}
+
+func rejected(errs []gqlerrors.FormattedError) *graphql.Result {
+	return &graphql.Result{Errors: errs}
+}
@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is the assumed length of lists that have no limit or ids
+// argument, like the subscriptions of a user.
+const defaultListSize = 10
+
+// defaultLimits are the limits the service applies when a limit argument
+// is omitted.
+var defaultLimits = map[string]int{
+	"Query.subscriptions":     50,
+	"Reports.popularServices": 10,
+}
+
+// queryFieldCost is the cost of fields that run their own storage query
+// for every parent object instead of going through a loader.
+const queryFieldCost = 10
+
+var expensiveFields = map[string]bool{
+	"Query.cost":              true,
+	"User.cost":               true,
+	"User.spendReport":        true,
+	"Reports.spend":           true,
+	"Reports.popularServices": true,
+	"Reports.priceStats":      true,
+	"Reports.churn":           true,
+	"Reports.lifetime":        true,
+}
+
+type analysis struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// checkLimits rejects an operation that nests fields deeper than the max
+// depth or whose complexity exceeds the max complexity. Every field costs 1
+// and the fields of list items count once per expected item. Introspection
+// fields are not counted. The document must be validated first, validation
+// rejects fragment cycles.
+func (g *Graph) checkLimits(doc *ast.Document, operationName string, variables map[string]any) error {
+	a := analysis{
+		schema:    &g.schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return nil
+	}
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		root = g.schema.QueryType()
+	case ast.OperationTypeMutation:
+		root = g.schema.MutationType()
+	default:
+		return nil
+	}
+
+	complexity, depth := a.selectionSet(root, op.SelectionSet, 0, 0)
+	if depth > g.maxDepth {
+		return &Error{Code: CodeQueryTooDeep, Message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, g.maxDepth)}
+	}
+	if complexity > g.maxComplexity {
+		return &Error{Code: CodeQueryTooComplex, Message: fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, g.maxComplexity)}
+	}
+	return nil
+}
+
+// selectionSet returns the complexity and the depth of a selection set on
+// the parent type. A limit set by a field that is not a list itself, like
+// the subscription page, applies to the lists below it.
+func (a *analysis) selectionSet(parent graphql.Type, set *ast.SelectionSet, depth int, limit int) (complexity int, maxDepth int) {
+	if set == nil {
+		return 0, depth
+	}
+	maxDepth = depth
+	for _, selection := range set.Selections {
+		var c, d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c, d = a.field(parent, selection, depth+1, limit)
+		case *ast.InlineFragment:
+			c, d = a.selectionSet(a.typeCondition(parent, selection.TypeCondition), selection.SelectionSet, depth, limit)
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			c, d = a.selectionSet(a.typeCondition(parent, fragment.TypeCondition), fragment.SelectionSet, depth, limit)
+		}
+		complexity += c
+		maxDepth = max(maxDepth, d)
+	}
+	return complexity, maxDepth
+}
+
+func (a *analysis) field(parent graphql.Type, f *ast.Field, depth int, limit int) (complexity int, maxDepth int) {
+	name := f.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return 0, depth - 1
+	}
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return 1, depth
+	}
+	def, ok := object.Fields()[name]
+	if !ok {
+		return 1, depth
+	}
+	complexity = 1
+	if expensiveFields[object.Name()+"."+name] {
+		complexity = queryFieldCost
+	}
+	if f.SelectionSet == nil {
+		return complexity, depth
+	}
+
+	items := 1
+	if n, ok := a.intArgument(f, "limit"); ok {
+		limit = n
+	} else if n, ok := defaultLimits[object.Name()+"."+name]; ok {
+		limit = n
+	}
+	if _, isList := graphql.GetNullable(def.Type).(*graphql.List); isList {
+		switch n, ok := a.listArgument(f, "ids"); {
+		case ok:
+			items = n
+		case limit > 0:
+			items = limit
+		default:
+			items = defaultListSize
+		}
+		limit = 0
+	}
+	child, _ := graphql.GetNamed(def.Type).(graphql.Type)
+	childComplexity, maxDepth := a.selectionSet(child, f.SelectionSet, depth, limit)
+	return complexity + items*childComplexity, maxDepth
+}
+
+func (a *analysis) typeCondition(parent graphql.Type, condition *ast.Named) graphql.Type {
+	if condition == nil {
+		return parent
+	}
+	if t := a.schema.Type(condition.Name.Value); t != nil {
+		return t
+	}
+	return parent
+}
+
+func (a *analysis) intArgument(f *ast.Field, name string) (n int, ok bool) {
+	switch value := a.argument(f, name).(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	case string:
+		n, err := strconv.Atoi(value)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func (a *analysis) listArgument(f *ast.Field, name string) (n int, ok bool) {
+	value, ok := a.argument(f, name).([]any)
+	return len(value), ok
+}
+
+// argument returns the literal or variable value of a field argument.
+func (a *analysis) argument(f *ast.Field, name string) any {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.Variable:
+			return a.variables[value.Name.Value]
+		case *ast.IntValue:
+			return value.Value
+		case *ast.ListValue:
+			items := make([]any, len(value.Values))
+			return items
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"main/internal/model"
+	"main/internal/subscription"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type loadersKey struct{}
+
+// loaders batch the storage reads of one request. Resolvers register keys
+// and return thunks; the executor resolves every field of a level before it
+// calls the thunks, so the first thunk loads the keys of the whole level in
+// one query.
+type loaders struct {
+	subsById   *loader[int, model.Subscription]
+	subsByUser *loader[uuid.UUID, []model.Subscription]
+}
+
+func withLoaders(ctx context.Context, s subscription.SubscriptionInterface) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		subsById: newLoader(func(ctx context.Context, ids []int) (map[int]model.Subscription, error) {
+			subs, err := s.LoadListByIds(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			found := make(map[int]model.Subscription, len(subs))
+			for _, sub := range subs {
+				found[sub.Id] = sub
+			}
+			return found, nil
+		}),
+		subsByUser: newLoader(func(ctx context.Context, userIds []uuid.UUID) (map[uuid.UUID][]model.Subscription, error) {
+			subs, err := s.LoadListByUsers(ctx, userIds)
+			if err != nil {
+				return nil, err
+			}
+			found := make(map[uuid.UUID][]model.Subscription, len(userIds))
+			for _, sub := range subs {
+				found[sub.UserId] = append(found[sub.UserId], sub)
+			}
+			return found, nil
+		}),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+type loaded[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+type loader[K comparable, V any] struct {
+	fetch   func(ctx context.Context, keys []K) (map[K]V, error)
+	mu      sync.Mutex
+	pending []K
+	results map[K]loaded[V]
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		results: make(map[K]loaded[V]),
+	}
+}
+
+// Load queues the key and returns a thunk for its value. Keys that were
+// loaded before are served from the results of the earlier batch.
+func (l *loader[K, V]) Load(ctx context.Context, key K) func() (value V, found bool, err error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.pending = append(l.pending, key)
+		l.results[key] = loaded[V]{}
+	}
+	l.mu.Unlock()
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.pending) > 0 {
+			l.dispatch(ctx)
+		}
+		r := l.results[key]
+		return r.value, r.found, r.err
+	}
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		value, found := values[key]
+		l.results[key] = loaded[V]{value: value, found: found, err: err}
+	}
+}
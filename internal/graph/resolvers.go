@@ -0,0 +1,271 @@
+package graph
+
+import (
+	"main/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+func (g *Graph) subscription(p graphql.ResolveParams) (any, error) {
+	id := intArg(p, "id")
+	if id <= 0 {
+		return nil, nil
+	}
+	load := loadersFrom(p.Context).subsById.Load(p.Context, id)
+	return func() (any, error) {
+		sub, found, err := load()
+		if err != nil {
+			return nil, g.fail(err)
+		}
+		if !found {
+			return nil, nil
+		}
+		return sub, nil
+	}, nil
+}
+
+func (g *Graph) subscriptions(p graphql.ResolveParams) (any, error) {
+	filter := model.ListFilter{
+		ServiceName: stringArg(p, "serviceName"),
+		Limit:       intArg(p, "limit"),
+		Offset:      intArg(p, "offset"),
+	}
+	if stringArg(p, "userId") != "" {
+		userId, err := userIdArg(p, "userId")
+		if err != nil {
+			return nil, err
+		}
+		filter.UserId = userId
+	}
+	page, err := g.subService.LoadPage(p.Context, filter)
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return page, nil
+}
+
+func (g *Graph) user(p graphql.ResolveParams) (any, error) {
+	userId, err := userIdArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+	return user{id: userId}, nil
+}
+
+func (g *Graph) users(p graphql.ResolveParams) (any, error) {
+	ids, _ := p.Args["ids"].([]any)
+	users := make([]user, 0, len(ids))
+	for _, id := range ids {
+		s, _ := id.(string)
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			return nil, badRequest("wrong uuid")
+		}
+		users = append(users, user{id: userId})
+	}
+	return users, nil
+}
+
+func (g *Graph) cost(p graphql.ResolveParams) (any, error) {
+	userId, err := userIdArg(p, "userId")
+	if err != nil {
+		return nil, err
+	}
+	cost, err := g.subService.Cost(p.Context, model.CostRequest{
+		UserId:      userId,
+		ServiceName: stringArg(p, "serviceName"),
+		StartDate:   stringArg(p, "start"),
+		EndDate:     stringArg(p, "end"),
+	})
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return cost, nil
+}
+
+func (g *Graph) userSubscriptions(p graphql.ResolveParams) (any, error) {
+	u := p.Source.(user)
+	serviceName := stringArg(p, "serviceName")
+	load := loadersFrom(p.Context).subsByUser.Load(p.Context, u.id)
+	return func() (any, error) {
+		subs, _, err := load()
+		if err != nil {
+			return nil, g.fail(err)
+		}
+		if serviceName == "" {
+			return subs, nil
+		}
+		filtered := make([]model.Subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.ServiceName == serviceName {
+				filtered = append(filtered, sub)
+			}
+		}
+		return filtered, nil
+	}, nil
+}
+
+func (g *Graph) userCost(p graphql.ResolveParams) (any, error) {
+	cost, err := g.subService.Cost(p.Context, model.CostRequest{
+		UserId:      p.Source.(user).id,
+		ServiceName: stringArg(p, "serviceName"),
+		StartDate:   stringArg(p, "start"),
+		EndDate:     stringArg(p, "end"),
+	})
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return cost, nil
+}
+
+func (g *Graph) userSpendReport(p graphql.ResolveParams) (any, error) {
+	report, err := g.subService.SpendReport(p.Context, model.SpendReportRequest{
+		UserId:    p.Source.(user).id,
+		StartDate: stringArg(p, "start"),
+		EndDate:   stringArg(p, "end"),
+	})
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return report, nil
+}
+
+func (g *Graph) reportSpend(p graphql.ResolveParams) (any, error) {
+	r := p.Source.(reports)
+	data := model.SpendReportRequest{
+		StartDate: r.start,
+		EndDate:   r.end,
+	}
+	if stringArg(p, "userId") != "" {
+		userId, err := userIdArg(p, "userId")
+		if err != nil {
+			return nil, err
+		}
+		data.UserId = userId
+	}
+	report, err := g.subService.SpendReport(p.Context, data)
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return report, nil
+}
+
+func (g *Graph) reportPopularServices(p graphql.ResolveParams) (any, error) {
+	list, err := g.subService.PopularServices(p.Context, analyticsRequest(p))
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return list, nil
+}
+
+func (g *Graph) reportPriceStats(p graphql.ResolveParams) (any, error) {
+	list, err := g.subService.PriceStats(p.Context, analyticsRequest(p))
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return list, nil
+}
+
+func (g *Graph) reportChurn(p graphql.ResolveParams) (any, error) {
+	list, err := g.subService.Churn(p.Context, analyticsRequest(p))
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return list, nil
+}
+
+func (g *Graph) reportLifetime(p graphql.ResolveParams) (any, error) {
+	lifetime, err := g.subService.Lifetime(p.Context, analyticsRequest(p))
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return lifetime, nil
+}
+
+func (g *Graph) createSubscription(p graphql.ResolveParams) (any, error) {
+	sub, err := subscriptionInput(p)
+	if err != nil {
+		return nil, err
+	}
+	id, _, err := g.subService.Save(p.Context, sub)
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	created, err := g.subService.Load(p.Context, id)
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return created, nil
+}
+
+func (g *Graph) updateSubscription(p graphql.ResolveParams) (any, error) {
+	sub, err := subscriptionInput(p)
+	if err != nil {
+		return nil, err
+	}
+	sub.Id = intArg(p, "id")
+	if _, err = g.subService.Update(p.Context, sub); err != nil {
+		return nil, g.fail(err)
+	}
+	updated, err := g.subService.Load(p.Context, sub.Id)
+	if err != nil {
+		return nil, g.fail(err)
+	}
+	return updated, nil
+}
+
+func (g *Graph) deleteSubscription(p graphql.ResolveParams) (any, error) {
+	if err := g.subService.Delete(p.Context, intArg(p, "id")); err != nil {
+		return nil, g.fail(err)
+	}
+	return true, nil
+}
+
+func analyticsRequest(p graphql.ResolveParams) model.AnalyticsRequest {
+	r := p.Source.(reports)
+	return model.AnalyticsRequest{
+		StartDate: r.start,
+		EndDate:   r.end,
+		OrderBy:   stringArg(p, "orderBy"),
+		Limit:     intArg(p, "limit"),
+	}
+}
+
+func subscriptionInput(p graphql.ResolveParams) (sub model.Subscription, err error) {
+	input, _ := p.Args["input"].(map[string]any)
+	get := func(name string) string {
+		s, _ := input[name].(string)
+		return s
+	}
+	sub = model.Subscription{
+		ServiceName:   get("serviceName"),
+		StartDate:     get("startDate"),
+		EndDate:       get("endDate"),
+		BillingPeriod: get("billingPeriod"),
+		TrialEndDate:  get("trialEndDate"),
+	}
+	sub.Price, _ = input["price"].(int)
+	if sub.UserId, err = uuid.Parse(get("userId")); err != nil {
+		return sub, badRequest("wrong uuid")
+	}
+	return sub, nil
+}
+
+func stringArg(p graphql.ResolveParams, name string) string {
+	s, _ := p.Args[name].(string)
+	return s
+}
+
+func intArg(p graphql.ResolveParams, name string) int {
+	n, _ := p.Args[name].(int)
+	return n
+}
+
+func userIdArg(p graphql.ResolveParams, name string) (userId uuid.UUID, err error) {
+	userId, err = uuid.Parse(stringArg(p, name))
+	if err != nil {
+		return userId, badRequest("wrong uuid")
+	}
+	return userId, nil
+}
@@ -0,0 +1,290 @@
+package graph
+
+import (
+	"main/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+)
+
+type user struct {
+	id uuid.UUID
+}
+
+type reports struct {
+	start string
+	end   string
+}
+
+var (
+	nonNullInt    = graphql.NewNonNull(graphql.Int)
+	nonNullFloat  = graphql.NewNonNull(graphql.Float)
+	nonNullString = graphql.NewNonNull(graphql.String)
+	nonNullID     = graphql.NewNonNull(graphql.ID)
+)
+
+func (g *Graph) newSchema() (graphql.Schema, error) {
+	var userType *graphql.Object
+
+	subscriptionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          field(nonNullInt, func(s model.Subscription) any { return s.Id }),
+				"serviceName": field(nonNullString, func(s model.Subscription) any { return s.ServiceName }),
+				"price":       field(nonNullInt, func(s model.Subscription) any { return s.Price }),
+				"userId":      field(nonNullID, func(s model.Subscription) any { return s.UserId.String() }),
+				"user":        field(graphql.NewNonNull(userType), func(s model.Subscription) any { return user{id: s.UserId} }),
+				"startDate":   field(nonNullString, func(s model.Subscription) any { return s.StartDate }),
+				"endDate":     field(graphql.String, func(s model.Subscription) any { return nullable(s.EndDate) }),
+				"billingPeriod": field(nonNullString, func(s model.Subscription) any {
+					return s.BillingPeriod
+				}),
+				"trialEndDate": field(graphql.String, func(s model.Subscription) any { return nullable(s.TrialEndDate) }),
+			}
+		}),
+	})
+	subscriptionList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(subscriptionType)))
+
+	pageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SubscriptionPage",
+		Fields: graphql.Fields{
+			"items":  field(subscriptionList, func(p model.SubscriptionPage) any { return p.Items }),
+			"limit":  field(nonNullInt, func(p model.SubscriptionPage) any { return p.Limit }),
+			"offset": field(nonNullInt, func(p model.SubscriptionPage) any { return p.Offset }),
+			"total":  field(nonNullInt, func(p model.SubscriptionPage) any { return p.Total }),
+		},
+	})
+
+	serviceSpendType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ServiceSpend",
+		Fields: graphql.Fields{
+			"serviceName": field(nonNullString, func(s model.ServiceSpend) any { return s.ServiceName }),
+			"total":       field(nonNullInt, func(s model.ServiceSpend) any { return s.Total }),
+		},
+	})
+	monthSpendType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MonthSpend",
+		Fields: graphql.Fields{
+			"month": field(nonNullString, func(m model.MonthSpend) any { return m.Month }),
+			"total": field(nonNullInt, func(m model.MonthSpend) any { return m.Total }),
+			"services": field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceSpendType))), func(m model.MonthSpend) any {
+				return m.Services
+			}),
+		},
+	})
+	monthSpendList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(monthSpendType)))
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": field(nonNullID, func(u user) any { return u.id.String() }),
+			"subscriptions": &graphql.Field{
+				Type:        subscriptionList,
+				Description: "Subscriptions of the user ordered by ID, loaded in one query for all users of the request.",
+				Args: graphql.FieldConfigArgument{
+					"serviceName": {Type: graphql.String},
+				},
+				Resolve: g.userSubscriptions,
+			},
+			"cost": &graphql.Field{
+				Type: nonNullInt,
+				Args: graphql.FieldConfigArgument{
+					"serviceName": {Type: nonNullString},
+					"start":       {Type: nonNullString, Description: "MM-YYYY"},
+					"end":         {Type: nonNullString, Description: "MM-YYYY"},
+				},
+				Resolve: g.userCost,
+			},
+			"spendReport": &graphql.Field{
+				Type: monthSpendList,
+				Args: graphql.FieldConfigArgument{
+					"start": {Type: nonNullString, Description: "MM-YYYY"},
+					"end":   {Type: nonNullString, Description: "MM-YYYY"},
+				},
+				Resolve: g.userSpendReport,
+			},
+		},
+	})
+
+	reportsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Reports",
+		Fields: graphql.Fields{
+			"spend": &graphql.Field{
+				Type: monthSpendList,
+				Args: graphql.FieldConfigArgument{
+					"userId": {Type: graphql.ID},
+				},
+				Resolve: g.reportSpend,
+			},
+			"popularServices": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.NewObject(graphql.ObjectConfig{
+					Name: "ServicePopularity",
+					Fields: graphql.Fields{
+						"serviceName": field(nonNullString, func(s model.ServicePopularity) any { return s.ServiceName }),
+						"subscribers": field(nonNullInt, func(s model.ServicePopularity) any { return s.Subscribers }),
+						"revenue":     field(nonNullInt, func(s model.ServicePopularity) any { return s.Revenue }),
+					},
+				})))),
+				Args: graphql.FieldConfigArgument{
+					"orderBy": {Type: graphql.String, Description: "subscribers (default) or revenue"},
+					"limit":   {Type: graphql.Int},
+				},
+				Resolve: g.reportPopularServices,
+			},
+			"priceStats": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.NewObject(graphql.ObjectConfig{
+					Name: "ServicePriceStats",
+					Fields: graphql.Fields{
+						"serviceName":   field(nonNullString, func(s model.ServicePriceStats) any { return s.ServiceName }),
+						"subscriptions": field(nonNullInt, func(s model.ServicePriceStats) any { return s.Subscriptions }),
+						"averagePrice":  field(nonNullFloat, func(s model.ServicePriceStats) any { return s.AveragePrice }),
+						"medianPrice":   field(nonNullFloat, func(s model.ServicePriceStats) any { return s.MedianPrice }),
+					},
+				})))),
+				Resolve: g.reportPriceStats,
+			},
+			"churn": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.NewObject(graphql.ObjectConfig{
+					Name: "MonthChurn",
+					Fields: graphql.Fields{
+						"month":     field(nonNullString, func(m model.MonthChurn) any { return m.Month }),
+						"new":       field(nonNullInt, func(m model.MonthChurn) any { return m.New }),
+						"cancelled": field(nonNullInt, func(m model.MonthChurn) any { return m.Cancelled }),
+					},
+				})))),
+				Resolve: g.reportChurn,
+			},
+			"lifetime": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewObject(graphql.ObjectConfig{
+					Name: "SubscriptionLifetime",
+					Fields: graphql.Fields{
+						"subscriptions": field(nonNullInt, func(l model.SubscriptionLifetime) any { return l.Subscriptions }),
+						"averageMonths": field(nonNullFloat, func(l model.SubscriptionLifetime) any { return l.AverageMonths }),
+					},
+				})),
+				Resolve: g.reportLifetime,
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"subscription": &graphql.Field{
+				Type: subscriptionType,
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: nonNullInt},
+				},
+				Resolve: g.subscription,
+			},
+			"subscriptions": &graphql.Field{
+				Type: graphql.NewNonNull(pageType),
+				Args: graphql.FieldConfigArgument{
+					"userId":      {Type: graphql.ID},
+					"serviceName": {Type: graphql.String},
+					"limit":       {Type: graphql.Int, Description: "1 to 1000, default 50"},
+					"offset":      {Type: graphql.Int},
+				},
+				Resolve: g.subscriptions,
+			},
+			"user": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: nonNullID},
+				},
+				Resolve: g.user,
+			},
+			"users": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType))),
+				Args: graphql.FieldConfigArgument{
+					"ids": {Type: graphql.NewNonNull(graphql.NewList(nonNullID))},
+				},
+				Resolve: g.users,
+			},
+			"cost": &graphql.Field{
+				Type: nonNullInt,
+				Args: graphql.FieldConfigArgument{
+					"userId":      {Type: nonNullID},
+					"serviceName": {Type: nonNullString},
+					"start":       {Type: nonNullString, Description: "MM-YYYY"},
+					"end":         {Type: nonNullString, Description: "MM-YYYY"},
+				},
+				Resolve: g.cost,
+			},
+			"reports": &graphql.Field{
+				Type: graphql.NewNonNull(reportsType),
+				Args: graphql.FieldConfigArgument{
+					"start": {Type: nonNullString, Description: "MM-YYYY"},
+					"end":   {Type: nonNullString, Description: "MM-YYYY"},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return reports{start: stringArg(p, "start"), end: stringArg(p, "end")}, nil
+				},
+			},
+		},
+	})
+
+	subscriptionInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "SubscriptionInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"serviceName":   {Type: nonNullString},
+			"price":         {Type: nonNullInt},
+			"userId":        {Type: nonNullID},
+			"startDate":     {Type: nonNullString, Description: "MM-YYYY"},
+			"endDate":       {Type: graphql.String, Description: "MM-YYYY, empty for an active subscription"},
+			"billingPeriod": {Type: graphql.String, Description: "monthly (default) or yearly"},
+			"trialEndDate":  {Type: graphql.String, Description: "MM-YYYY"},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createSubscription": &graphql.Field{
+				Type: graphql.NewNonNull(subscriptionType),
+				Args: graphql.FieldConfigArgument{
+					"input": {Type: graphql.NewNonNull(subscriptionInput)},
+				},
+				Resolve: g.createSubscription,
+			},
+			"updateSubscription": &graphql.Field{
+				Type: graphql.NewNonNull(subscriptionType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: nonNullInt},
+					"input": {Type: graphql.NewNonNull(subscriptionInput)},
+				},
+				Resolve: g.updateSubscription,
+			},
+			"deleteSubscription": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: nonNullInt},
+				},
+				Resolve: g.deleteSubscription,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
+
+// field resolves a scalar or object field from a source of type T.
+func field[T any](typ graphql.Output, get func(source T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(p.Source.(T)), nil
+		},
+	}
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
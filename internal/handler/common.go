@@ -4,19 +4,23 @@ import (
 	"fmt"
 	"main/docs"
 	"main/internal/config"
+	"main/internal/model"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type RespMsgError struct {
 	Success bool   `json:"success" example:"false"`
 	Message string `json:"message" example:"error text"`
+	Details any    `json:"details,omitempty"`
 }
 
 type RespMsgSuccess struct {
-	Success bool `json:"success" example:"true"`
-	Message any  `json:"message"`
+	Success  bool     `json:"success" example:"true"`
+	Message  any      `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 func initSwagger() {
@@ -35,14 +39,31 @@ func (h *Handler) sendError(c *gin.Context, code int, err error) {
 	})
 }
 
-func (h *Handler) sendSuccess(c *gin.Context, code int, msg any) {
+func (h *Handler) sendErrorDetails(c *gin.Context, code int, err error, details any) {
+	c.AbortWithStatusJSON(code, RespMsgError{
+		Success: false,
+		Message: fmt.Sprint(err),
+		Details: details,
+	})
+}
+
+func (h *Handler) sendSuccess(c *gin.Context, code int, msg any, warnings ...string) {
 	h.logger.Infoln("request completed successfully")
 	c.AbortWithStatusJSON(code, RespMsgSuccess{
-		Success: true,
-		Message: msg,
+		Success:  true,
+		Message:  msg,
+		Warnings: warnings,
 	})
 }
 
+func overlapWarnings(overlaps []model.Subscription) (warnings []string) {
+	for _, o := range overlaps {
+		warnings = append(warnings, fmt.Sprintf("overlaps %s sub with id: %d (%s - %s)",
+			o.ServiceName, o.Id, o.StartDate, o.EndDate))
+	}
+	return warnings
+}
+
 func (h *Handler) getID(c *gin.Context) (id int, err error) {
 	s := c.Params.ByName("id")
 	subId := 0
@@ -53,3 +74,12 @@ func (h *Handler) getID(c *gin.Context) (id int, err error) {
 	}
 	return subId, nil
 }
+
+func (h *Handler) getUserID(c *gin.Context) (userId uuid.UUID, err error) {
+	userId, err = uuid.Parse(c.Params.ByName("id"))
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+		return userId, err
+	}
+	return userId, nil
+}
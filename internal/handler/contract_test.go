@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"main/docs"
+	"main/internal/config"
+	"main/internal/openapi"
+	"main/internal/subscription"
+	"main/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testToken = "contract-test-token"
+
+// undocumentedRoutes serve the docs themselves.
+var undocumentedRoutes = map[string]bool{
+	"/openapi.json": true,
+	"/swagger/*any": true,
+}
+
+// TestMain runs the tests in a scratch directory, as the logger and the
+// configuration work relative to the current one.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "handler-test")
+	if err != nil {
+		panic(err)
+	}
+	code := func() int {
+		defer os.RemoveAll(dir)
+		if err = os.Chdir(dir); err != nil {
+			panic(err)
+		}
+		env := "LEGACY_ROUTES=true\nOPENAPI_VALIDATE=true\nOPENAPI_STRICT=true\nWAREHOUSE_EXPORT_TOKEN=" + testToken + "\nWEBHOOK_TOKEN=" + testToken + "\n"
+		if err = os.WriteFile(".env", []byte(env), 0600); err != nil {
+			panic(err)
+		}
+		logger.NewLogger()
+		config.GetConfig()
+		gin.SetMode(gin.ReleaseMode)
+		return m.Run()
+	}()
+	os.Exit(code)
+}
+
+func newTestRouter(service stubService) *gin.Engine {
+	router := gin.New()
+	NewHandler(router, service, logger.GetLogger()).Register()
+	return router
+}
+
+func loadSpec(t *testing.T) *openapi.Spec {
+	t.Helper()
+	spec, err := openapi.Load([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// probe sends the sample request of the operation, with the placeholder ids
+// replaced by id and the placeholder user ids and months by valid ones, and
+// fails the test unless the response matches the spec.
+func probe(t *testing.T, router http.Handler, spec *openapi.Spec, op *openapi.Operation, id string) (status int, body []byte) {
+	t.Helper()
+	req, err := spec.SampleRequest(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments := strings.Split(req.URL.Path, "/")
+	for i, s := range segments {
+		switch s {
+		case "0":
+			segments[i] = id
+		case "x":
+			segments[i] = stubUser.String()
+		}
+	}
+	req.URL.Path = strings.Join(segments, "/")
+	query := req.URL.Query()
+	for name, value := range map[string]string{"user_id": stubUser.String(), "start": "01-2025", "end": "12-2025"} {
+		if query.Get(name) == "x" {
+			query.Set(name, value)
+		}
+	}
+	req.URL.RawQuery = query.Encode()
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	// Streamed responses are cut off after the timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req.WithContext(ctx))
+
+	body, _ = io.ReadAll(rec.Result().Body)
+	if rec.Header().Get(HeaderSpecViolation) != "" {
+		t.Errorf("%s %s: %d %s", req.Method, req.URL.Path, rec.Code, bytes.TrimSpace(body))
+		return rec.Code, body
+	}
+	if err = op.ValidateResponse(rec.Code, rec.Header(), body); err != nil {
+		t.Errorf("%s %s: %d %v", req.Method, req.URL.Path, rec.Code, err)
+	}
+	return rec.Code, body
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	router := newTestRouter(stubService{})
+	spec := loadSpec(t)
+
+	routes := map[string]bool{}
+	for _, r := range router.Routes() {
+		key := r.Method + " " + openapi.PathTemplate(r.Path)
+		routes[key] = true
+		if _, ok := spec.Operation(r.Method, r.Path); !ok && !undocumentedRoutes[r.Path] {
+			t.Errorf("%s: route is not documented", key)
+		}
+	}
+	for _, key := range spec.Operations() {
+		if !routes[key] {
+			t.Errorf("%s: documented operation has no route", key)
+		}
+	}
+}
+
+func TestOperationsMatchSpec(t *testing.T) {
+	router := newTestRouter(stubService{})
+	spec := loadSpec(t)
+
+	for _, key := range spec.Operations() {
+		t.Run(key, func(t *testing.T) {
+			method, path, _ := strings.Cut(key, " ")
+			op, _ := spec.Operation(method, path)
+			if status, _ := probe(t, router, spec, op, "1"); status >= http.StatusInternalServerError {
+				t.Errorf("got %d", status)
+			}
+		})
+	}
+}
+
+func TestMissingResourcesMatchSpec(t *testing.T) {
+	router := newTestRouter(stubService{err: subscription.ErrNotFound})
+	spec := loadSpec(t)
+
+	for _, key := range spec.Operations() {
+		method, path, _ := strings.Cut(key, " ")
+		op, _ := spec.Operation(method, path)
+		if _, ok := op.Responses["404"]; !ok || !strings.Contains(path, "{") {
+			continue
+		}
+		t.Run(key, func(t *testing.T) {
+			if status, _ := probe(t, router, spec, op, "1"); status != http.StatusNotFound {
+				t.Errorf("got %d, want %d", status, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestStorageErrorsAreHidden(t *testing.T) {
+	router := newTestRouter(stubService{err: errors.New("database error, connection refused")})
+	spec := loadSpec(t)
+
+	for _, key := range []string{"PATCH /subscriptions/{id}", "DELETE /subscriptions/{id}"} {
+		t.Run(key, func(t *testing.T) {
+			method, path, _ := strings.Cut(key, " ")
+			op, _ := spec.Operation(method, path)
+			status, body := probe(t, router, spec, op, "1")
+			if status != http.StatusInternalServerError || bytes.Contains(body, []byte("database")) {
+				t.Errorf("got %d %s, want %d without the error text", status, body, http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+func TestStrictModeRejectsUndocumentedResponses(t *testing.T) {
+	spec := loadSpec(t)
+	h := &Handler{logger: logger.GetLogger()}
+
+	cases := map[string]gin.HandlerFunc{
+		"undocumented status": func(c *gin.Context) {
+			c.JSON(http.StatusTeapot, gin.H{"error": "teapot"})
+		},
+		"undocumented body": func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"data": gin.H{"id": "one"}})
+		},
+	}
+	for name, handle := range cases {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(h.OpenAPIMiddleware(spec, true))
+			router.GET("/api/v1/subscriptions/:id", handle)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/subscriptions/1", nil))
+			if rec.Code != http.StatusInternalServerError || rec.Header().Get(HeaderSpecViolation) == "" {
+				t.Errorf("got %d without %s, want a replaced 500", rec.Code, HeaderSpecViolation)
+			}
+		})
+	}
+}
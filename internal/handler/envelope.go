@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrCodeBadRequest   = "bad_request"
+	ErrCodeValidation   = "validation_error"
+	ErrCodeNotFound     = "not_found"
+	ErrCodeConflict     = "conflict"
+	ErrCodeInternal     = "internal_error"
+	ErrCodeUnauthorized = "unauthorized"
+	ErrCodeUnavailable  = "unavailable"
+)
+
+type Envelope struct {
+	Data  any       `json:"data"`
+	Error *APIError `json:"error" extensions:"x-nullable"`
+	Meta  Meta      `json:"meta"`
+}
+
+type APIError struct {
+	Code    string `json:"code" example:"validation_error"`
+	Message string `json:"message" example:"invalid subscription: service name is required"`
+	Details any    `json:"details,omitempty"`
+}
+
+type Meta struct {
+	RequestId  string      `json:"request_id" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+type Pagination struct {
+	Limit  int `json:"limit" example:"50"`
+	Offset int `json:"offset" example:"0"`
+	Total  int `json:"total" example:"120"`
+}
+
+func (h *Handler) sendData(c *gin.Context, code int, data any, meta Meta) {
+	h.logger.Infoln("request completed successfully")
+	meta.RequestId = c.GetString(requestIdKey)
+	c.AbortWithStatusJSON(code, Envelope{Data: data, Meta: meta})
+}
+
+func (h *Handler) sendAPIError(c *gin.Context, code int, errCode string, err error) {
+	c.AbortWithStatusJSON(code, Envelope{
+		Error: &APIError{Code: errCode, Message: err.Error()},
+		Meta:  Meta{RequestId: c.GetString(requestIdKey)},
+	})
+}
+
+// sendServiceError maps the service error kinds to status codes and hides
+// the text of unexpected errors, which may contain database details.
+func (h *Handler) sendServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, subscription.ErrValidation), errors.Is(err, subscription.ErrInvalidQuery):
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeValidation, err)
+	case errors.Is(err, subscription.ErrNotFound):
+		h.sendAPIError(c, http.StatusNotFound, ErrCodeNotFound, errors.New("sub not found"))
+	case errors.Is(err, subscription.ErrOverlap):
+		h.sendAPIError(c, http.StatusConflict, ErrCodeConflict, err)
+	default:
+		h.logger.Errorln(err)
+		h.sendAPIError(c, http.StatusInternalServerError, ErrCodeInternal, errors.New("internal error"))
+	}
+}
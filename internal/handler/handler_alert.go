@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"main/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PriceIncreases godoc
+//
+//	@Summary		Price increase alerts
+//	@Description	Returns recent price hikes above the configured threshold and subscriptions priced well above other users of the same service
+//	@Tags			Alerts
+//	@Param			since			query	string	false	"First month to include (MM-YYYY), defaults to three months ago"
+//	@Param			user_id			query	string	false	"User ID"
+//	@Param			service_name	query	string	false	"Service name"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.PriceAlerts}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/alerts/price-increases [get]
+func (h *Handler) PriceIncreases(c *gin.Context) {
+	h.logger.Infoln("request to the price increases handler")
+	data := model.PriceAlertRequest{
+		Since:       c.Query("since"),
+		ServiceName: c.Query("service_name"),
+	}
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		data.UserId = userId
+	}
+	ctx := c.Request.Context()
+	alerts, err := h.subService.PriceAlerts(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("price alerts error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, alerts)
+}
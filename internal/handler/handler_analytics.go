@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"main/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PopularServices godoc
+//
+//	@Summary		Most popular services
+//	@Description	Returns services ranked by subscriber count or by revenue within a date range
+//	@Tags			Analytics
+//	@Param			from	query	string	true	"Start month (MM-YYYY)"
+//	@Param			to		query	string	true	"End month (MM-YYYY)"
+//	@Param			by		query	string	false	"Ranking: subscribers (default) or revenue"
+//	@Param			limit	query	int		false	"Max services to return (default 10, max 100)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.ServicePopularity}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/analytics/popular-services [get]
+func (h *Handler) PopularServices(c *gin.Context) {
+	h.logger.Infoln("request to the popular services handler")
+	data, err := h.getAnalyticsRequest(c)
+	if err != nil {
+		return
+	}
+	data.OrderBy = c.Query("by")
+	ctx := c.Request.Context()
+	list, err := h.subService.PopularServices(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("popular services error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, list)
+}
+
+// PriceStats godoc
+//
+//	@Summary		Price statistics per service
+//	@Description	Returns average and median price per service for subscriptions active within a date range
+//	@Tags			Analytics
+//	@Param			from	query	string	true	"Start month (MM-YYYY)"
+//	@Param			to		query	string	true	"End month (MM-YYYY)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.ServicePriceStats}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/analytics/prices [get]
+func (h *Handler) PriceStats(c *gin.Context) {
+	h.logger.Infoln("request to the price stats handler")
+	data, err := h.getAnalyticsRequest(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	list, err := h.subService.PriceStats(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("price stats error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, list)
+}
+
+// Churn godoc
+//
+//	@Summary		New versus cancelled subscriptions
+//	@Description	Returns the number of started and cancelled subscriptions per month
+//	@Tags			Analytics
+//	@Param			from	query	string	true	"Start month (MM-YYYY)"
+//	@Param			to		query	string	true	"End month (MM-YYYY)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.MonthChurn}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/analytics/churn [get]
+func (h *Handler) Churn(c *gin.Context) {
+	h.logger.Infoln("request to the churn handler")
+	data, err := h.getAnalyticsRequest(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	list, err := h.subService.Churn(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("churn error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, list)
+}
+
+// Lifetime godoc
+//
+//	@Summary		Average subscription lifetime
+//	@Description	Returns the average lifetime in months of subscriptions that ended within a date range
+//	@Tags			Analytics
+//	@Param			from	query	string	true	"Start month (MM-YYYY)"
+//	@Param			to		query	string	true	"End month (MM-YYYY)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.SubscriptionLifetime}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/analytics/lifetime [get]
+func (h *Handler) Lifetime(c *gin.Context) {
+	h.logger.Infoln("request to the lifetime handler")
+	data, err := h.getAnalyticsRequest(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	lifetime, err := h.subService.Lifetime(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("lifetime error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, lifetime)
+}
+
+func (h *Handler) getAnalyticsRequest(c *gin.Context) (data model.AnalyticsRequest, err error) {
+	data.StartDate = c.Query("from")
+	data.EndDate = c.Query("to")
+	if data.StartDate == "" || data.EndDate == "" {
+		err = fmt.Errorf("both dates are required")
+		h.sendError(c, http.StatusBadRequest, err)
+		return data, err
+	}
+	if s := c.Query("limit"); s != "" {
+		data.Limit, err = strconv.Atoi(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("incorrect limit"))
+			return data, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batch godoc
+//
+//	@Summary		Create, update and delete subscriptions in one request
+//	@Description	Applies a mixed list of create, update and delete operations and returns a result per operation. With atomic=true (default) all operations run in one transaction and nothing is applied if any of them fails; with atomic=false every operation is applied on its own.
+//	@Tags			Subscription
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch	body		model.BatchRequest	true	"Batch operations"
+//	@Param			atomic	query		bool				false	"Apply all operations or none (default true)"
+//	@Success		200		{object}	handler.RespMsgSuccess{message=[]model.BatchResult}
+//	@Failure		400		{object}	handler.RespMsgError
+//	@Failure		422		{object}	handler.RespMsgError{details=[]model.BatchResult}
+//	@Failure		500		{object}	handler.RespMsgError
+//	@Router			/subscriptions/batch [post]
+func (h *Handler) Batch(c *gin.Context) {
+	h.logger.Infoln("request to the batch handler")
+	atomic := c.Query("atomic") != "false"
+	data := model.BatchRequest{}
+	err := c.ShouldBindBodyWithJSON(&data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	results, err := h.subService.Batch(ctx, data.Operations, atomic)
+	if errors.Is(err, subscription.ErrBatchFailed) {
+		h.sendErrorDetails(c, http.StatusUnprocessableEntity, err, results)
+		return
+	}
+	if errors.Is(err, subscription.ErrValidation) {
+		h.sendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("batch error"))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, results)
+}
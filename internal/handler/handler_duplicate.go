@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Duplicates godoc
+//
+//	@Summary		Overlapping subscriptions
+//	@Description	Returns pairs of subscriptions of a user for the same service with overlapping dates
+//	@Tags			Subscription
+//	@Param			id	path	string	true	"User ID"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.DuplicatePair}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/users/{id}/duplicates [get]
+func (h *Handler) Duplicates(c *gin.Context) {
+	h.logger.Infoln("request to the duplicates handler")
+	userId, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	pairs, err := h.subService.Duplicates(ctx, userId)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("duplicates error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, pairs)
+}
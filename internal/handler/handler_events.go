@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// eventsRetry is the reconnect delay suggested to EventSource clients.
+const eventsRetry = 3 * time.Second
+
+// SubscriptionEvents godoc
+//
+//	@Summary		Stream subscription changes
+//	@Description	Streams every created, updated and deleted subscription as Server-Sent Events, including changes made by other instances or directly in the database. Each event has the change ID as "id", the change type as "event" and the change as JSON "data". Send the last received ID in the Last-Event-ID header to resume; changes older than EVENTS_RETENTION are gone. Comment lines are sent every EVENTS_HEARTBEAT to keep the connection open.
+//	@Tags			Subscription
+//	@Param			user_id			query	string	false	"Only changes of this user"
+//	@Param			Last-Event-ID	header	int		false	"Resume after this change ID"
+//	@Produce		text/event-stream
+//	@Success		200	{object}	model.SubscriptionChange
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/subscriptions/events [get]
+func (h *Handler) SubscriptionEvents(c *gin.Context) {
+	h.logger.Infoln("request to the subscription events handler")
+	filter := model.ChangeFilter{}
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		filter.UserId = userId
+	}
+	if s := c.GetHeader("Last-Event-ID"); s != "" {
+		lastId, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || lastId < 0 {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID, change id required"))
+			return
+		}
+		filter.AfterId = lastId
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	changes := make(chan model.SubscriptionChange)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.subService.WatchChanges(ctx, filter, func(change model.SubscriptionChange) error {
+			select {
+			case changes <- change:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetry.Milliseconds())
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(config.GetConfig().Events.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case change := <-changes:
+			data, err := json.Marshal(change)
+			if err != nil {
+				h.logger.Errorln(err)
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", change.Id, change.Type, data)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		case err := <-done:
+			if err != nil && !errors.Is(err, context.Canceled) {
+				if !errors.Is(err, subscription.ErrChangesLagging) {
+					h.logger.Errorln(err)
+				}
+				fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", "stream interrupted, reconnect with Last-Event-ID")
+				c.Writer.Flush()
+			}
+			c.Abort()
+			return
+		case <-ctx.Done():
+			h.logger.Infoln("subscription events client disconnected")
+			c.Abort()
+			return
+		}
+	}
+}
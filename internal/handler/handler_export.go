@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"main/internal/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) exportFormat(c *gin.Context) (format string) {
+	format = c.Query("format")
+	if format == "" {
+		format = export.FormatFromAccept(c.GetHeader("Accept"))
+	}
+	if format == "json" {
+		return ""
+	}
+	return format
+}
+
+func (h *Handler) sendExport(c *gin.Context, format string, name string, code int, write func(w export.Writer) error) {
+	w, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	err = write(w)
+	if err != nil {
+		w.Abort()
+		if c.Writer.Written() {
+			h.logger.Errorln(fmt.Errorf("export interrupted: %v", err))
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		h.sendError(c, code, fmt.Errorf("export error: %v", err))
+		return
+	}
+	if err = w.Close(); err != nil {
+		h.logger.Errorln(fmt.Errorf("export interrupted: %v", err))
+		c.Abort()
+		return
+	}
+	h.logger.Infoln("request completed successfully")
+	c.Abort()
+}
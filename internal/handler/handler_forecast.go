@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Forecast godoc
+//
+//	@Summary		Spending forecast
+//	@Description	Returns projected month-by-month spend of a user for the next N months
+//	@Tags			Forecast
+//	@Param			id		path	string	true	"User ID"
+//	@Param			months	query	int		false	"Number of months (default 12, max 120)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.Forecast}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/users/{id}/forecast [get]
+func (h *Handler) Forecast(c *gin.Context) {
+	h.logger.Infoln("request to the forecast handler")
+	userId, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+	data := model.ForecastRequest{
+		UserId: userId,
+	}
+	if s := c.Query("months"); s != "" {
+		data.Months, err = strconv.Atoi(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("incorrect months"))
+			return
+		}
+	}
+	ctx := c.Request.Context()
+	forecast, err := h.subService.Forecast(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("forecast error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, forecast)
+}
+
+// CreateScheduledPrice godoc
+//
+//	@Summary		Schedule price change
+//	@Description	Records a known future price of a subscription, used by reports and forecasts.
+//	@Tags			Forecast
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"Subscription ID"
+//	@Param			price	body		model.ScheduledPriceRequest	true	"Scheduled price data"
+//	@Success		200		{object}	handler.RespMsgSuccess
+//	@Failure		400		{object}	handler.RespMsgError
+//	@Failure		404		{object}	handler.RespMsgError
+//	@Router			/subscriptions/{id}/prices [post]
+func (h *Handler) CreateScheduledPrice(c *gin.Context) {
+	h.logger.Infoln("request to the create scheduled price handler")
+	subId, err := h.getID(c)
+	if err != nil {
+		return
+	}
+	data := model.ScheduledPriceRequest{}
+	err = c.ShouldBindBodyWithJSON(&data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+	ctx := c.Request.Context()
+	id, err := h.subService.SaveScheduledPrice(ctx, subId, data)
+	if errors.Is(err, subscription.ErrNotFound) {
+		h.sendError(c, http.StatusNotFound, fmt.Errorf("scheduling price error, sub not found"))
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("scheduling price error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, fmt.Sprintf("created new scheduled price with id: %d", id))
+}
+
+// ListScheduledPrices godoc
+//
+//	@Summary		Read scheduled prices
+//	@Description	Returns the scheduled price changes of a subscription
+//	@Tags			Forecast
+//	@Produce		json
+//	@Param			id	path		int	true	"Subscription ID"
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.ScheduledPrice}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Failure		404	{object}	handler.RespMsgError
+//	@Router			/subscriptions/{id}/prices [get]
+func (h *Handler) ListScheduledPrices(c *gin.Context) {
+	h.logger.Infoln("request to the list scheduled prices handler")
+	subId, err := h.getID(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	prices, err := h.subService.LoadScheduledPrices(ctx, subId)
+	if errors.Is(err, subscription.ErrNotFound) {
+		h.sendError(c, http.StatusNotFound, fmt.Errorf("scheduled prices error, sub not found"))
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("scheduled prices error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, prices)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"main/internal/config"
+	"main/internal/graph"
+	"main/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) registerGraphQL() {
+	g, err := graph.NewGraph(h.subService, config.GetConfig(), h.logger)
+	if err != nil {
+		h.logger.Fatalln(err)
+	}
+	h.graph = g
+	h.router.POST("/graphql", h.GraphQL)
+}
+
+// GraphQL godoc
+//
+//	@Summary		GraphQL endpoint
+//	@Description	Executes a GraphQL query or mutation on users, subscriptions, costs and reports. Requests that do not parse, fail validation or exceed GRAPHQL_MAX_DEPTH or GRAPHQL_MAX_COMPLEXITY are rejected with 400 and not executed. Field errors are returned with 200 next to the partial data, their "extensions.code" holds the error kind.
+//	@Tags			GraphQL
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.GraphQLRequest	true	"GraphQL request"
+//	@Success		200		{object}	model.GraphQLResponse
+//	@Failure		400		{object}	model.GraphQLResponse
+//	@Router			/graphql [post]
+func (h *Handler) GraphQL(c *gin.Context) {
+	h.logger.Infoln("request to the graphql handler")
+	req := model.GraphQLRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Query == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, model.GraphQLResponse{
+			Errors: []model.GraphQLError{{Message: "reading request body error, query is required"}},
+		})
+		return
+	}
+	result, err := h.graph.Execute(c.Request.Context(), req)
+	if errors.Is(err, graph.ErrRejected) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, result)
+		return
+	}
+	h.logger.Infoln("request completed successfully")
+	c.AbortWithStatusJSON(http.StatusOK, result)
+}
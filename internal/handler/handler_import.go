@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Import godoc
+//
+//	@Summary		Import subscriptions from CSV
+//	@Description	Validates every row of a CSV file and inserts all of them in one transaction. The header row maps columns: service_name, price, user_id, start_date, end_date, billing_period, trial_end_date.
+//	@Tags			Subscription
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"CSV file"
+//	@Param			dry_run	query		bool	false	"Validate only, do not insert"
+//	@Success		200		{object}	handler.RespMsgSuccess{message=model.ImportReport}
+//	@Failure		400		{object}	handler.RespMsgError
+//	@Failure		422		{object}	handler.RespMsgError{details=model.ImportReport}
+//	@Router			/subscriptions/import [post]
+func (h *Handler) Import(c *gin.Context) {
+	h.logger.Infoln("request to the import handler")
+	dryRun := c.Query("dry_run") == "true"
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("csv file is required"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("reading csv file error"))
+		return
+	}
+	defer file.Close()
+
+	ctx := c.Request.Context()
+	report, err := h.subService.Import(ctx, file, dryRun)
+	if errors.Is(err, subscription.ErrInvalidImport) {
+		h.sendErrorDetails(c, http.StatusUnprocessableEntity, err, report)
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("import error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, report)
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"main/docs"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/openapi"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderSpecViolation marks the responses strict mode replaced, so a client
+// can tell them from a regular 500.
+const HeaderSpecViolation = "X-OpenAPI-Violation"
+
+// registerOpenAPI serves the OpenAPI 3.1 document converted from the swagger
+// annotations and validates the requests of the routes registered after it.
+func (h *Handler) registerOpenAPI() {
+	spec, err := openapi.Load([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		h.logger.Fatalln(err)
+	}
+	h.router.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec.JSON())
+	})
+	cfg := config.GetConfig()
+	if cfg.OpenAPI.Validate {
+		h.router.Use(h.OpenAPIMiddleware(spec, cfg.OpenAPI.Strict))
+	}
+}
+
+// OpenAPIMiddleware rejects requests that do not match their operation in
+// the spec before they reach the handler. Invalid path parameters get 404
+// like an unknown id, anything else 400.
+//
+// In strict mode the responses are checked too, and one that does not match
+// is replaced with a 500 naming the difference, so the contract check fails
+// on it. Streamed responses are sent as they are flushed and only have their
+// status checked.
+func (h *Handler) OpenAPIMiddleware(spec *openapi.Spec, strict bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := spec.Operation(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+		params := map[string]string{}
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		if err := op.ValidateRequest(c.Request, params); err != nil {
+			code := http.StatusBadRequest
+			var verr *openapi.ValidationError
+			if errors.As(err, &verr) && verr.Path {
+				code = http.StatusNotFound
+			}
+			h.sendSpecError(c, code, fmt.Errorf("invalid request, %v", err))
+			return
+		}
+		if !strict {
+			c.Next()
+			return
+		}
+
+		w := &specWriter{ResponseWriter: c.Writer, check: func(status int) {
+			if err := op.ValidateResponse(status, nil, nil); err != nil {
+				h.logger.Errorf("%s %s streamed response does not match the API spec: %v", op.Method, op.Path, err)
+			}
+		}}
+		c.Writer = w
+		// A panic leaves the response to the recovery middleware.
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if w.streaming {
+			return
+		}
+		if err := op.ValidateResponse(w.Status(), w.Header(), w.body.Bytes()); err != nil {
+			h.logger.Errorf("%s %s response does not match the API spec: %v", op.Method, op.Path, err)
+			for _, key := range []string{"Content-Disposition", "Content-Length", "Trailer"} {
+				c.Writer.Header().Del(key)
+			}
+			c.Header(HeaderSpecViolation, "response")
+			h.sendSpecError(c, http.StatusInternalServerError, fmt.Errorf("response does not match the API spec, %v", err))
+			return
+		}
+		w.send()
+	}
+}
+
+// sendSpecError answers in the error format of the route.
+func (h *Handler) sendSpecError(c *gin.Context, code int, err error) {
+	switch {
+	case c.FullPath() == "/graphql":
+		c.AbortWithStatusJSON(code, model.GraphQLResponse{Errors: []model.GraphQLError{{Message: err.Error()}}})
+	case strings.HasPrefix(c.FullPath(), "/api/v1/"):
+		errCode := ErrCodeValidation
+		if code == http.StatusNotFound {
+			errCode = ErrCodeNotFound
+		} else if code == http.StatusInternalServerError {
+			errCode = ErrCodeInternal
+		}
+		h.sendAPIError(c, code, errCode, err)
+	default:
+		h.sendError(c, code, err)
+	}
+}
+
+// specWriter holds the response back until it is checked. A flush means the
+// handler streams, so the held part is sent and the rest passes through.
+type specWriter struct {
+	gin.ResponseWriter
+	check     func(status int)
+	status    int
+	written   bool
+	streaming bool
+	body      bytes.Buffer
+}
+
+func (w *specWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *specWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *specWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *specWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *specWriter) Status() int {
+	if w.streaming {
+		return w.ResponseWriter.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *specWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *specWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+func (w *specWriter) Flush() {
+	if !w.streaming {
+		w.check(w.Status())
+		w.send()
+		w.streaming = true
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *specWriter) send() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
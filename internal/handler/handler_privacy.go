@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportUserData godoc
+//
+//	@Summary		Export all data of a user
+//	@Description	Returns a ZIP archive with the user's subscriptions, scheduled prices, event history, spend report and forecast as JSON and CSV files.
+//	@Tags			Privacy
+//	@Param			id	path	string	true	"User ID"
+//	@Produce		application/zip
+//	@Success		200	{file}		file
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Failure		500	{object}	handler.RespMsgError
+//	@Router			/users/{id}/export [get]
+func (h *Handler) ExportUserData(c *gin.Context) {
+	h.logger.Infoln("request to the user data export handler")
+	userId, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	var buf bytes.Buffer
+	err = h.subService.ExportUserData(ctx, userId, &buf)
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("user data export error"))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s.zip"`, userId))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	h.logger.Infoln("request completed successfully")
+	c.Abort()
+}
+
+// EraseUserData godoc
+//
+//	@Summary		Erase all data of a user
+//	@Description	Deletes every row tied to the user, keeps a tombstone with a keyed hash of the user ID and returns a receipt. The signature is HMAC-SHA256, keyed with PRIVACY_SIGNING_KEY, over the receipt JSON without the signature field.
+//	@Tags			Privacy
+//	@Param			id	path	string	true	"User ID"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.ErasureReceipt}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Failure		500	{object}	handler.RespMsgError
+//	@Failure		503	{object}	handler.RespMsgError
+//	@Router			/users/{id}/data [delete]
+func (h *Handler) EraseUserData(c *gin.Context) {
+	h.logger.Infoln("request to the user data erase handler")
+	userId, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+	ctx := c.Request.Context()
+	receipt, err := h.subService.EraseUserData(ctx, userId)
+	if errors.Is(err, subscription.ErrNoSigningKey) {
+		h.sendError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("user data erase error"))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, receipt)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"main/internal/export"
+	"main/internal/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SpendReport godoc
+//
+//	@Summary		Monthly spending report
+//	@Description	Returns one row per month with total spend and a per-service breakdown
+//	@Tags			Report
+//	@Param			from		query	string	true	"Start month (MM-YYYY)"
+//	@Param			to			query	string	true	"End month (MM-YYYY)"
+//	@Param			user_id		query	string	false	"User ID"
+//	@Param			group_by	query	string	false	"Set to user for per-user rows"
+//	@Param			format		query	string	false	"Response format: json (default), csv or xlsx"
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.MonthSpend}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Router			/reports/spend [get]
+func (h *Handler) SpendReport(c *gin.Context) {
+	h.logger.Infoln("request to the spend report handler")
+	from := c.Query("from")
+	to := c.Query("to")
+	groupBy := c.Query("group_by")
+
+	if from == "" || to == "" {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("both dates are required"))
+		return
+	}
+
+	if groupBy != "" && groupBy != "user" {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("unsupported group_by value"))
+		return
+	}
+
+	data := model.SpendReportRequest{
+		StartDate:   from,
+		EndDate:     to,
+		GroupByUser: groupBy == "user",
+	}
+
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		data.UserId = userId
+	}
+
+	ctx := c.Request.Context()
+	if format := h.exportFormat(c); format != "" {
+		h.sendExport(c, format, "spend_report", http.StatusBadRequest, func(w export.Writer) error {
+			return h.subService.ExportSpendReport(ctx, data, w)
+		})
+		return
+	}
+	report, err := h.subService.SpendReport(ctx, data)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("spend report error: %v", err))
+		return
+	}
+
+	h.sendSuccess(c, http.StatusOK, report)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Search godoc
+//
+//	@Summary		Search subscriptions by service name
+//	@Description	Finds subscriptions whose service name is like the query, ignoring case, best match first. The fuzzy mode tolerates typos and matches single words of longer names, so "kinopoisk" finds "KinoPoisk HD". The prefix mode matches names starting with the query, for autocomplete.
+//	@Tags			Subscription
+//	@Param			q		query	string	true	"Service name to look for, up to 100 characters"
+//	@Param			user_id	query	string	false	"User ID"
+//	@Param			mode	query	string	false	"fuzzy (default) or prefix"	Enums(fuzzy, prefix)
+//	@Param			limit	query	int		false	"Max results, 1 to 100 (default 20)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=[]model.SearchResult}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Failure		500	{object}	handler.RespMsgError
+//	@Router			/subscriptions/search [get]
+func (h *Handler) Search(c *gin.Context) {
+	h.logger.Infoln("request to the search handler")
+	data := model.SearchRequest{
+		Query: c.Query("q"),
+		Mode:  c.Query("mode"),
+	}
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		data.UserId = userId
+	}
+	var err error
+	if data.Limit, err = queryInt(c, "limit"); err != nil {
+		h.sendError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	results, err := h.subService.Search(ctx, data)
+	if errors.Is(err, subscription.ErrInvalidQuery) {
+		h.sendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("search error"))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, results)
+}
@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"main/internal/config"
+	"main/internal/export"
+	"main/internal/graph"
 	"main/internal/model"
 	"main/internal/subscription"
 	"main/pkg/logger"
@@ -16,6 +20,7 @@ import (
 type Handler struct {
 	router     *gin.Engine
 	subService subscription.SubscriptionInterface
+	graph      *graph.Graph
 	logger     *logger.Logger
 }
 
@@ -29,13 +34,37 @@ func NewHandler(r *gin.Engine, s subscription.SubscriptionInterface, logger *log
 }
 
 func (h *Handler) Register() {
-	h.router.Use(CORSMiddleware())
-	h.router.POST("/subscriptions", h.Create)
-	h.router.GET("/subscriptions/:id", h.Read)
-	h.router.PATCH("/subscriptions/:id", h.Update)
-	h.router.DELETE("/subscriptions/:id", h.Delete)
-	h.router.GET("/subscriptions", h.List)
-	h.router.GET("/subscriptions/cost", h.Cost)
+	h.router.Use(CORSMiddleware(), RequestIDMiddleware())
+	h.registerOpenAPI()
+	h.registerV1()
+	h.registerWebhooks()
+	if config.GetConfig().API.LegacyRoutes {
+		h.router.POST("/subscriptions", h.Create)
+		h.router.GET("/subscriptions/:id", h.Read)
+		h.router.PATCH("/subscriptions/:id", h.Update)
+		h.router.DELETE("/subscriptions/:id", h.Delete)
+		h.router.GET("/subscriptions", h.List)
+		h.router.GET("/subscriptions/cost", h.Cost)
+	}
+	h.router.GET("/subscriptions/events", h.SubscriptionEvents)
+	h.router.GET("/subscriptions/search", h.Search)
+	h.router.POST("/subscriptions/import", h.Import)
+	h.router.POST("/subscriptions/batch", h.Batch)
+	h.router.GET("/reports/spend", h.SpendReport)
+	h.router.GET("/analytics/popular-services", h.PopularServices)
+	h.router.GET("/analytics/prices", h.PriceStats)
+	h.router.GET("/analytics/churn", h.Churn)
+	h.router.GET("/analytics/lifetime", h.Lifetime)
+	h.router.GET("/users/:id/forecast", h.Forecast)
+	h.router.GET("/users/:id/duplicates", h.Duplicates)
+	h.router.GET("/users/:id/export", h.ExportUserData)
+	h.router.DELETE("/users/:id/data", h.EraseUserData)
+	h.router.POST("/users/:id/statements/analyze", h.DetectCharges)
+	h.router.POST("/subscriptions/:id/prices", h.CreateScheduledPrice)
+	h.router.GET("/subscriptions/:id/prices", h.ListScheduledPrices)
+	h.router.GET("/alerts/price-increases", h.PriceIncreases)
+	h.router.POST("/exports/parquet", TokenAuthMiddleware(config.GetConfig().Warehouse.Token), h.ExportWarehouse)
+	h.registerGraphQL()
 	h.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
 
@@ -49,7 +78,7 @@ func (h *Handler) Register() {
 //	@Param			subscription	body		model.SubRequest	true	"Subscription create data"
 //	@Success		200				{object}	handler.RespMsgSuccess
 //	@Failure		400				{object}	handler.RespMsgError
-//	@Failure		401				{object}	handler.RespMsgError
+//	@Failure		409				{object}	handler.RespMsgError
 //	@Router			/subscriptions [post]
 func (h *Handler) Create(c *gin.Context) {
 	h.logger.Infoln("request to the create handler")
@@ -60,12 +89,16 @@ func (h *Handler) Create(c *gin.Context) {
 		h.sendError(c, http.StatusBadRequest, fmt.Errorf("reading request body error"))
 		return
 	}
-	id, err := h.subService.Save(ctx, sub)
+	id, overlaps, err := h.subService.Save(ctx, sub)
+	if errors.Is(err, subscription.ErrOverlap) {
+		h.sendError(c, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
-		h.sendError(c, http.StatusBadRequest, fmt.Errorf("creating sub error"))
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("creating sub error: %v", err))
 		return
 	}
-	h.sendSuccess(c, http.StatusOK, fmt.Sprintf("created new sub with id: %d", id))
+	h.sendSuccess(c, http.StatusOK, fmt.Sprintf("created new sub with id: %d", id), overlapWarnings(overlaps)...)
 }
 
 // Read godoc
@@ -75,9 +108,8 @@ func (h *Handler) Create(c *gin.Context) {
 //	@Tags			Subscription
 //	@Produce		json
 //	@Param			id	path		int	true	"Subscription ID"
-//	@Success		200	{object}	handler.RespMsgSuccess
-//	@Failure		400	{object}	handler.RespMsgError
-//	@Failure		401	{object}	handler.RespMsgError
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.Subscription}
+//	@Failure		404	{object}	handler.RespMsgError
 //	@Router			/subscriptions/{id} [get]
 func (h *Handler) Read(c *gin.Context) {
 	h.logger.Infoln("request to the read handler")
@@ -105,7 +137,9 @@ func (h *Handler) Read(c *gin.Context) {
 //	@Param			subscription	body		model.SubRequest	true	"Subscription update data"
 //	@Success		200				{object}	handler.RespMsgSuccess
 //	@Failure		400				{object}	handler.RespMsgError
-//	@Failure		401				{object}	handler.RespMsgError
+//	@Failure		404				{object}	handler.RespMsgError
+//	@Failure		409				{object}	handler.RespMsgError
+//	@Failure		500				{object}	handler.RespMsgError
 //	@Router			/subscriptions/{id} [patch]
 func (h *Handler) Update(c *gin.Context) {
 	h.logger.Infoln("request to the update handler")
@@ -121,12 +155,25 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 	sub.Id = subId
-	err = h.subService.Update(ctx, sub)
+	overlaps, err := h.subService.Update(ctx, sub)
+	if errors.Is(err, subscription.ErrOverlap) {
+		h.sendError(c, http.StatusConflict, err)
+		return
+	}
+	if errors.Is(err, subscription.ErrNotFound) {
+		h.sendError(c, http.StatusNotFound, fmt.Errorf("update failed, sub not found"))
+		return
+	}
+	if errors.Is(err, subscription.ErrValidation) {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("update failed: %v", err))
+		return
+	}
 	if err != nil {
-		h.sendError(c, http.StatusBadRequest, fmt.Errorf("update failed, sub not found"))
+		h.logger.Errorln(err)
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("update failed"))
 		return
 	}
-	h.sendSuccess(c, http.StatusOK, "sub updated")
+	h.sendSuccess(c, http.StatusOK, "sub updated", overlapWarnings(overlaps)...)
 
 }
 
@@ -139,7 +186,8 @@ func (h *Handler) Update(c *gin.Context) {
 //	@Param			id	path		int	true	"Subscription ID"
 //	@Success		200	{object}	handler.RespMsgSuccess
 //	@Failure		400	{object}	handler.RespMsgError
-//	@Failure		401	{object}	handler.RespMsgError
+//	@Failure		404	{object}	handler.RespMsgError
+//	@Failure		500	{object}	handler.RespMsgError
 //	@Router			/subscriptions/{id} [delete]
 func (h *Handler) Delete(c *gin.Context) {
 	h.logger.Infoln("request to the delete handler")
@@ -149,8 +197,13 @@ func (h *Handler) Delete(c *gin.Context) {
 	}
 	ctx := c.Request.Context()
 	err = h.subService.Delete(ctx, subId)
+	if errors.Is(err, subscription.ErrNotFound) {
+		h.sendError(c, http.StatusNotFound, fmt.Errorf("delete failed, sub not found"))
+		return
+	}
 	if err != nil {
-		h.sendError(c, http.StatusBadRequest, fmt.Errorf("delete failed, sub not found"))
+		h.logger.Errorln(err)
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("delete failed"))
 		return
 	}
 	h.sendSuccess(c, http.StatusOK, "sub deleted")
@@ -159,17 +212,47 @@ func (h *Handler) Delete(c *gin.Context) {
 // List godoc
 //
 //	@Summary		Read subscription list
-//	@Description	Returns a list of subscription objects
+//	@Description	Returns a list of subscription objects. Use format or the Accept header to download it as CSV or XLSX, or to stream it as NDJSON (one subscription per line, X-Stream-Count and X-Stream-Error trailers).
 //	@Tags			Subscription
+//	@Param			user_id			query	string	false	"User ID"
+//	@Param			service_name	query	string	false	"Service name"
+//	@Param			format			query	string	false	"Response format: json (default), csv, xlsx or ndjson"
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Produce		application/x-ndjson
 //	@Success		200	{object}	handler.RespMsgSuccess
 //	@Failure		400	{object}	handler.RespMsgError
-//	@Failure		401	{object}	handler.RespMsgError
 //	@Router			/subscriptions [get]
 func (h *Handler) List(c *gin.Context) {
 	h.logger.Infoln("request to the list handler")
+	filter := model.ListFilter{
+		ServiceName: c.Query("service_name"),
+	}
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendError(c, http.StatusBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		filter.UserId = userId
+	}
 	ctx := c.Request.Context()
-	subList, err := h.subService.LoadList(ctx)
+	if h.wantsNDJSON(c) {
+		h.sendStream(c, http.StatusBadRequest, func(write func(v any) error) error {
+			return h.subService.StreamList(ctx, filter, func(sub model.Subscription) error {
+				return write(sub)
+			})
+		})
+		return
+	}
+	if format := h.exportFormat(c); format != "" {
+		h.sendExport(c, format, "subscriptions", http.StatusBadRequest, func(w export.Writer) error {
+			return h.subService.ExportList(ctx, filter, w)
+		})
+		return
+	}
+	subList, err := h.subService.LoadList(ctx, filter)
 	if err != nil {
 		h.sendError(c, http.StatusBadRequest, fmt.Errorf("sub list is empty"))
 		return
@@ -186,10 +269,13 @@ func (h *Handler) List(c *gin.Context) {
 //	@Param			service_name	query	string	true	"Service name"
 //	@Param			start			query	string	true	"Start date"
 //	@Param			end				query	string	true	"End date"
+//	@Param			format			query	string	false	"Response format: json (default), csv or xlsx"
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 //	@Success		200	{object}	handler.RespMsgSuccess
 //	@Failure		400	{object}	handler.RespMsgError
-//	@Failure		401	{object}	handler.RespMsgError
+//	@Failure		404	{object}	handler.RespMsgError
 //	@Router			/subscriptions/cost [get]
 func (h *Handler) Cost(c *gin.Context) {
 	h.logger.Infoln("request to the cost handler")
@@ -223,6 +309,13 @@ func (h *Handler) Cost(c *gin.Context) {
 		EndDate:     end,
 	}
 
+	if format := h.exportFormat(c); format != "" {
+		h.sendExport(c, format, "cost", http.StatusNotFound, func(w export.Writer) error {
+			return h.subService.ExportCost(ctx, data, w)
+		})
+		return
+	}
+
 	cost, err := h.subService.Cost(ctx, data)
 
 	if err != nil {
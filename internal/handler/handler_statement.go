@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DetectCharges godoc
+//
+//	@Summary		Detect recurring charges
+//	@Description	Reads an OFX or bank CSV statement and proposes candidate subscriptions for recurring charges. A candidate is accepted by posting its subscription object to /subscriptions.
+//	@Tags			Statement
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		string	true	"User ID"
+//	@Param			file	formData	file	true	"OFX or CSV statement"
+//	@Success		200		{object}	handler.RespMsgSuccess{message=[]model.ChargeCandidate}
+//	@Failure		400		{object}	handler.RespMsgError
+//	@Router			/users/{id}/statements/analyze [post]
+func (h *Handler) DetectCharges(c *gin.Context) {
+	h.logger.Infoln("request to the detect charges handler")
+	userId, err := h.getUserID(c)
+	if err != nil {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("statement file is required"))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("reading statement file error"))
+		return
+	}
+	defer file.Close()
+
+	ctx := c.Request.Context()
+	candidates, err := h.subService.DetectCharges(ctx, file, fileHeader.Filename, userId)
+	if err != nil {
+		h.sendError(c, http.StatusBadRequest, fmt.Errorf("statement error: %v", err))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, candidates)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contentTypeNDJSON = "application/x-ndjson"
+	streamFlushRows   = 100
+)
+
+func (h *Handler) wantsNDJSON(c *gin.Context) bool {
+	if c.Query("format") == "ndjson" {
+		return true
+	}
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		if strings.TrimSpace(strings.Split(part, ";")[0]) == contentTypeNDJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// sendStream writes one JSON document per line as the rows are produced.
+// Errors before the first row get a regular error response. After that the
+// status is already sent, so the stream ends with a RespMsgError line and the
+// X-Stream-Error trailer; a complete stream reports X-Stream-Count instead.
+func (h *Handler) sendStream(c *gin.Context, code int, stream func(write func(v any) error) error) {
+	c.Header("Content-Type", contentTypeNDJSON)
+	c.Header("Trailer", "X-Stream-Count, X-Stream-Error")
+	enc := json.NewEncoder(c.Writer)
+	count := 0
+	err := stream(func(v any) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		count++
+		if count%streamFlushRows == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Trailer")
+		h.sendError(c, code, fmt.Errorf("stream error: %v", err))
+		return
+	}
+	if err != nil {
+		h.logger.Errorln(fmt.Errorf("stream interrupted: %v", err))
+		enc.Encode(RespMsgError{Success: false, Message: fmt.Sprintf("stream interrupted after %d rows", count)})
+		c.Writer.Header().Set("X-Stream-Error", "stream interrupted")
+		c.Abort()
+		return
+	}
+	c.Writer.WriteHeaderNow()
+	c.Writer.Header().Set("X-Stream-Count", strconv.Itoa(count))
+	h.logger.Infoln("request completed successfully")
+	c.Abort()
+}
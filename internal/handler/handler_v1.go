@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) registerV1() {
+	v1 := h.router.Group("/api/v1")
+	v1.POST("/subscriptions", h.CreateV1)
+	v1.GET("/subscriptions", h.ListV1)
+	v1.GET("/subscriptions/cost", h.CostV1)
+	v1.GET("/subscriptions/:id", h.ReadV1)
+	v1.PATCH("/subscriptions/:id", h.UpdateV1)
+	v1.DELETE("/subscriptions/:id", h.DeleteV1)
+}
+
+// CreateV1 godoc
+//
+//	@Summary		Create new subscription
+//	@Description	Returns the created subscription and its URL in the Location header.
+//	@Tags			API v1
+//	@Accept			json
+//	@Produce		json
+//	@Param			subscription	body		model.SubRequest	true	"Subscription create data"
+//	@Success		201				{object}	handler.Envelope{data=model.Subscription}
+//	@Failure		400				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		409				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500				{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/subscriptions [post]
+func (h *Handler) CreateV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 create handler")
+	sub := model.Subscription{}
+	err := c.ShouldBindBodyWithJSON(&sub)
+	if err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+	sub.Id = 0
+	ctx := c.Request.Context()
+	id, overlaps, err := h.subService.Save(ctx, sub)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	created, err := h.subService.Load(ctx, id)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	c.Header("Location", fmt.Sprintf("/api/v1/subscriptions/%d", id))
+	h.sendData(c, http.StatusCreated, created, Meta{Warnings: overlapWarnings(overlaps)})
+}
+
+// ReadV1 godoc
+//
+//	@Summary	Read subscription by ID
+//	@Tags		API v1
+//	@Produce	json
+//	@Param		id	path		int	true	"Subscription ID"
+//	@Success	200	{object}	handler.Envelope{data=model.Subscription}
+//	@Failure	404	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	500	{object}	handler.Envelope{error=handler.APIError}
+//	@Router		/api/v1/subscriptions/{id} [get]
+func (h *Handler) ReadV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 read handler")
+	subId, err := h.getIDV1(c)
+	if err != nil {
+		return
+	}
+	sub, err := h.subService.Load(c.Request.Context(), subId)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	h.sendData(c, http.StatusOK, sub, Meta{})
+}
+
+// UpdateV1 godoc
+//
+//	@Summary		Update subscription by ID
+//	@Description	Replaces the subscription fields and returns the updated subscription.
+//	@Tags			API v1
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int					true	"Subscription ID"
+//	@Param			subscription	body		model.SubRequest	true	"Subscription update data"
+//	@Success		200				{object}	handler.Envelope{data=model.Subscription}
+//	@Failure		400				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		404				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		409				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500				{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/subscriptions/{id} [patch]
+func (h *Handler) UpdateV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 update handler")
+	subId, err := h.getIDV1(c)
+	if err != nil {
+		return
+	}
+	sub := model.Subscription{}
+	err = c.ShouldBindBodyWithJSON(&sub)
+	if err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+	sub.Id = subId
+	ctx := c.Request.Context()
+	overlaps, err := h.subService.Update(ctx, sub)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	updated, err := h.subService.Load(ctx, subId)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	h.sendData(c, http.StatusOK, updated, Meta{Warnings: overlapWarnings(overlaps)})
+}
+
+// DeleteV1 godoc
+//
+//	@Summary	Delete subscription by ID
+//	@Tags		API v1
+//	@Param		id	path	int	true	"Subscription ID"
+//	@Success	204
+//	@Failure	404	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	500	{object}	handler.Envelope{error=handler.APIError}
+//	@Router		/api/v1/subscriptions/{id} [delete]
+func (h *Handler) DeleteV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 delete handler")
+	subId, err := h.getIDV1(c)
+	if err != nil {
+		return
+	}
+	if err = h.subService.Delete(c.Request.Context(), subId); err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	h.logger.Infoln("request completed successfully")
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// ListV1 godoc
+//
+//	@Summary		Read subscription list
+//	@Description	Returns a page of subscriptions ordered by ID. An empty page is not an error.
+//	@Tags			API v1
+//	@Produce		json
+//	@Param			user_id			query		string	false	"User ID"
+//	@Param			service_name	query		string	false	"Service name"
+//	@Param			limit			query		int		false	"Page size, 1 to 1000 (default 50)"
+//	@Param			offset			query		int		false	"Number of subscriptions to skip"
+//	@Success		200				{object}	handler.Envelope{data=[]model.Subscription}
+//	@Failure		400				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500				{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/subscriptions [get]
+func (h *Handler) ListV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 list handler")
+	filter := model.ListFilter{
+		ServiceName: c.Query("service_name"),
+	}
+	if s := c.Query("user_id"); s != "" {
+		userId, err := uuid.Parse(s)
+		if err != nil {
+			h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("wrong uuid"))
+			return
+		}
+		filter.UserId = userId
+	}
+	var err error
+	if filter.Limit, err = queryInt(c, "limit"); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, err)
+		return
+	}
+	if filter.Offset, err = queryInt(c, "offset"); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, err)
+		return
+	}
+	page, err := h.subService.LoadPage(c.Request.Context(), filter)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	h.sendData(c, http.StatusOK, page.Items, Meta{Pagination: &Pagination{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		Total:  page.Total,
+	}})
+}
+
+// CostV1 godoc
+//
+//	@Summary		Cost of subscriptions
+//	@Description	Returns the total cost of a user's subscriptions to a service between two months.
+//	@Tags			API v1
+//	@Produce		json
+//	@Param			user_id			query		string	true	"User ID"
+//	@Param			service_name	query		string	true	"Service name"
+//	@Param			start			query		string	true	"Start date, MM-YYYY"
+//	@Param			end				query		string	true	"End date, MM-YYYY"
+//	@Success		200				{object}	handler.Envelope{data=model.CostResult}
+//	@Failure		400				{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500				{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/subscriptions/cost [get]
+func (h *Handler) CostV1(c *gin.Context) {
+	h.logger.Infoln("request to the v1 cost handler")
+	data := model.CostRequest{
+		ServiceName: c.Query("service_name"),
+		StartDate:   c.Query("start"),
+		EndDate:     c.Query("end"),
+	}
+	if data.ServiceName == "" {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("service name is required"))
+		return
+	}
+	userId, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("wrong uuid"))
+		return
+	}
+	data.UserId = userId
+	cost, err := h.subService.Cost(c.Request.Context(), data)
+	if err != nil {
+		h.sendServiceError(c, err)
+		return
+	}
+	h.sendData(c, http.StatusOK, model.CostResult{
+		UserId:      data.UserId,
+		ServiceName: data.ServiceName,
+		StartDate:   data.StartDate,
+		EndDate:     data.EndDate,
+		Cost:        cost,
+	}, Meta{})
+}
+
+func (h *Handler) getIDV1(c *gin.Context) (id int, err error) {
+	id, err = strconv.Atoi(c.Params.ByName("id"))
+	if err != nil || id <= 0 {
+		h.sendAPIError(c, http.StatusNotFound, ErrCodeNotFound, fmt.Errorf("incorrect sub id"))
+		return id, errors.New("incorrect sub id")
+	}
+	return id, nil
+}
+
+func queryInt(c *gin.Context, name string) (n int, err error) {
+	s := c.Query(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err = strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s, integer required", name)
+	}
+	return n, nil
+}
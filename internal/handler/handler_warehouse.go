@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportWarehouse godoc
+//
+//	@Summary		Export data for the warehouse
+//	@Description	Writes subscriptions, price history and monthly spend facts as Parquet files partitioned by month into the export directory. Requires "Authorization: Bearer <WAREHOUSE_EXPORT_TOKEN>".
+//	@Tags			Export
+//	@Param			from	query	string	false	"First spend month, MM-YYYY (default: earliest start date)"
+//	@Param			to		query	string	false	"Last spend month, MM-YYYY (default: current month)"
+//	@Produce		json
+//	@Success		200	{object}	handler.RespMsgSuccess{message=model.WarehouseExport}
+//	@Failure		400	{object}	handler.RespMsgError
+//	@Failure		401	{object}	handler.RespMsgError
+//	@Failure		500	{object}	handler.RespMsgError
+//	@Failure		503	{object}	handler.RespMsgError
+//	@Router			/exports/parquet [post]
+func (h *Handler) ExportWarehouse(c *gin.Context) {
+	h.logger.Infoln("request to the warehouse export handler")
+	data := model.WarehouseExportRequest{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+	ctx := c.Request.Context()
+	result, err := h.subService.ExportWarehouse(ctx, data)
+	if errors.Is(err, subscription.ErrValidation) {
+		h.sendError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.sendError(c, http.StatusInternalServerError, fmt.Errorf("warehouse export error"))
+		return
+	}
+	h.sendSuccess(c, http.StatusOK, result)
+}
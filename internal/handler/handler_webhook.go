@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"main/internal/config"
+	"main/internal/model"
+	"main/internal/subscription"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) registerWebhooks() {
+	webhooks := h.router.Group("/api/v1/webhooks", h.apiTokenAuthMiddleware(config.GetConfig().Webhooks.Token))
+	webhooks.POST("", h.CreateWebhook)
+	webhooks.GET("", h.ListWebhooks)
+	webhooks.GET("/:id", h.ReadWebhook)
+	webhooks.PATCH("/:id", h.UpdateWebhook)
+	webhooks.DELETE("/:id", h.DeleteWebhook)
+	webhooks.GET("/:id/deliveries", h.ListDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery_id/replay", h.ReplayDelivery)
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Register webhook
+//	@Description	Registers a URL for subscription lifecycle events: subscription.created, subscription.updated, subscription.deleted and subscription.expired. Deliveries are signed with the webhook secret, which is generated when omitted and only returned by this request.
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		model.WebhookRequest	true	"Webhook data"
+//	@Success		201		{object}	handler.Envelope{data=model.Webhook}
+//	@Failure		400		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		401		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		503		{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	h.logger.Infoln("request to the create webhook handler")
+	data := model.WebhookRequest{}
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+	webhook, err := h.subService.CreateWebhook(c.Request.Context(), data)
+	if err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	c.Header("Location", fmt.Sprintf("/api/v1/webhooks/%d", webhook.Id))
+	h.sendData(c, http.StatusCreated, webhook, Meta{})
+}
+
+// ListWebhooks godoc
+//
+//	@Summary	Read webhook list
+//	@Tags		Webhooks
+//	@Produce	json
+//	@Success	200	{object}	handler.Envelope{data=[]model.Webhook}
+//	@Failure	401	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	500	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	503	{object}	handler.Envelope{error=handler.APIError}
+//	@Router		/api/v1/webhooks [get]
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	h.logger.Infoln("request to the list webhooks handler")
+	webhooks, err := h.subService.LoadWebhooks(c.Request.Context())
+	if err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	h.sendData(c, http.StatusOK, webhooks, Meta{})
+}
+
+// ReadWebhook godoc
+//
+//	@Summary	Read webhook by ID
+//	@Tags		Webhooks
+//	@Produce	json
+//	@Param		id	path		int	true	"Webhook ID"
+//	@Success	200	{object}	handler.Envelope{data=model.Webhook}
+//	@Failure	401	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	404	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	500	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure	503	{object}	handler.Envelope{error=handler.APIError}
+//	@Router		/api/v1/webhooks/{id} [get]
+func (h *Handler) ReadWebhook(c *gin.Context) {
+	h.logger.Infoln("request to the read webhook handler")
+	id, err := h.getWebhookID(c, "id", "incorrect webhook id")
+	if err != nil {
+		return
+	}
+	webhook, err := h.subService.LoadWebhook(c.Request.Context(), id)
+	if err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	h.sendData(c, http.StatusOK, webhook, Meta{})
+}
+
+// UpdateWebhook godoc
+//
+//	@Summary		Update webhook by ID
+//	@Description	Changes only the fields set in the request. Set active to false to pause deliveries, pending ones are sent once it is active again.
+//	@Tags			Webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Webhook ID"
+//	@Param			webhook	body		model.WebhookRequest	true	"Webhook data"
+//	@Success		200		{object}	handler.Envelope{data=model.Webhook}
+//	@Failure		400		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		401		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		404		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		503		{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/webhooks/{id} [patch]
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	h.logger.Infoln("request to the update webhook handler")
+	id, err := h.getWebhookID(c, "id", "incorrect webhook id")
+	if err != nil {
+		return
+	}
+	data := model.WebhookRequest{}
+	if err = c.ShouldBindJSON(&data); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, fmt.Errorf("reading request body error"))
+		return
+	}
+	webhook, err := h.subService.UpdateWebhook(c.Request.Context(), id, data)
+	if err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	h.sendData(c, http.StatusOK, webhook, Meta{})
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Delete webhook by ID
+//	@Description	Deletes the webhook together with its delivery log.
+//	@Tags			Webhooks
+//	@Param			id	path	int	true	"Webhook ID"
+//	@Success		204
+//	@Failure		401	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		404	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500	{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		503	{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	h.logger.Infoln("request to the delete webhook handler")
+	id, err := h.getWebhookID(c, "id", "incorrect webhook id")
+	if err != nil {
+		return
+	}
+	if err = h.subService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	h.logger.Infoln("request completed successfully")
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+//
+//	@Summary		Read webhook delivery log
+//	@Description	Returns the deliveries of a webhook, newest first.
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Param			id		path		int		true	"Webhook ID"
+//	@Param			status	query		string	false	"pending, succeeded or failed"
+//	@Param			limit	query		int		false	"Page size, 1 to 500 (default 50)"
+//	@Param			offset	query		int		false	"Number of deliveries to skip"
+//	@Success		200		{object}	handler.Envelope{data=[]model.WebhookDelivery}
+//	@Failure		400		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		401		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		404		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500		{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		503		{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	h.logger.Infoln("request to the list deliveries handler")
+	id, err := h.getWebhookID(c, "id", "incorrect webhook id")
+	if err != nil {
+		return
+	}
+	filter := model.DeliveryFilter{
+		WebhookId: id,
+		Status:    c.Query("status"),
+	}
+	if filter.Limit, err = queryInt(c, "limit"); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, err)
+		return
+	}
+	if filter.Offset, err = queryInt(c, "offset"); err != nil {
+		h.sendAPIError(c, http.StatusBadRequest, ErrCodeBadRequest, err)
+		return
+	}
+	list, err := h.subService.LoadDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		h.sendWebhookError(c, err, "webhook not found")
+		return
+	}
+	h.sendData(c, http.StatusOK, list, Meta{})
+}
+
+// ReplayDelivery godoc
+//
+//	@Summary		Replay webhook delivery
+//	@Description	Queues the event of a delivery to the webhook again with the same event ID and returns the ID of the new delivery.
+//	@Tags			Webhooks
+//	@Produce		json
+//	@Param			id			path		int	true	"Webhook ID"
+//	@Param			delivery_id	path		int	true	"Delivery ID"
+//	@Success		201			{object}	handler.Envelope{data=int}
+//	@Failure		401			{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		404			{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		500			{object}	handler.Envelope{error=handler.APIError}
+//	@Failure		503			{object}	handler.Envelope{error=handler.APIError}
+//	@Router			/api/v1/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (h *Handler) ReplayDelivery(c *gin.Context) {
+	h.logger.Infoln("request to the replay delivery handler")
+	id, err := h.getWebhookID(c, "id", "incorrect webhook id")
+	if err != nil {
+		return
+	}
+	deliveryId, err := h.getWebhookID(c, "delivery_id", "incorrect delivery id")
+	if err != nil {
+		return
+	}
+	newId, err := h.subService.ReplayDelivery(c.Request.Context(), id, deliveryId)
+	if err != nil {
+		h.sendWebhookError(c, err, "delivery not found")
+		return
+	}
+	h.sendData(c, http.StatusCreated, newId, Meta{})
+}
+
+func (h *Handler) getWebhookID(c *gin.Context, param string, message string) (id int, err error) {
+	id, err = strconv.Atoi(c.Params.ByName(param))
+	if err != nil || id <= 0 {
+		h.sendAPIError(c, http.StatusNotFound, ErrCodeNotFound, errors.New(message))
+		return id, errors.New(message)
+	}
+	return id, nil
+}
+
+// sendWebhookError is sendServiceError with a not found message that names
+// the missing webhook resource instead of a sub.
+func (h *Handler) sendWebhookError(c *gin.Context, err error, notFound string) {
+	if errors.Is(err, subscription.ErrNotFound) {
+		h.sendAPIError(c, http.StatusNotFound, ErrCodeNotFound, errors.New(notFound))
+		return
+	}
+	h.sendServiceError(c, err)
+}
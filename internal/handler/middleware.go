@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func CORSMiddleware() gin.HandlerFunc {
@@ -11,7 +15,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Location, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Request-ID, Authorization, accept, origin, Cache-Control, X-Requested-With")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -21,3 +26,68 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+const requestIdKey = "request_id"
+
+// RequestIDMiddleware keeps the caller's X-Request-ID or generates one, so the
+// id can be echoed in responses and matched against the logs.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		c.Set(requestIdKey, id)
+		c.Writer.Header().Set("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// TokenAuthMiddleware accepts requests with "Authorization: Bearer <token>".
+// An empty token disables the routes instead of leaving them open.
+func TokenAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if code := checkToken(c, token); code != 0 {
+			c.AbortWithStatusJSON(code, RespMsgError{
+				Success: false,
+				Message: tokenMessages[code],
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// apiTokenAuthMiddleware is TokenAuthMiddleware for the /api/v1 routes,
+// which answer with the envelope.
+func (h *Handler) apiTokenAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if code := checkToken(c, token); code != 0 {
+			errCode := ErrCodeUnauthorized
+			if code == http.StatusServiceUnavailable {
+				errCode = ErrCodeUnavailable
+			}
+			h.sendAPIError(c, code, errCode, errors.New(tokenMessages[code]))
+			return
+		}
+		c.Next()
+	}
+}
+
+var tokenMessages = map[int]string{
+	http.StatusServiceUnavailable: "access token is not configured",
+	http.StatusUnauthorized:       "unauthorized",
+}
+
+// checkToken returns the status code to reject the request with, or 0 if it
+// carries the token.
+func checkToken(c *gin.Context, token string) int {
+	if token == "" {
+		return http.StatusServiceUnavailable
+	}
+	got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		return http.StatusUnauthorized
+	}
+	return 0
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"main/internal/export"
+	"main/internal/model"
+	"main/internal/subscription"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	stubUser = uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	stubTime = time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// stubService answers every call with a fixed, fully populated result, or
+// fails everything looked up by id with err when it is set.
+type stubService struct {
+	err error
+}
+
+var _ subscription.SubscriptionInterface = stubService{}
+
+func stubSub(id int) model.Subscription {
+	return model.Subscription{
+		Id:            id,
+		ServiceName:   "Yandex Plus",
+		Price:         400,
+		UserId:        stubUser,
+		StartDate:     "01-2025",
+		EndDate:       "12-2025",
+		BillingPeriod: model.BillingMonthly,
+		TrialEndDate:  "",
+	}
+}
+
+func stubWebhook(id int) model.Webhook {
+	return model.Webhook{
+		Id:         id,
+		URL:        "https://example.com/hooks/subscriptions",
+		EventTypes: []string{model.WebhookSubscriptionCreated},
+		Active:     true,
+		CreatedAt:  stubTime,
+	}
+}
+
+func (s stubService) byId() error {
+	return s.err
+}
+
+func (s stubService) Save(ctx context.Context, sub model.Subscription) (id int, overlaps []model.Subscription, err error) {
+	return 1, []model.Subscription{stubSub(2)}, nil
+}
+
+func (s stubService) Load(ctx context.Context, subID int) (sub model.Subscription, err error) {
+	return stubSub(subID), s.byId()
+}
+
+func (s stubService) LoadList(ctx context.Context, filter model.ListFilter) (subs []model.Subscription, err error) {
+	return []model.Subscription{stubSub(1)}, nil
+}
+
+func (s stubService) LoadPage(ctx context.Context, filter model.ListFilter) (page model.SubscriptionPage, err error) {
+	return model.SubscriptionPage{Items: []model.Subscription{stubSub(1)}, Limit: 50, Total: 1}, nil
+}
+
+func (s stubService) StreamList(ctx context.Context, filter model.ListFilter, fn func(sub model.Subscription) error) (err error) {
+	return fn(stubSub(1))
+}
+
+func (s stubService) Search(ctx context.Context, data model.SearchRequest) (results []model.SearchResult, err error) {
+	return []model.SearchResult{{Subscription: stubSub(1), Score: 0.8}}, nil
+}
+
+func (s stubService) LoadListByIds(ctx context.Context, ids []int) (subs []model.Subscription, err error) {
+	for _, id := range ids {
+		subs = append(subs, stubSub(id))
+	}
+	return subs, nil
+}
+
+func (s stubService) LoadListByUsers(ctx context.Context, userIds []uuid.UUID) (subs []model.Subscription, err error) {
+	return []model.Subscription{stubSub(1)}, nil
+}
+
+func (s stubService) Delete(ctx context.Context, subID int) (err error) {
+	return s.byId()
+}
+
+func (s stubService) Update(ctx context.Context, sub model.Subscription) (overlaps []model.Subscription, err error) {
+	return []model.Subscription{stubSub(2)}, s.byId()
+}
+
+func (s stubService) Cost(ctx context.Context, data model.CostRequest) (cost int, err error) {
+	return 4800, nil
+}
+
+func (s stubService) SpendReport(ctx context.Context, data model.SpendReportRequest) (report []model.MonthSpend, err error) {
+	return []model.MonthSpend{{
+		Month:    "01-2025",
+		Total:    400,
+		Services: []model.ServiceSpend{{ServiceName: "Yandex Plus", Total: 400}},
+	}}, nil
+}
+
+func (s stubService) PopularServices(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePopularity, err error) {
+	return []model.ServicePopularity{{ServiceName: "Yandex Plus", Subscribers: 42, Revenue: 16800}}, nil
+}
+
+func (s stubService) PriceStats(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePriceStats, err error) {
+	return []model.ServicePriceStats{{ServiceName: "Yandex Plus", Subscriptions: 42, AveragePrice: 399.5, MedianPrice: 400}}, nil
+}
+
+func (s stubService) Churn(ctx context.Context, data model.AnalyticsRequest) (list []model.MonthChurn, err error) {
+	return []model.MonthChurn{{Month: "01-2025", New: 10, Cancelled: 3}}, nil
+}
+
+func (s stubService) Lifetime(ctx context.Context, data model.AnalyticsRequest) (lifetime model.SubscriptionLifetime, err error) {
+	return model.SubscriptionLifetime{Subscriptions: 120, AverageMonths: 7.5}, nil
+}
+
+func (s stubService) Forecast(ctx context.Context, data model.ForecastRequest) (forecast model.Forecast, err error) {
+	return model.Forecast{
+		UserId:        data.UserId,
+		From:          "11-2026",
+		To:            "10-2027",
+		Total:         400,
+		Months:        []model.ForecastMonth{{Month: "11-2026", Total: 400}},
+		Subscriptions: []model.ForecastContribution{{SubscriptionId: 1, ServiceName: "Yandex Plus", BillingPeriod: model.BillingMonthly, Charges: 1, Total: 400}},
+		Assumptions:   []string{"prices stay as scheduled"},
+	}, nil
+}
+
+func (s stubService) SaveScheduledPrice(ctx context.Context, subID int, data model.ScheduledPriceRequest) (id int, err error) {
+	return 1, s.byId()
+}
+
+func (s stubService) LoadScheduledPrices(ctx context.Context, subID int) (prices []model.ScheduledPrice, err error) {
+	return []model.ScheduledPrice{{Id: 1, SubscriptionId: subID, Price: 500, EffectiveDate: "03-2026"}}, s.byId()
+}
+
+func (s stubService) PriceAlerts(ctx context.Context, data model.PriceAlertRequest) (alerts model.PriceAlerts, err error) {
+	return model.PriceAlerts{
+		Increases: []model.PriceIncrease{{SubscriptionId: 1, UserId: stubUser, ServiceName: "Yandex Plus", OldPrice: 400, NewPrice: 500, Percent: 25}},
+		Outliers:  []model.PriceOutlier{{SubscriptionId: 1, UserId: stubUser, ServiceName: "Yandex Plus", Price: 600, MedianPrice: 400, Percent: 50}},
+	}, nil
+}
+
+func (s stubService) Duplicates(ctx context.Context, userId uuid.UUID) (pairs []model.DuplicatePair, err error) {
+	return []model.DuplicatePair{{First: stubSub(1), Second: stubSub(2)}}, nil
+}
+
+func (s stubService) Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) (results []model.BatchResult, err error) {
+	for i, op := range ops {
+		results = append(results, model.BatchResult{Index: i, Op: op.Op, Id: 1, Status: 200})
+	}
+	return results, nil
+}
+
+func (s stubService) Import(ctx context.Context, r io.Reader, dryRun bool) (report model.ImportReport, err error) {
+	return model.ImportReport{DryRun: dryRun, Total: 1, Valid: 1, Rows: []model.ImportRow{{Row: 2, Id: 1}}}, nil
+}
+
+func (s stubService) ExportList(ctx context.Context, filter model.ListFilter, w export.Writer) (err error) {
+	return w.Close()
+}
+
+func (s stubService) ExportCost(ctx context.Context, data model.CostRequest, w export.Writer) (err error) {
+	return w.Close()
+}
+
+func (s stubService) ExportSpendReport(ctx context.Context, data model.SpendReportRequest, w export.Writer) (err error) {
+	return w.Close()
+}
+
+func (s stubService) ExportUserData(ctx context.Context, userId uuid.UUID, w io.Writer) (err error) {
+	return nil
+}
+
+func (s stubService) EraseUserData(ctx context.Context, userId uuid.UUID) (receipt model.ErasureReceipt, err error) {
+	return model.ErasureReceipt{
+		Id:        1,
+		UserId:    userId,
+		Removed:   []model.ErasedTable{{Table: model.TableSubscriptions, Rows: 1}},
+		ErasedAt:  stubTime,
+		Signature: "hmac-sha256:00",
+	}, nil
+}
+
+func (s stubService) ExportWarehouse(ctx context.Context, data model.WarehouseExportRequest) (result model.WarehouseExport, err error) {
+	return model.WarehouseExport{
+		Path:          "exports/export-20250301T120000Z",
+		SchemaVersion: 1,
+		CreatedAt:     stubTime,
+		From:          stubTime,
+		To:            stubTime,
+		Files:         []model.WarehouseFile{{Dataset: "subscriptions", Path: "subscriptions/month=2025-03/part-0.parquet", Rows: 1}},
+	}, nil
+}
+
+func (s stubService) DetectCharges(ctx context.Context, r io.Reader, filename string, userId uuid.UUID) (candidates []model.ChargeCandidate, err error) {
+	return []model.ChargeCandidate{{Subscription: stubSub(0), Merchant: "YANDEX PLUS", Charges: 6, LastCharge: "2025-06-05", Confidence: 0.83}}, nil
+}
+
+func (s stubService) CreateWebhook(ctx context.Context, data model.WebhookRequest) (webhook model.Webhook, err error) {
+	webhook = stubWebhook(1)
+	webhook.Secret = "generated"
+	return webhook, nil
+}
+
+func (s stubService) LoadWebhook(ctx context.Context, id int) (webhook model.Webhook, err error) {
+	return stubWebhook(id), s.byId()
+}
+
+func (s stubService) LoadWebhooks(ctx context.Context) (webhooks []model.Webhook, err error) {
+	return []model.Webhook{stubWebhook(1)}, nil
+}
+
+func (s stubService) UpdateWebhook(ctx context.Context, id int, data model.WebhookRequest) (webhook model.Webhook, err error) {
+	return stubWebhook(id), s.byId()
+}
+
+func (s stubService) DeleteWebhook(ctx context.Context, id int) (err error) {
+	return s.byId()
+}
+
+func (s stubService) LoadDeliveries(ctx context.Context, filter model.DeliveryFilter) (list []model.WebhookDelivery, err error) {
+	return []model.WebhookDelivery{{
+		Id:           1,
+		WebhookId:    filter.WebhookId,
+		EventId:      "0f8fad5b-d9cb-469f-a165-70867728950e",
+		EventType:    model.WebhookSubscriptionCreated,
+		Payload:      json.RawMessage(`{}`),
+		Status:       model.DeliverySucceeded,
+		Attempts:     1,
+		ResponseCode: 200,
+		CreatedAt:    stubTime,
+		DeliveredAt:  &stubTime,
+	}}, s.byId()
+}
+
+func (s stubService) ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (id int, err error) {
+	return 2, s.byId()
+}
+
+func (s stubService) NotifyExpired(ctx context.Context, now time.Time) (count int, err error) {
+	return 0, nil
+}
+
+func (s stubService) WatchChanges(ctx context.Context, filter model.ChangeFilter, fn func(change model.SubscriptionChange) error) (err error) {
+	return fn(model.SubscriptionChange{
+		Id:             1,
+		Type:           model.WebhookSubscriptionCreated,
+		SubscriptionId: 1,
+		UserId:         stubUser,
+		ChangedAt:      stubTime,
+		Data:           json.RawMessage(`{}`),
+	})
+}
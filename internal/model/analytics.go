@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+type AnalyticsRequest struct {
+	StartDate string
+	EndDate   string
+	OrderBy   string
+	Limit     int
+}
+
+type AnalyticsDTO struct {
+	StartDate time.Time
+	EndDate   time.Time
+	OrderBy   string
+	Limit     int
+}
+
+type ServicePopularity struct {
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	Subscribers int    `json:"subscribers" example:"42"`
+	Revenue     int    `json:"revenue" example:"16800"`
+}
+
+type ServicePriceStats struct {
+	ServiceName   string  `json:"service_name" example:"Yandex Plus"`
+	Subscriptions int     `json:"subscriptions" example:"42"`
+	AveragePrice  float64 `json:"average_price" example:"399.5"`
+	MedianPrice   float64 `json:"median_price" example:"400"`
+}
+
+type MonthChurn struct {
+	Month     string `json:"month" example:"01-2025"`
+	New       int    `json:"new" example:"10"`
+	Cancelled int    `json:"cancelled" example:"3"`
+}
+
+type MonthChurnDTO struct {
+	Month     time.Time
+	New       int
+	Cancelled int
+}
+
+type SubscriptionLifetime struct {
+	Subscriptions int     `json:"subscriptions" example:"120"`
+	AverageMonths float64 `json:"average_months" example:"7.5"`
+}
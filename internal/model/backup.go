@@ -0,0 +1,29 @@
+package model
+
+const (
+	RestoreFail      = "fail"
+	RestoreSkip      = "skip"
+	RestoreOverwrite = "overwrite"
+)
+
+const (
+	TableSubscriptions   = "subscriptions"
+	TableScheduledPrices = "scheduled_prices"
+	TableEvents          = "subscription_events"
+)
+
+type BackupDTO struct {
+	Subscriptions   []SubscriptionDTO
+	ScheduledPrices []ScheduledPriceDTO
+	Events          []EventDTO
+	Webhooks        []WebhookDTO
+	Expirations     []ExpirationDTO
+	Erasures        []ErasureDTO
+}
+
+type RestoreCount struct {
+	Table    string
+	Inserted int
+	Updated  int
+	Skipped  int
+}
@@ -0,0 +1,37 @@
+package model
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+type BatchOperation struct {
+	Op           string       `json:"op" example:"update"`
+	Id           int          `json:"id,omitempty" example:"5"`
+	Subscription Subscription `json:"subscription"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchResult struct {
+	Index  int    `json:"index" example:"0"`
+	Op     string `json:"op" example:"update"`
+	Id     int    `json:"id,omitempty" example:"5"`
+	Status int    `json:"status" example:"200"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchOperationDTO struct {
+	Op  string
+	Sub SubscriptionDTO
+	// Event is the price change event of an update, saved with it.
+	Event *EventDTO
+}
+
+type BatchResultDTO struct {
+	Id  int
+	Err error
+}
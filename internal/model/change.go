@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const TableChanges = "subscription_changes"
+
+// SubscriptionChange is a row change logged by the subscriptions trigger.
+// Data is the subscription after the change, or before it for deletes.
+type SubscriptionChange struct {
+	Id             int64           `json:"id" example:"42"`
+	Type           string          `json:"type" example:"subscription.updated"`
+	SubscriptionId int             `json:"subscription_id" example:"7"`
+	UserId         uuid.UUID       `json:"user_id"`
+	ChangedAt      time.Time       `json:"changed_at"`
+	Data           json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+type ChangeFilter struct {
+	AfterId int64
+	Ids     []int64
+	UserId  uuid.UUID
+	Limit   int
+}
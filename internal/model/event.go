@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventPriceChange = "price_change"
+)
+
+type EventDTO struct {
+	Id             int
+	Type           string
+	SubscriptionId int
+	UserId         uuid.UUID
+	ServiceName    string
+	OldPrice       int
+	NewPrice       int
+	CreatedAt      time.Time
+}
+
+type PriceAlertRequest struct {
+	Since       string
+	UserId      uuid.UUID
+	ServiceName string
+}
+
+type PriceAlertDTO struct {
+	Since       time.Time
+	UserId      uuid.UUID
+	ServiceName string
+	Threshold   float64
+}
+
+type PriceIncrease struct {
+	SubscriptionId int       `json:"subscription_id" example:"5"`
+	UserId         uuid.UUID `json:"user_id"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	OldPrice       int       `json:"old_price" example:"400"`
+	NewPrice       int       `json:"new_price" example:"500"`
+	Percent        float64   `json:"percent" example:"25"`
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+type PriceOutlier struct {
+	SubscriptionId int       `json:"subscription_id" example:"5"`
+	UserId         uuid.UUID `json:"user_id"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	Price          int       `json:"price" example:"600"`
+	MedianPrice    float64   `json:"median_price" example:"400"`
+	Percent        float64   `json:"percent" example:"50"`
+}
+
+type PriceAlerts struct {
+	Increases []PriceIncrease `json:"increases"`
+	Outliers  []PriceOutlier  `json:"outliers"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduledPrice struct {
+	Id             int    `json:"id"`
+	SubscriptionId int    `json:"subscription_id"`
+	Price          int    `json:"price" example:"500"`
+	EffectiveDate  string `json:"effective_date" example:"03-2026"`
+}
+
+type ScheduledPriceDTO struct {
+	Id             int
+	SubscriptionId int
+	Price          int
+	EffectiveDate  time.Time
+}
+
+type ScheduledPriceRequest struct {
+	Price         int    `json:"price" example:"500"`
+	EffectiveDate string `json:"effective_date" example:"03-2026"`
+}
+
+type ForecastRequest struct {
+	UserId uuid.UUID
+	Months int
+}
+
+type ForecastMonth struct {
+	Month string `json:"month" example:"11-2026"`
+	Total int    `json:"total" example:"1200"`
+}
+
+type ForecastContribution struct {
+	SubscriptionId int    `json:"subscription_id" example:"5"`
+	ServiceName    string `json:"service_name" example:"Yandex Plus"`
+	BillingPeriod  string `json:"billing_period" example:"monthly"`
+	Charges        int    `json:"charges" example:"12"`
+	Total          int    `json:"total" example:"4800"`
+}
+
+type Forecast struct {
+	UserId        uuid.UUID              `json:"user_id"`
+	From          string                 `json:"from" example:"11-2026"`
+	To            string                 `json:"to" example:"10-2027"`
+	Total         int                    `json:"total" example:"14400"`
+	Months        []ForecastMonth        `json:"months"`
+	Subscriptions []ForecastContribution `json:"subscriptions"`
+	Assumptions   []string               `json:"assumptions"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type GraphQLRequest struct {
+	Query         string         `json:"query" example:"{ user(id: \"60601fee-2bf1-4721-ae6f-7636e79a0cba\") { subscriptions { id serviceName price } } }"`
+	OperationName string         `json:"operationName" extensions:"x-nullable"`
+	Variables     map[string]any `json:"variables" extensions:"x-nullable"`
+}
+
+type GraphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+	Message    string         `json:"message" example:"sub not found"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
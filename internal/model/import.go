@@ -0,0 +1,16 @@
+package model
+
+type ImportRow struct {
+	Row      int      `json:"row" example:"2"`
+	Id       int      `json:"id,omitempty" example:"5"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun   bool        `json:"dry_run" example:"true"`
+	Total    int         `json:"total" example:"120"`
+	Valid    int         `json:"valid" example:"118"`
+	Imported int         `json:"imported" example:"0"`
+	Rows     []ImportRow `json:"rows"`
+}
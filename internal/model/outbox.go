@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxSchemaVersion is the version of the published event format. It is
+// raised on every change that is not backwards compatible.
+const OutboxSchemaVersion = 1
+
+const TableOutbox = "outbox"
+
+// OutboxEvent is published for every insert, update and delete of a
+// subscription row, restores and erasures included. Data is the subscription
+// after the change, or before it for deletes. The deletes of an erasure carry
+// only the subscription id. An expiry does not change the row and is only
+// sent to webhooks.
+type OutboxEvent struct {
+	Id             int64           `json:"id"`
+	Type           string          `json:"type"`
+	SchemaVersion  int             `json:"schema_version"`
+	SubscriptionId int             `json:"subscription_id"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Outbox events use the names of the webhook events.
+const (
+	OutboxSubscriptionCreated = WebhookSubscriptionCreated
+	OutboxSubscriptionUpdated = WebhookSubscriptionUpdated
+	OutboxSubscriptionDeleted = WebhookSubscriptionDeleted
+)
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const TableErasures = "user_erasures"
+
+type Event struct {
+	Id             int       `json:"id" example:"1"`
+	Type           string    `json:"type" example:"price_change"`
+	SubscriptionId int       `json:"subscription_id" example:"5"`
+	ServiceName    string    `json:"service_name" example:"Yandex Plus"`
+	OldPrice       int       `json:"old_price" example:"400"`
+	NewPrice       int       `json:"new_price" example:"500"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type UserExportManifest struct {
+	UserId      uuid.UUID `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+}
+
+type ErasedTable struct {
+	Table string `json:"table" example:"subscriptions"`
+	Rows  int    `json:"rows" example:"3"`
+}
+
+type ErasureDTO struct {
+	Id       int
+	UserId   uuid.UUID
+	UserHash string
+	Removed  []ErasedTable
+	ErasedAt time.Time
+}
+
+type ErasureReceipt struct {
+	Id        int           `json:"id" example:"1"`
+	UserId    uuid.UUID     `json:"user_id"`
+	Removed   []ErasedTable `json:"removed"`
+	ErasedAt  time.Time     `json:"erased_at"`
+	Signature string        `json:"signature,omitempty" example:"hmac-sha256:9f86d08..."`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SpendReportRequest struct {
+	StartDate   string
+	EndDate     string
+	UserId      uuid.UUID
+	GroupByUser bool
+}
+
+type SpendReportDTO struct {
+	StartDate   time.Time
+	EndDate     time.Time
+	UserId      uuid.UUID
+	GroupByUser bool
+}
+
+type ServiceSpend struct {
+	ServiceName string `json:"service_name" example:"Yandex Plus"`
+	Total       int    `json:"total" example:"400"`
+}
+
+type MonthSpend struct {
+	Month    string         `json:"month" example:"01-2025"`
+	UserId   *uuid.UUID     `json:"user_id,omitempty"`
+	Total    int            `json:"total" example:"1200"`
+	Services []ServiceSpend `json:"services"`
+}
+
+type MonthSpendDTO struct {
+	Month time.Time
+	// UserId is nil for months without spend, as no user joins them.
+	UserId   *uuid.UUID
+	Total    int
+	Services []ServiceSpend
+}
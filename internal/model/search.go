@@ -0,0 +1,34 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	SearchFuzzy  = "fuzzy"
+	SearchPrefix = "prefix"
+)
+
+type SearchRequest struct {
+	Query  string
+	UserId uuid.UUID
+	Mode   string
+	Limit  int
+}
+
+type SearchDTO struct {
+	Query  string
+	UserId uuid.UUID
+	Prefix bool
+	Limit  int
+}
+
+// SearchResult is a subscription whose service name matches the query, with
+// the trigram similarity of the two from 0 to 1.
+type SearchResult struct {
+	Subscription Subscription `json:"subscription"`
+	Score        float64      `json:"score" example:"0.75"`
+}
+
+type SearchResultDTO struct {
+	Sub   SubscriptionDTO
+	Score float64
+}
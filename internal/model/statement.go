@@ -0,0 +1,10 @@
+package model
+
+type ChargeCandidate struct {
+	Subscription Subscription `json:"subscription"`
+	Merchant     string       `json:"merchant" example:"NETFLIX.COM"`
+	Charges      int          `json:"charges" example:"6"`
+	LastCharge   string       `json:"last_charge" example:"2025-06-05"`
+	Confidence   float64      `json:"confidence" example:"0.83"`
+	ExistingId   int          `json:"existing_id,omitempty" example:"5"`
+}
@@ -6,22 +6,43 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	BillingMonthly = "monthly"
+	BillingYearly  = "yearly"
+)
+
 type Subscription struct {
-	Id          int       `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
-	UserId      uuid.UUID `json:"user_id"`
-	StartDate   string    `json:"start_date"`
-	EndDate     string    `json:"end_date"`
+	Id            int       `json:"id" example:"1"`
+	ServiceName   string    `json:"service_name" example:"Yandex Plus"`
+	Price         int       `json:"price" example:"400"`
+	UserId        uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate     string    `json:"start_date" example:"07-2025"`
+	EndDate       string    `json:"end_date" example:"12-2025"`
+	BillingPeriod string    `json:"billing_period" example:"monthly"`
+	TrialEndDate  string    `json:"trial_end_date" example:""`
 }
 
 type SubscriptionDTO struct {
-	Id          int       `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
-	UserId      uuid.UUID `json:"user_id"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
+	Id            int       `json:"id"`
+	ServiceName   string    `json:"service_name"`
+	Price         int       `json:"price"`
+	UserId        uuid.UUID `json:"user_id"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	BillingPeriod string    `json:"billing_period"`
+	TrialEndDate  time.Time `json:"trial_end_date"`
+}
+
+type ListFilter struct {
+	UserId      uuid.UUID
+	ServiceName string
+	Limit       int
+	Offset      int
+}
+
+type DuplicatePair struct {
+	First  Subscription `json:"first"`
+	Second Subscription `json:"second"`
 }
 
 type CostRequest struct {
@@ -31,6 +52,21 @@ type CostRequest struct {
 	ServiceName string
 }
 
+type CostResult struct {
+	UserId      uuid.UUID `json:"user_id"`
+	ServiceName string    `json:"service_name" example:"Yandex Plus"`
+	StartDate   string    `json:"start_date" example:"01-2025"`
+	EndDate     string    `json:"end_date" example:"12-2025"`
+	Cost        int       `json:"cost" example:"4800"`
+}
+
+type SubscriptionPage struct {
+	Items  []Subscription
+	Limit  int
+	Offset int
+	Total  int
+}
+
 type CostDTO struct {
 	StartDate   time.Time
 	EndDate     time.Time
@@ -39,9 +75,11 @@ type CostDTO struct {
 }
 
 type SubRequest struct {
-	ServiceName string    `json:"service_name" example:"Yandex Plus"`
-	Price       int       `json:"price" example:"400"`
-	UserId      uuid.UUID `json:"user_id" example:"UUID"`
-	StartDate   string    `json:"start_date" example:"01-2025"`
-	EndDate     string    `json:"end_date" example:"02-2025"`
+	ServiceName   string    `json:"service_name" example:"Yandex Plus"`
+	Price         int       `json:"price" example:"400"`
+	UserId        uuid.UUID `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	StartDate     string    `json:"start_date" example:"01-2025"`
+	EndDate       string    `json:"end_date" example:"02-2025"`
+	BillingPeriod string    `json:"billing_period" example:"monthly"`
+	TrialEndDate  string    `json:"trial_end_date" example:"01-2025"`
 }
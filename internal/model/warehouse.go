@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type WarehouseExportRequest struct {
+	From string
+	To   string
+}
+
+type WarehouseFile struct {
+	Dataset string `json:"dataset" example:"subscriptions"`
+	Path    string `json:"path" example:"subscriptions/month=2025-01/part-0.parquet"`
+	Rows    int    `json:"rows" example:"120"`
+}
+
+type WarehouseExport struct {
+	Path          string          `json:"path" example:"exports/export-20260101T000000Z"`
+	SchemaVersion int             `json:"schema_version" example:"1"`
+	CreatedAt     time.Time       `json:"created_at"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	Files         []WarehouseFile `json:"files"`
+}
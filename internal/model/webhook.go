@@ -0,0 +1,117 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookSubscriptionCreated = "subscription.created"
+	WebhookSubscriptionUpdated = "subscription.updated"
+	WebhookSubscriptionDeleted = "subscription.deleted"
+	WebhookSubscriptionExpired = "subscription.expired"
+)
+
+var WebhookEventTypes = []string{
+	WebhookSubscriptionCreated,
+	WebhookSubscriptionUpdated,
+	WebhookSubscriptionDeleted,
+	WebhookSubscriptionExpired,
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	TableWebhooks          = "webhooks"
+	TableWebhookDeliveries = "webhook_deliveries"
+	TableExpirations       = "subscription_expirations"
+)
+
+type WebhookRequest struct {
+	URL        string   `json:"url" example:"https://example.com/hooks/subscriptions"`
+	Secret     string   `json:"secret" example:"generated when empty"`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.deleted" extensions:"x-nullable"`
+	Active     *bool    `json:"active" example:"true" extensions:"x-nullable"`
+}
+
+// Webhook is returned by the API. Secret is only set in the response to
+// the request that created the webhook.
+type Webhook struct {
+	Id         int       `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://example.com/hooks/subscriptions"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types" example:"subscription.created,subscription.deleted"`
+	Active     bool      `json:"active" example:"true"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDTO struct {
+	Id         int
+	URL        string
+	Secret     string
+	EventTypes []string
+	Active     bool
+	CreatedAt  time.Time
+}
+
+// ExpirationDTO records that subscription.expired was sent for the end date
+// of a subscription.
+type ExpirationDTO struct {
+	SubscriptionId int
+	EndDate        time.Time
+	NotifiedAt     time.Time
+}
+
+// WebhookEvent is the body of every delivery.
+type WebhookEvent struct {
+	Id         string       `json:"id" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	Type       string       `json:"type" example:"subscription.created"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Data       Subscription `json:"data"`
+}
+
+type WebhookDelivery struct {
+	Id            int             `json:"id" example:"12"`
+	WebhookId     int             `json:"webhook_id" example:"1"`
+	EventId       string          `json:"event_id" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	EventType     string          `json:"event_type" example:"subscription.created"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	Status        string          `json:"status" example:"succeeded"`
+	Attempts      int             `json:"attempts" example:"1"`
+	ResponseCode  int             `json:"response_code,omitempty" example:"200"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type DeliveryFilter struct {
+	WebhookId int
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// DeliveryDTO is a claimed delivery together with the target of its webhook.
+type DeliveryDTO struct {
+	Id        int
+	WebhookId int
+	EventId   string
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+type DeliveryResultDTO struct {
+	Id            int
+	Status        string
+	ResponseCode  int
+	LastError     string
+	NextAttemptAt time.Time
+}
@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	Version = "3.1.0"
+
+	definitionsRef = "#/definitions/"
+	schemasRef     = "#/components/schemas/"
+)
+
+// Convert turns the Swagger 2.0 document generated from the handler
+// annotations into an OpenAPI 3.1 document, so the annotations stay the one
+// place the API is described.
+func Convert(swagger []byte) (doc map[string]any, err error) {
+	src := map[string]any{}
+	if err = json.Unmarshal(swagger, &src); err != nil {
+		return doc, fmt.Errorf("openapi error, failed to parse swagger document: %v", err)
+	}
+	if v, _ := src["swagger"].(string); v != "2.0" {
+		return doc, fmt.Errorf("openapi error, unsupported swagger version %q", v)
+	}
+
+	info, _ := src["info"].(map[string]any)
+	if info == nil {
+		info = map[string]any{}
+	}
+	if _, ok := info["title"]; !ok {
+		info["title"] = ""
+	}
+	if _, ok := info["version"]; !ok {
+		info["version"] = ""
+	}
+	basePath, _ := src["basePath"].(string)
+	if basePath == "" {
+		basePath = "/"
+	}
+	consumes := stringList(src["consumes"])
+	produces := stringList(src["produces"])
+
+	paths := map[string]any{}
+	srcPaths, _ := src["paths"].(map[string]any)
+	for path, item := range srcPaths {
+		methods, _ := item.(map[string]any)
+		converted := map[string]any{}
+		for method, op := range methods {
+			srcOp, ok := op.(map[string]any)
+			if !ok {
+				continue
+			}
+			converted[method] = convertOperation(srcOp, consumes, produces)
+		}
+		paths[path] = converted
+	}
+
+	schemas := map[string]any{}
+	definitions, _ := src["definitions"].(map[string]any)
+	for name, schema := range definitions {
+		schemas[name] = convertSchema(schema)
+	}
+
+	doc = map[string]any{
+		"openapi": Version,
+		"info":    info,
+		"servers": []any{map[string]any{"url": basePath}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+		},
+	}
+	return doc, nil
+}
+
+func convertOperation(src map[string]any, consumes, produces []string) map[string]any {
+	op := map[string]any{}
+	for _, key := range []string{"tags", "summary", "description", "operationId", "deprecated"} {
+		if v, ok := src[key]; ok {
+			op[key] = v
+		}
+	}
+	if v := stringList(src["consumes"]); len(v) > 0 {
+		consumes = v
+	}
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	if v := stringList(src["produces"]); len(v) > 0 {
+		produces = v
+	}
+	if len(produces) == 0 {
+		produces = []string{"application/json"}
+	}
+
+	params := []any{}
+	form := map[string]any{}
+	formRequired := []any{}
+	srcParams, _ := src["parameters"].([]any)
+	for _, p := range srcParams {
+		param, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch param["in"] {
+		case "body":
+			content := map[string]any{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]any{"schema": convertSchema(param["schema"])}
+			}
+			body := map[string]any{
+				"required": param["required"] == true,
+				"content":  content,
+			}
+			if v, ok := param["description"]; ok {
+				body["description"] = v
+			}
+			op["requestBody"] = body
+		case "formData":
+			schema := convertSchema(paramSchema(param))
+			if v, ok := param["description"]; ok {
+				schema.(map[string]any)["description"] = v
+			}
+			form[param["name"].(string)] = schema
+			if param["required"] == true {
+				formRequired = append(formRequired, param["name"])
+			}
+		default:
+			converted := map[string]any{
+				"name":     param["name"],
+				"in":       param["in"],
+				"required": param["required"] == true,
+				"schema":   convertSchema(paramSchema(param)),
+			}
+			if v, ok := param["description"]; ok {
+				converted["description"] = v
+			}
+			if param["collectionFormat"] == "multi" {
+				converted["explode"] = true
+			} else if param["type"] == "array" {
+				converted["explode"] = false
+			}
+			params = append(params, converted)
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if len(form) > 0 {
+		mediaType := "multipart/form-data"
+		for _, v := range consumes {
+			if v == "application/x-www-form-urlencoded" {
+				mediaType = v
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		op["requestBody"] = map[string]any{
+			"required": len(formRequired) > 0,
+			"content":  map[string]any{mediaType: map[string]any{"schema": schema}},
+		}
+	}
+
+	responses := map[string]any{}
+	srcResponses, _ := src["responses"].(map[string]any)
+	for code, r := range srcResponses {
+		resp, _ := r.(map[string]any)
+		converted := map[string]any{"description": resp["description"]}
+		if converted["description"] == nil {
+			converted["description"] = ""
+		}
+		if schema, ok := resp["schema"]; ok {
+			converted["content"] = responseContent(code, convertSchema(schema), produces)
+		}
+		responses[code] = converted
+	}
+	op["responses"] = responses
+	return op
+}
+
+// responseContent lists the media types of a response. Success responses
+// come in every produced type, error responses are always sent as JSON
+// whatever the operation produces.
+func responseContent(code string, schema any, produces []string) map[string]any {
+	content := map[string]any{}
+	if !strings.HasPrefix(code, "2") {
+		content["application/json"] = map[string]any{"schema": schema}
+		return content
+	}
+	for _, mediaType := range produces {
+		content[mediaType] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+// paramSchema collects the schema keywords Swagger 2.0 keeps inline on non
+// body parameters.
+func paramSchema(param map[string]any) map[string]any {
+	schema := map[string]any{}
+	for _, key := range []string{"type", "format", "items", "enum", "default", "minimum", "maximum",
+		"exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength", "pattern", "minItems", "maxItems",
+		"uniqueItems", "x-nullable"} {
+		if v, ok := param[key]; ok {
+			schema[key] = v
+		}
+	}
+	return schema
+}
+
+// convertSchema rewrites definition references and the Swagger extensions
+// that JSON Schema 2020-12 expresses differently.
+func convertSchema(v any) any {
+	src, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	schema := map[string]any{}
+	for key, value := range src {
+		switch key {
+		case "$ref":
+			ref, _ := value.(string)
+			schema[key] = schemasRef + strings.TrimPrefix(ref, definitionsRef)
+		case "properties", "patternProperties":
+			props := map[string]any{}
+			for name, prop := range value.(map[string]any) {
+				props[name] = convertSchema(prop)
+			}
+			schema[key] = props
+		case "items", "additionalProperties", "not":
+			schema[key] = convertSchema(value)
+		case "allOf", "anyOf", "oneOf":
+			list := []any{}
+			for _, item := range value.([]any) {
+				list = append(list, convertSchema(item))
+			}
+			schema[key] = list
+		case "x-nullable":
+		default:
+			schema[key] = value
+		}
+	}
+
+	if schema["type"] == "file" {
+		schema["type"] = "string"
+		schema["format"] = "binary"
+	}
+	// A 2020-12 exclusive bound is the number itself, not a flag.
+	for bound, limit := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		if flag, ok := schema[bound].(bool); ok {
+			delete(schema, bound)
+			if flag {
+				schema[bound] = schema[limit]
+				delete(schema, limit)
+			}
+		}
+	}
+	if src["x-nullable"] == true {
+		if t, ok := schema["type"].(string); ok {
+			schema["type"] = []any{t, "null"}
+			if enum, ok := schema["enum"].([]any); ok {
+				schema["enum"] = append(enum, nil)
+			}
+		} else {
+			return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+		}
+	}
+	return schema
+}
+
+func stringList(v any) (list []string) {
+	items, _ := v.([]any)
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const maxSampleDepth = 8
+
+// SampleRequest builds a request for the operation from the spec alone. Path
+// parameters get placeholders that match no resource, required query and
+// header parameters get placeholders of their type and a JSON body is put
+// together from the schema examples. Form bodies are left out.
+func (s *Spec) SampleRequest(op *Operation) (req *http.Request, err error) {
+	path := op.Path
+	query := url.Values{}
+	header := http.Header{}
+	params, _ := op.src["parameters"].([]any)
+	for _, p := range params {
+		param := p.(map[string]any)
+		name, _ := param["name"].(string)
+		schema, _ := param["schema"].(map[string]any)
+		switch param["in"] {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(placeholder(schema, "0")))
+		case "query":
+			if param["required"] == true {
+				query.Set(name, placeholder(schema, "1"))
+			}
+		case "header":
+			if param["required"] == true {
+				header.Set(name, placeholder(schema, "1"))
+			}
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var body []byte
+	if requestBody, ok := op.src["requestBody"].(map[string]any); ok {
+		content, _ := requestBody["content"].(map[string]any)
+		for mediaType, media := range content {
+			if !isJSON(mediaType) {
+				continue
+			}
+			schema, _ := media.(map[string]any)["schema"].(map[string]any)
+			if body, err = json.Marshal(s.sample(schema, 0)); err != nil {
+				return req, err
+			}
+			header.Set("Content-Type", mediaType)
+		}
+	}
+	req, err = http.NewRequest(op.Method, path, bytes.NewReader(body))
+	if err != nil {
+		return req, err
+	}
+	req.Header = header
+	return req, nil
+}
+
+func (s *Spec) sample(schema map[string]any, depth int) any {
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if depth > maxSampleDepth {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		components, _ := s.doc["components"].(map[string]any)
+		schemas, _ := components["schemas"].(map[string]any)
+		target, _ := schemas[strings.TrimPrefix(ref, schemasRef)].(map[string]any)
+		return s.sample(target, depth+1)
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		merged := map[string]any{}
+		for _, item := range all {
+			if part, ok := s.sample(item.(map[string]any), depth+1).(map[string]any); ok {
+				for k, v := range part {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if list, ok := schema[key].([]any); ok && len(list) > 0 {
+			return s.sample(list[0].(map[string]any), depth+1)
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	switch schemaType(schema) {
+	case "object":
+		object := map[string]any{}
+		props, _ := schema["properties"].(map[string]any)
+		for name, prop := range props {
+			object[name] = s.sample(prop.(map[string]any), depth+1)
+		}
+		return object
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		return []any{s.sample(items, depth+1)}
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	case "string":
+		return "string"
+	}
+	return nil
+}
+
+func placeholder(schema map[string]any, number string) string {
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		b, _ := json.Marshal(enum[0])
+		return strings.Trim(string(b), `"`)
+	}
+	switch schemaType(schema) {
+	case "integer", "number":
+		return number
+	case "boolean":
+		return "false"
+	}
+	return "x"
+}
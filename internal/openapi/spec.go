@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const documentURL = "openapi.json"
+
+// Spec is an OpenAPI 3.1 document with the schemas of its operations compiled
+// for validation.
+type Spec struct {
+	doc        map[string]any
+	raw        []byte
+	operations map[string]*Operation
+}
+
+type Operation struct {
+	Method     string
+	Path       string
+	Parameters []Parameter
+	Body       *Body
+	Responses  map[string]Response
+	src        map[string]any
+}
+
+type Parameter struct {
+	Name     string
+	In       string
+	Required bool
+	Type     string
+	Items    string
+	Explode  bool
+	schema   *jsonschema.Schema
+}
+
+type Body struct {
+	Required bool
+	Content  map[string]*jsonschema.Schema
+	Fields   []string
+}
+
+type Response struct {
+	Content map[string]*jsonschema.Schema
+}
+
+// Load converts the Swagger 2.0 document and compiles its schemas.
+func Load(swagger []byte) (spec *Spec, err error) {
+	doc, err := Convert(swagger)
+	if err != nil {
+		return spec, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return spec, fmt.Errorf("openapi error, failed to encode document: %v", err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return spec, fmt.Errorf("openapi error, failed to decode document: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	if err = compiler.AddResource(documentURL, value); err != nil {
+		return spec, fmt.Errorf("openapi error, failed to add document: %v", err)
+	}
+
+	spec = &Spec{doc: doc, raw: raw, operations: map[string]*Operation{}}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		ops, _ := item.(map[string]any)
+		for method, v := range ops {
+			pointer := "/paths/" + escape(path) + "/" + method
+			op, err := compileOperation(compiler, pointer, v.(map[string]any))
+			if err != nil {
+				return nil, fmt.Errorf("openapi error, %s %s: %v", strings.ToUpper(method), path, err)
+			}
+			op.Method = strings.ToUpper(method)
+			op.Path = path
+			spec.operations[op.Method+" "+path] = op
+		}
+	}
+	return spec, nil
+}
+
+func compileOperation(compiler *jsonschema.Compiler, pointer string, src map[string]any) (op *Operation, err error) {
+	op = &Operation{Responses: map[string]Response{}, src: src}
+	params, _ := src["parameters"].([]any)
+	for i, p := range params {
+		param := p.(map[string]any)
+		schema, _ := param["schema"].(map[string]any)
+		converted := Parameter{
+			Name:     param["name"].(string),
+			In:       param["in"].(string),
+			Required: param["required"] == true,
+			Type:     schemaType(schema),
+			Explode:  param["explode"] != false,
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			converted.Items = schemaType(items)
+		}
+		converted.schema, err = compiler.Compile(fmt.Sprintf("%s#%s/parameters/%d/schema", documentURL, pointer, i))
+		if err != nil {
+			return op, err
+		}
+		op.Parameters = append(op.Parameters, converted)
+	}
+
+	if body, ok := src["requestBody"].(map[string]any); ok {
+		op.Body = &Body{Required: body["required"] == true}
+		op.Body.Content, err = compileContent(compiler, pointer+"/requestBody", body)
+		if err != nil {
+			return op, err
+		}
+		// Form bodies are only checked for their required fields.
+		for mediaType, media := range body["content"].(map[string]any) {
+			if isJSON(mediaType) {
+				continue
+			}
+			schema, _ := media.(map[string]any)["schema"].(map[string]any)
+			op.Body.Fields = append(op.Body.Fields, stringList(schema["required"])...)
+		}
+	}
+
+	responses, _ := src["responses"].(map[string]any)
+	for code, r := range responses {
+		content, err := compileContent(compiler, pointer+"/responses/"+code, r.(map[string]any))
+		if err != nil {
+			return op, err
+		}
+		op.Responses[code] = Response{Content: content}
+	}
+	return op, nil
+}
+
+func compileContent(compiler *jsonschema.Compiler, pointer string, src map[string]any) (content map[string]*jsonschema.Schema, err error) {
+	content = map[string]*jsonschema.Schema{}
+	media, _ := src["content"].(map[string]any)
+	for mediaType, v := range media {
+		if _, ok := v.(map[string]any)["schema"]; !ok || !isJSON(mediaType) {
+			content[mediaType] = nil
+			continue
+		}
+		content[mediaType], err = compiler.Compile(documentURL + "#" + pointer + "/content/" + escape(mediaType) + "/schema")
+		if err != nil {
+			return content, err
+		}
+	}
+	return content, nil
+}
+
+// JSON returns the document as served at /openapi.json.
+func (s *Spec) JSON() []byte {
+	return s.raw
+}
+
+// Operation finds the operation of a route, path parameters are written the
+// gin way as ":id" or the OpenAPI way as "{id}".
+func (s *Spec) Operation(method, route string) (op *Operation, ok bool) {
+	op, ok = s.operations[method+" "+PathTemplate(route)]
+	return op, ok
+}
+
+// Operations lists the "METHOD /path" keys of all documented operations.
+func (s *Spec) Operations() (keys []string) {
+	for key := range s.operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PathTemplate rewrites gin path parameters as OpenAPI path templates.
+func PathTemplate(route string) string {
+	parts := strings.Split(route, "/")
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			parts[i] = "{" + name + "}"
+		} else if name, ok := strings.CutPrefix(part, "*"); ok {
+			parts[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if v != "null" {
+				s, _ := v.(string)
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func isJSON(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// escape encodes a key as a JSON pointer token.
+func escape(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,236 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// maxFormMemory matches the multipart memory limit of gin.
+const maxFormMemory = 32 << 20
+
+// ValidationError lists every way a request or response differs from the
+// spec.
+type ValidationError struct {
+	Problems []string
+	// Path is set when a path parameter does not match, which the handlers
+	// answer like an unknown resource.
+	Path bool
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// ValidateRequest checks the parameters and the body of a request. The body
+// is read and put back, so handlers can still bind it.
+func (op *Operation) ValidateRequest(r *http.Request, pathParams map[string]string) error {
+	verr := &ValidationError{}
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		where := param.In + " parameter " + param.Name
+		var values []string
+		switch param.In {
+		case "path":
+			values = []string{pathParams[param.Name]}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		}
+		// Handlers read params with c.Query and friends, which do not tell
+		// an empty value from a missing one.
+		if len(values) == 0 || values[0] == "" {
+			if param.Required {
+				verr.Problems = append(verr.Problems, where+": is required")
+			}
+			continue
+		}
+		count := len(verr.Problems)
+		if value, err := param.coerce(values); err != nil {
+			verr.Problems = append(verr.Problems, where+": "+err.Error())
+		} else {
+			verr.Problems = append(verr.Problems, describe(where, param.schema.Validate(value))...)
+		}
+		if param.In == "path" && len(verr.Problems) > count {
+			verr.Path = true
+		}
+	}
+	if op.Body != nil {
+		verr.Problems = append(verr.Problems, op.validateBody(r)...)
+	}
+	if len(verr.Problems) > 0 {
+		return verr
+	}
+	return nil
+}
+
+func (op *Operation) validateBody(r *http.Request) (problems []string) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" || mediaType == "application/x-www-form-urlencoded" {
+		if _, ok := op.Body.Content[mediaType]; !ok {
+			return []string{fmt.Sprintf("body: content type %s is not accepted", mediaType)}
+		}
+		if err := r.ParseMultipartForm(maxFormMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return []string{"body: " + err.Error()}
+		}
+		for _, field := range op.Body.Fields {
+			if r.FormValue(field) != "" {
+				continue
+			}
+			if r.MultipartForm != nil && len(r.MultipartForm.File[field]) > 0 {
+				continue
+			}
+			problems = append(problems, "body: form field "+field+" is required")
+		}
+		return problems
+	}
+
+	// Clients do not always send a Content-Type with JSON, so any other
+	// body is read as JSON when the operation takes JSON.
+	schema, ok := op.jsonBody()
+	if !ok && mediaType == "" && op.Body.Required {
+		return []string{"body: is required"}
+	}
+	if !ok {
+		return []string{fmt.Sprintf("body: content type %q is not accepted", mediaType)}
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return []string{"body: " + err.Error()}
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.Body.Required {
+			return []string{"body: is required"}
+		}
+		return nil
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []string{"body: invalid JSON"}
+	}
+	if schema == nil {
+		return nil
+	}
+	return describe("body", schema.Validate(value))
+}
+
+func (op *Operation) jsonBody() (schema *jsonschema.Schema, ok bool) {
+	for mediaType, schema := range op.Body.Content {
+		if isJSON(mediaType) {
+			return schema, true
+		}
+	}
+	return nil, false
+}
+
+// ValidateResponse checks that the status is documented for the operation
+// and that the body has a documented content type and matches its schema.
+func (op *Operation) ValidateResponse(status int, header http.Header, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return &ValidationError{Problems: []string{fmt.Sprintf("status %d is not documented", status)}}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if len(resp.Content) == 0 {
+		return &ValidationError{Problems: []string{fmt.Sprintf("status %d is documented without a body", status)}}
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	schema, ok := resp.Content[mediaType]
+	if !ok {
+		return &ValidationError{Problems: []string{
+			fmt.Sprintf("content type %q is not documented for status %d", mediaType, status),
+		}}
+	}
+	if schema == nil {
+		return nil
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return &ValidationError{Problems: []string{"body: invalid JSON"}}
+	}
+	if problems := describe("body", schema.Validate(value)); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// coerce turns the raw strings of a parameter into the JSON value its schema
+// describes.
+func (p Parameter) coerce(values []string) (value any, err error) {
+	if p.Type != "array" {
+		return coerceValue(values[0], p.Type)
+	}
+	if !p.Explode {
+		values = strings.Split(values[0], ",")
+	}
+	list := make([]any, 0, len(values))
+	for _, s := range values {
+		v, err := coerceValue(s, p.Items)
+		if err != nil {
+			return value, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func coerceValue(s string, t string) (value any, err error) {
+	switch t {
+	case "integer", "number":
+		if _, err = strconv.ParseFloat(s, 64); err != nil {
+			return value, fmt.Errorf("got %q, want %s", s, t)
+		}
+		return json.Number(s), nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return value, fmt.Errorf("got %q, want boolean", s)
+		}
+		return b, nil
+	}
+	return s, nil
+}
+
+// describe flattens a schema validation error into one line per failed
+// keyword, prefixed with where the value came from.
+func describe(where string, err error) (problems []string) {
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		if err != nil {
+			problems = append(problems, where+": "+err.Error())
+		}
+		return problems
+	}
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		location := ""
+		if len(e.InstanceLocation) > 0 {
+			location = "/" + strings.Join(e.InstanceLocation, "/")
+		}
+		problems = append(problems, fmt.Sprintf("%s%s: %s", where, location, e.BasicOutput().Error))
+	}
+	walk(verr)
+	return problems
+}
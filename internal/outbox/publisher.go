@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"main/internal/config"
+	"main/internal/model"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	SinkStdout = "stdout"
+	SinkFile   = "file"
+	SinkNATS   = "nats"
+	SinkKafka  = "kafka"
+)
+
+// Publisher sends events to a sink. Publish returns once the sink has
+// accepted the event; an event may be published more than once, consumers
+// drop duplicates by its id.
+type Publisher interface {
+	Publish(ctx context.Context, event model.OutboxEvent) error
+	Close() error
+}
+
+func NewPublisher(cfg *config.Config) (Publisher, error) {
+	switch cfg.Outbox.Sink {
+	case SinkStdout:
+		return &writerPublisher{w: os.Stdout}, nil
+	case SinkFile:
+		f, err := os.OpenFile(cfg.Outbox.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return nil, err
+		}
+		return &writerPublisher{w: f, file: f}, nil
+	case SinkNATS:
+		return newNATSPublisher(cfg.Outbox.NATSURL, cfg.Outbox.NATSSubject)
+	case SinkKafka:
+		return newKafkaPublisher(cfg.Outbox.KafkaBrokers, cfg.Outbox.KafkaTopic), nil
+	}
+	return nil, fmt.Errorf("unknown outbox sink %q, %s, %s, %s or %s required",
+		cfg.Outbox.Sink, SinkStdout, SinkFile, SinkNATS, SinkKafka)
+}
+
+// writerPublisher writes one JSON event per line.
+type writerPublisher struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+}
+
+func (p *writerPublisher) Publish(ctx context.Context, event model.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err = p.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if p.file != nil {
+		return p.file.Sync()
+	}
+	return nil
+}
+
+func (p *writerPublisher) Close() error {
+	if p.file != nil {
+		return p.file.Close()
+	}
+	return nil
+}
+
+// natsPublisher publishes to a JetStream stream and waits for its ack. The
+// event id is the message id, so the stream drops duplicates within its
+// deduplication window.
+type natsPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+func newNATSPublisher(url, subject string) (*natsPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("sub_service outbox relay"))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to nats error: %v", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("opening jetstream error: %v", err)
+	}
+	return &natsPublisher{conn: conn, js: js, subject: subject}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, event model.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(p.subject + "." + event.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatInt(event.Id, 10))
+	msg.Header.Set("Schema-Version", strconv.Itoa(event.SchemaVersion))
+	_, err = p.js.PublishMsg(msg, nats.Context(ctx))
+	return err
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// kafkaPublisher keys messages by subscription id, so the events of a
+// subscription land on one partition in order.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func newKafkaPublisher(brokers []string, topic string) *kafkaPublisher {
+	return &kafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, event model.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.Itoa(event.SubscriptionId)),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event-id", Value: []byte(strconv.FormatInt(event.Id, 10))},
+			{Key: "event-type", Value: []byte(event.Type)},
+			{Key: "schema-version", Value: []byte(strconv.Itoa(event.SchemaVersion))},
+		},
+	})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"context"
+	"main/internal/config"
+	"main/internal/db"
+	"main/internal/model"
+	"main/pkg/logger"
+	"time"
+)
+
+// Relay publishes the outbox events in the order they were written. When an
+// event fails, the later events of the same subscription wait for the next
+// round, so each subscription's events reach the sink in order while the
+// others keep flowing.
+type Relay struct {
+	storage      db.Storage
+	publisher    Publisher
+	logger       *logger.Logger
+	pollInterval time.Duration
+	batchSize    int
+	retention    time.Duration
+}
+
+func NewRelay(storage db.Storage, publisher Publisher, cfg *config.Config, logger *logger.Logger) *Relay {
+	return &Relay{
+		storage:      storage,
+		publisher:    publisher,
+		logger:       logger,
+		pollInterval: cfg.Outbox.PollInterval,
+		batchSize:    max(cfg.Outbox.BatchSize, 1),
+		retention:    cfg.Outbox.Retention,
+	}
+}
+
+// Run relays events until the context is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		r.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain relays full batches until the outbox is empty or a round publishes
+// nothing, then removes events published before the retention period.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := r.storage.RelayOutbox(ctx, r.batchSize, func(events []model.OutboxEvent) []int64 {
+			return r.publish(ctx, events)
+		})
+		if err != nil {
+			r.logger.Errorln(err)
+			return
+		}
+		if count < r.batchSize {
+			break
+		}
+	}
+	if r.retention > 0 && ctx.Err() == nil {
+		if _, err := r.storage.PruneOutbox(ctx, time.Now().Add(-r.retention)); err != nil {
+			r.logger.Errorln(err)
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, events []model.OutboxEvent) (published []int64) {
+	blocked := make(map[int]bool)
+	for _, event := range events {
+		if blocked[event.SubscriptionId] || ctx.Err() != nil {
+			continue
+		}
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			r.logger.Errorf("publishing outbox event %d of sub %d error: %v", event.Id, event.SubscriptionId, err)
+			blocked[event.SubscriptionId] = true
+			continue
+		}
+		published = append(published, event.Id)
+	}
+	return published
+}
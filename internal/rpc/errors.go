@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"main/internal/subscription"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError maps the service error kinds to gRPC codes the same way the
+// REST handlers map them to HTTP statuses. The text of unexpected errors is
+// hidden, it may contain database details.
+func (s *Server) statusError(err error) error {
+	switch {
+	case errors.Is(err, subscription.ErrValidation), errors.Is(err, subscription.ErrInvalidQuery):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, subscription.ErrNotFound):
+		return status.Error(codes.NotFound, "sub not found")
+	case errors.Is(err, subscription.ErrOverlap):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		s.logger.Errorln(err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,68 @@
+package rpc
+
+import (
+	"context"
+	"main/internal/rpc/subscriptionpb"
+	"main/internal/subscription"
+	"main/pkg/logger"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Server struct {
+	subscriptionpb.UnimplementedSubscriptionServiceServer
+	subService subscription.SubscriptionInterface
+	logger     *logger.Logger
+}
+
+// NewServer returns a gRPC server with the subscription, health and
+// reflection services registered. The health service reports SERVING for
+// the whole server and for subscription.v1.SubscriptionService.
+func NewServer(s subscription.SubscriptionInterface, logger *logger.Logger) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(recoveryInterceptor(logger), loggingInterceptor(logger)))
+	subscriptionpb.RegisterSubscriptionServiceServer(srv, &Server{
+		subService: s,
+		logger:     logger,
+	})
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthSrv.SetServingStatus(subscriptionpb.SubscriptionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	reflection.Register(srv)
+	return srv
+}
+
+// recoveryInterceptor turns a panic in a handler into an Internal error, so
+// one bad request does not take the whole server down with it.
+func recoveryInterceptor(logger *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("grpc request to %s panicked: %v\n%s", info.FullMethod, r, debug.Stack())
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return next(ctx, req)
+	}
+}
+
+func loggingInterceptor(logger *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		logger.Infof("grpc request to %s", info.FullMethod)
+		resp, err := next(ctx, req)
+		if err != nil {
+			logger.Infof("grpc request to %s failed: %s", info.FullMethod, status.Code(err))
+			return resp, err
+		}
+		logger.Infoln("request completed successfully")
+		return resp, nil
+	}
+}
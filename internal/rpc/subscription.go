@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"context"
+	"main/internal/model"
+	"main/internal/rpc/subscriptionpb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (s *Server) CreateSubscription(ctx context.Context, req *subscriptionpb.CreateSubscriptionRequest) (*subscriptionpb.Subscription, error) {
+	sub, err := toSub(req.GetSubscription())
+	if err != nil {
+		return nil, err
+	}
+	sub.Id = 0
+	id, _, err := s.subService.Save(ctx, sub)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	created, err := s.subService.Load(ctx, id)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return fromSub(created), nil
+}
+
+func (s *Server) GetSubscription(ctx context.Context, req *subscriptionpb.GetSubscriptionRequest) (*subscriptionpb.Subscription, error) {
+	id, err := subId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.subService.Load(ctx, id)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return fromSub(sub), nil
+}
+
+func (s *Server) UpdateSubscription(ctx context.Context, req *subscriptionpb.UpdateSubscriptionRequest) (*subscriptionpb.Subscription, error) {
+	sub, err := toSub(req.GetSubscription())
+	if err != nil {
+		return nil, err
+	}
+	if sub.Id, err = subId(req.GetSubscription().GetId()); err != nil {
+		return nil, err
+	}
+	if _, err = s.subService.Update(ctx, sub); err != nil {
+		return nil, s.statusError(err)
+	}
+	updated, err := s.subService.Load(ctx, sub.Id)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return fromSub(updated), nil
+}
+
+func (s *Server) DeleteSubscription(ctx context.Context, req *subscriptionpb.DeleteSubscriptionRequest) (*emptypb.Empty, error) {
+	id, err := subId(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.subService.Delete(ctx, id); err != nil {
+		return nil, s.statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListSubscriptions(ctx context.Context, req *subscriptionpb.ListSubscriptionsRequest) (*subscriptionpb.ListSubscriptionsResponse, error) {
+	filter := model.ListFilter{
+		ServiceName: req.GetServiceName(),
+		Limit:       int(req.GetLimit()),
+		Offset:      int(req.GetOffset()),
+	}
+	if req.GetUserId() != "" {
+		userId, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "wrong uuid")
+		}
+		filter.UserId = userId
+	}
+	page, err := s.subService.LoadPage(ctx, filter)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	resp := &subscriptionpb.ListSubscriptionsResponse{
+		Subscriptions: make([]*subscriptionpb.Subscription, 0, len(page.Items)),
+		Limit:         int32(page.Limit),
+		Offset:        int32(page.Offset),
+		Total:         int32(page.Total),
+	}
+	for _, sub := range page.Items {
+		resp.Subscriptions = append(resp.Subscriptions, fromSub(sub))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetCost(ctx context.Context, req *subscriptionpb.GetCostRequest) (*subscriptionpb.GetCostResponse, error) {
+	data := model.CostRequest{
+		ServiceName: req.GetServiceName(),
+		StartDate:   req.GetStartDate(),
+		EndDate:     req.GetEndDate(),
+	}
+	if data.ServiceName == "" {
+		return nil, status.Error(codes.InvalidArgument, "service name is required")
+	}
+	userId, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "wrong uuid")
+	}
+	data.UserId = userId
+	cost, err := s.subService.Cost(ctx, data)
+	if err != nil {
+		return nil, s.statusError(err)
+	}
+	return &subscriptionpb.GetCostResponse{Cost: int64(cost)}, nil
+}
+
+func subId(id int64) (int, error) {
+	if id <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "incorrect sub id")
+	}
+	return int(id), nil
+}
+
+func toSub(pb *subscriptionpb.Subscription) (sub model.Subscription, err error) {
+	if pb == nil {
+		return sub, status.Error(codes.InvalidArgument, "subscription is required")
+	}
+	sub = model.Subscription{
+		Id:            int(pb.GetId()),
+		ServiceName:   pb.GetServiceName(),
+		Price:         int(pb.GetPrice()),
+		StartDate:     pb.GetStartDate(),
+		EndDate:       pb.GetEndDate(),
+		BillingPeriod: pb.GetBillingPeriod(),
+		TrialEndDate:  pb.GetTrialEndDate(),
+	}
+	if sub.UserId, err = uuid.Parse(pb.GetUserId()); err != nil {
+		return sub, status.Error(codes.InvalidArgument, "wrong uuid")
+	}
+	return sub, nil
+}
+
+func fromSub(sub model.Subscription) *subscriptionpb.Subscription {
+	return &subscriptionpb.Subscription{
+		Id:            int64(sub.Id),
+		ServiceName:   sub.ServiceName,
+		Price:         int64(sub.Price),
+		UserId:        sub.UserId.String(),
+		StartDate:     sub.StartDate,
+		EndDate:       sub.EndDate,
+		BillingPeriod: sub.BillingPeriod,
+		TrialEndDate:  sub.TrialEndDate,
+	}
+}
@@ -0,0 +1,169 @@
+package seed
+
+import (
+	"fmt"
+	"main/internal/model"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	historyMonths  = 36
+	yearlyShare    = 0.2
+	endedShare     = 0.35
+	trialShare     = 0.1
+	duplicateShare = 0.05
+)
+
+type service struct {
+	name     string
+	weight   float64
+	minPrice int
+	maxPrice int
+	yearly   bool
+}
+
+// Monthly prices in rubles, weights follow rough market popularity.
+var services = []service{
+	{name: "Yandex Plus", weight: 30, minPrice: 299, maxPrice: 449, yearly: true},
+	{name: "Kinopoisk", weight: 14, minPrice: 269, maxPrice: 399, yearly: true},
+	{name: "VK Music", weight: 12, minPrice: 169, maxPrice: 249},
+	{name: "Okko", weight: 9, minPrice: 199, maxPrice: 399, yearly: true},
+	{name: "IVI", weight: 8, minPrice: 199, maxPrice: 399, yearly: true},
+	{name: "Telegram Premium", weight: 7, minPrice: 299, maxPrice: 349, yearly: true},
+	{name: "Wink", weight: 6, minPrice: 249, maxPrice: 599},
+	{name: "Start", weight: 5, minPrice: 199, maxPrice: 299},
+	{name: "Amediateka", weight: 4, minPrice: 599, maxPrice: 799, yearly: true},
+	{name: "Litres", weight: 4, minPrice: 399, maxPrice: 449},
+	{name: "Spotify", weight: 3, minPrice: 199, maxPrice: 299},
+	{name: "YouTube Premium", weight: 3, minPrice: 199, maxPrice: 299, yearly: true},
+	{name: "iCloud+", weight: 3, minPrice: 59, maxPrice: 749},
+	{name: "Google One", weight: 2, minPrice: 139, maxPrice: 699, yearly: true},
+	{name: "ChatGPT Plus", weight: 2, minPrice: 1990, maxPrice: 2290},
+	{name: "PlayStation Plus", weight: 1, minPrice: 549, maxPrice: 1299, yearly: true},
+}
+
+type Options struct {
+	Users         int
+	Subscriptions int
+	Seed          uint64
+	Until         time.Time
+}
+
+type Generator struct {
+	opts  Options
+	rng   *rand.Rand
+	users []uuid.UUID
+	total float64
+	// recent keeps a few generated subs per user to derive overlapping ones.
+	recent map[uuid.UUID][]model.SubscriptionDTO
+	made   int
+}
+
+// NewGenerator returns a generator whose output depends only on the options:
+// the same seed, counts and until month always produce the same rows.
+func NewGenerator(opts Options) (*Generator, error) {
+	if opts.Users <= 0 {
+		return nil, fmt.Errorf("users must be positive")
+	}
+	if opts.Subscriptions < 0 {
+		return nil, fmt.Errorf("subscriptions must not be negative")
+	}
+	g := &Generator{
+		opts:   opts,
+		rng:    rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)),
+		recent: make(map[uuid.UUID][]model.SubscriptionDTO),
+	}
+	g.opts.Until = time.Date(opts.Until.Year(), opts.Until.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for range opts.Users {
+		var id uuid.UUID
+		for i := range id {
+			id[i] = byte(g.rng.UintN(256))
+		}
+		id[6] = id[6]&0x0f | 0x40
+		id[8] = id[8]&0x3f | 0x80
+		g.users = append(g.users, id)
+	}
+	for _, s := range services {
+		g.total += s.weight
+	}
+	return g, nil
+}
+
+// Next returns up to n new subscriptions, an empty slice once all of them
+// have been generated.
+func (g *Generator) Next(n int) (list []model.SubscriptionDTO) {
+	for ; n > 0 && g.made < g.opts.Subscriptions; n-- {
+		list = append(list, g.subscription())
+		g.made++
+	}
+	return list
+}
+
+func (g *Generator) subscription() model.SubscriptionDTO {
+	// A few heavy users own most subscriptions.
+	userId := g.users[int(math.Pow(g.rng.Float64(), 2)*float64(len(g.users)))]
+	if prev := g.recent[userId]; len(prev) > 0 && g.rng.Float64() < duplicateShare {
+		return g.duplicate(prev[g.rng.IntN(len(prev))])
+	}
+
+	s := g.service()
+	dto := model.SubscriptionDTO{
+		ServiceName:   s.name,
+		UserId:        userId,
+		Price:         g.price(s),
+		BillingPeriod: model.BillingMonthly,
+		StartDate:     g.opts.Until.AddDate(0, -g.rng.IntN(historyMonths), 0),
+	}
+	if s.yearly && g.rng.Float64() < yearlyShare {
+		dto.BillingPeriod = model.BillingYearly
+		dto.Price = roundPrice(dto.Price * 10)
+	}
+	if g.rng.Float64() < endedShare {
+		dto.EndDate = dto.StartDate.AddDate(0, 1+g.rng.IntN(24), 0)
+	}
+	if g.rng.Float64() < trialShare {
+		dto.TrialEndDate = dto.StartDate.AddDate(0, 1, 0)
+	}
+	prev := append(g.recent[userId], dto)
+	if len(prev) > 4 {
+		prev = prev[1:]
+	}
+	g.recent[userId] = prev
+	return dto
+}
+
+func (g *Generator) duplicate(dto model.SubscriptionDTO) model.SubscriptionDTO {
+	end := dto.EndDate
+	if end.IsZero() || end.After(g.opts.Until) {
+		end = g.opts.Until
+	}
+	months := int(end.Sub(dto.StartDate).Hours()/24/30) + 1
+	dto.StartDate = dto.StartDate.AddDate(0, g.rng.IntN(months), 0)
+	dto.EndDate = time.Time{}
+	dto.TrialEndDate = time.Time{}
+	return dto
+}
+
+func (g *Generator) service() service {
+	r := g.rng.Float64() * g.total
+	for _, s := range services {
+		if r < s.weight {
+			return s
+		}
+		r -= s.weight
+	}
+	return services[len(services)-1]
+}
+
+func (g *Generator) price(s service) int {
+	return roundPrice(s.minPrice + g.rng.IntN(s.maxPrice-s.minPrice+1))
+}
+
+// roundPrice makes prices end with 9 the way shops list them.
+func roundPrice(price int) int {
+	return price/10*10 + 9
+}
@@ -0,0 +1,174 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	csvDateColumns        = []string{"date", "transaction_date", "posted_date", "posting_date", "booking_date", "value_date"}
+	csvAmountColumns      = []string{"amount", "sum", "value", "transaction_amount"}
+	csvDebitColumns       = []string{"debit", "withdrawal", "paid_out"}
+	csvDescriptionColumns = []string{"description", "merchant", "payee", "name", "details", "memo", "narrative"}
+	csvDateLayouts        = []string{"2006-01-02", "02.01.2006", "01/02/2006", "2006/01/02", "02-01-2006", "2006-01-02 15:04:05"}
+)
+
+type csvColumns struct {
+	date        int
+	amount      int
+	debit       int
+	description int
+}
+
+func ParseCSV(r io.Reader) (transactions []Transaction, err error) {
+	buffered := bufio.NewReader(io.LimitReader(r, maxStatementSize))
+	reader := csv.NewReader(buffered)
+	reader.Comma = csvDelimiter(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header error: %v", err)
+	}
+	columns, err := mapCSVColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv error: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		t, err := csvToTransaction(fields, columns)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+// csvDelimiter picks the semicolon when the header line has more of them than
+// commas, as in the exports of banks that write decimal commas.
+func csvDelimiter(r *bufio.Reader) rune {
+	head, _ := r.Peek(4096)
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	if bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func mapCSVColumns(header []string) (columns csvColumns, err error) {
+	columns = csvColumns{date: -1, amount: -1, debit: -1, description: -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		switch {
+		case columns.date < 0 && slices.Contains(csvDateColumns, name):
+			columns.date = i
+		case columns.amount < 0 && slices.Contains(csvAmountColumns, name):
+			columns.amount = i
+		case columns.debit < 0 && slices.Contains(csvDebitColumns, name):
+			columns.debit = i
+		case columns.description < 0 && slices.Contains(csvDescriptionColumns, name):
+			columns.description = i
+		}
+	}
+	if columns.date < 0 {
+		return columns, fmt.Errorf("statement date column is missing")
+	}
+	if columns.amount < 0 && columns.debit < 0 {
+		return columns, fmt.Errorf("statement amount column is missing")
+	}
+	if columns.description < 0 {
+		return columns, fmt.Errorf("statement description column is missing")
+	}
+	return columns, nil
+}
+
+func csvToTransaction(fields []string, columns csvColumns) (t Transaction, err error) {
+	get := func(i int) string {
+		if i < 0 || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+	t.Date, err = parseDate(get(columns.date))
+	if err != nil {
+		return t, err
+	}
+	if amount := get(columns.amount); amount != "" {
+		t.Amount, err = parseAmount(amount)
+	} else if debit := get(columns.debit); debit != "" {
+		t.Amount, err = parseAmount(debit)
+		t.Amount = -math.Abs(t.Amount)
+	}
+	if err != nil {
+		return t, err
+	}
+	t.Description = get(columns.description)
+	return t, nil
+}
+
+func parseDate(s string) (date time.Time, err error) {
+	for _, layout := range csvDateLayouts {
+		date, err = time.Parse(layout, s)
+		if err == nil {
+			return date, nil
+		}
+	}
+	return date, fmt.Errorf("invalid date: %q", s)
+}
+
+// parseAmount reads amounts like 1234.56, 1,234.56, 1.234,56, 1 234,56 and
+// 1,234.
+func parseAmount(s string) (amount float64, err error) {
+	clean := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '-' || r == '.' || r == ',' {
+			return r
+		}
+		return -1
+	}, s)
+	amount, err = strconv.ParseFloat(decimalPoint(clean), 64)
+	if err != nil {
+		return amount, fmt.Errorf("invalid amount: %q", s)
+	}
+	return amount, nil
+}
+
+// decimalPoint rewrites the amount with a dot as the decimal separator. The
+// last of dot and comma separates the decimals and the other one the
+// thousands, unless it appears more than once, as in 1.234.567. A lone comma
+// followed by exactly three digits, as in 1,234, separates the thousands.
+func decimalPoint(s string) string {
+	if i := strings.Index(s, ","); i >= 0 && strings.Count(s, ",") == 1 && !strings.Contains(s, ".") &&
+		len(s[i+1:]) == 3 && strings.Trim(s[i+1:], "0123456789") == "" {
+		return s[:i] + s[i+1:]
+	}
+	decimal, thousands := ".", ","
+	if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+		decimal, thousands = ",", "."
+	}
+	s = strings.ReplaceAll(s, thousands, "")
+	if strings.Count(s, decimal) > 1 {
+		return strings.ReplaceAll(s, decimal, "")
+	}
+	return strings.Replace(s, decimal, ".", 1)
+}
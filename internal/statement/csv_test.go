@@ -0,0 +1,87 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []Transaction
+	}{
+		{
+			name: "comma",
+			csv:  "Date,Description,Amount\n2025-07-01,Yandex Plus,-399.00\n2025-08-01,\"Netflix, Inc.\",\"-1,299.00\"\n",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Amount: -399, Description: "Yandex Plus"},
+				{Date: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), Amount: -1299, Description: "Netflix, Inc."},
+			},
+		},
+		{
+			name: "semicolon",
+			csv:  "Date;Description;Amount\n01.07.2025;Yandex Plus;-399,00\n01.08.2025;Netflix, Inc.;-1.299,00\n",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Amount: -399, Description: "Yandex Plus"},
+				{Date: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), Amount: -1299, Description: "Netflix, Inc."},
+			},
+		},
+		{
+			name: "semicolon with bom and debit column",
+			csv:  "\ufeffBooking date;Payee;Debit\n2025-07-01;Spotify;169,90\n",
+			want: []Transaction{
+				{Date: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Amount: -169.9, Description: "Spotify"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Date.Equal(tt.want[i].Date) || got[i].Amount != tt.want[i].Amount ||
+					got[i].Description != tt.want[i].Description {
+					t.Errorf("transaction %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1234.56", 1234.56},
+		{"1,234.56", 1234.56},
+		{"1.234,56", 1234.56},
+		{"1 234,56", 1234.56},
+		{"1.234.567", 1234567},
+		{"1,234,567", 1234567},
+		{"1,234", 1234},
+		{"-1,234", -1234},
+		{"12,50", 12.5},
+		{"1,2345", 1.2345},
+		{"399", 399},
+		{"-399.00 RUB", -399},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseAmount(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package statement
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+)
+
+const (
+	amountTolerance = 0.15
+	minConfidence   = 0.6
+)
+
+type period struct {
+	name       string
+	days       float64
+	minDays    float64
+	maxDays    float64
+	minCharges int
+}
+
+var periods = []period{
+	{name: PeriodMonthly, days: 30.4, minDays: 25, maxDays: 36, minCharges: 3},
+	{name: PeriodYearly, days: 365, minDays: 350, maxDays: 380, minCharges: 2},
+}
+
+type Recurring struct {
+	Merchant   string
+	Period     string
+	Amount     float64
+	First      time.Time
+	Last       time.Time
+	Charges    int
+	Confidence float64
+	Active     bool
+}
+
+func Detect(transactions []Transaction) (list []Recurring) {
+	statementEnd := time.Time{}
+	hasDebits := false
+	for _, t := range transactions {
+		if t.Date.After(statementEnd) {
+			statementEnd = t.Date
+		}
+		hasDebits = hasDebits || t.Amount < 0
+	}
+
+	groups := make(map[string][]Transaction)
+	for _, t := range transactions {
+		if t.Amount == 0 || (hasDebits && t.Amount > 0) {
+			continue
+		}
+		key := merchantKey(t.Description)
+		if key == "" {
+			continue
+		}
+		t.Amount = math.Abs(t.Amount)
+		groups[key] = append(groups[key], t)
+	}
+
+	for _, charges := range groups {
+		sort.Slice(charges, func(i, j int) bool {
+			return charges[i].Date.Before(charges[j].Date)
+		})
+		best := Recurring{}
+		for _, p := range periods {
+			r, ok := detectPeriod(charges, p, statementEnd)
+			if ok && r.Confidence > best.Confidence {
+				best = r
+			}
+		}
+		if best.Confidence >= minConfidence {
+			list = append(list, best)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Confidence != list[j].Confidence {
+			return list[i].Confidence > list[j].Confidence
+		}
+		return list[i].Merchant < list[j].Merchant
+	})
+	return list
+}
+
+func detectPeriod(charges []Transaction, p period, statementEnd time.Time) (r Recurring, ok bool) {
+	if len(charges) < p.minCharges {
+		return r, false
+	}
+	regular := 0
+	for i := 1; i < len(charges); i++ {
+		days := charges[i].Date.Sub(charges[i-1].Date).Hours() / 24
+		if days >= p.minDays && days <= p.maxDays {
+			regular++
+		}
+	}
+	amounts := make([]float64, 0, len(charges))
+	for _, c := range charges {
+		amounts = append(amounts, c.Amount)
+	}
+	median := medianOf(amounts)
+	similar := 0
+	for _, a := range amounts {
+		if math.Abs(a-median) <= median*amountTolerance {
+			similar++
+		}
+	}
+
+	last := charges[len(charges)-1]
+	r = Recurring{
+		Merchant:   merchantName(charges),
+		Period:     p.name,
+		Amount:     last.Amount,
+		First:      charges[0].Date,
+		Last:       last.Date,
+		Charges:    len(charges),
+		Confidence: float64(regular) / float64(len(charges)-1) * float64(similar) / float64(len(charges)),
+		Active:     statementEnd.Sub(last.Date).Hours()/24 <= p.days*1.5,
+	}
+	r.Confidence = math.Round(r.Confidence*100) / 100
+	return r, true
+}
+
+func merchantKey(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	key := make([]string, 0, 3)
+	for _, w := range words {
+		if len([]rune(w)) < 2 {
+			continue
+		}
+		key = append(key, w)
+		if len(key) == 3 {
+			break
+		}
+	}
+	return strings.Join(key, " ")
+}
+
+func merchantName(charges []Transaction) string {
+	counts := make(map[string]int)
+	name := ""
+	for _, c := range charges {
+		d := strings.TrimSpace(c.Description)
+		counts[d]++
+		if counts[d] > counts[name] || (counts[d] == counts[name] && d < name) {
+			name = d
+		}
+	}
+	return name
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
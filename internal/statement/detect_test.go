@@ -0,0 +1,82 @@
+package statement
+
+import (
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []Transaction
+		want         []Recurring
+	}{
+		{
+			name: "monthly",
+			transactions: []Transaction{
+				{Date: day(2025, 4, 3), Amount: -399, Description: "YANDEX*PLUS 1234"},
+				{Date: day(2025, 5, 3), Amount: -399, Description: "YANDEX*PLUS 5678"},
+				{Date: day(2025, 6, 2), Amount: -399, Description: "YANDEX*PLUS 1234"},
+				{Date: day(2025, 7, 3), Amount: -449, Description: "YANDEX*PLUS 1234"},
+			},
+			want: []Recurring{{Merchant: "YANDEX*PLUS 1234", Period: PeriodMonthly, Amount: 449,
+				First: day(2025, 4, 3), Last: day(2025, 7, 3), Charges: 4, Confidence: 1, Active: true}},
+		},
+		{
+			name: "yearly",
+			transactions: []Transaction{
+				{Date: day(2024, 3, 10), Amount: -2990, Description: "Kinopoisk annual"},
+				{Date: day(2025, 3, 10), Amount: -2990, Description: "Kinopoisk annual"},
+				{Date: day(2025, 6, 1), Amount: -150, Description: "Coffee"},
+			},
+			want: []Recurring{{Merchant: "Kinopoisk annual", Period: PeriodYearly, Amount: 2990,
+				First: day(2024, 3, 10), Last: day(2025, 3, 10), Charges: 2, Confidence: 1, Active: true}},
+		},
+		{
+			name: "ended",
+			transactions: []Transaction{
+				{Date: day(2025, 1, 5), Amount: -169, Description: "Spotify"},
+				{Date: day(2025, 2, 5), Amount: -169, Description: "Spotify"},
+				{Date: day(2025, 3, 5), Amount: -169, Description: "Spotify"},
+				{Date: day(2025, 7, 20), Amount: -150, Description: "Coffee"},
+			},
+			want: []Recurring{{Merchant: "Spotify", Period: PeriodMonthly, Amount: 169,
+				First: day(2025, 1, 5), Last: day(2025, 3, 5), Charges: 3, Confidence: 1, Active: false}},
+		},
+		{
+			name: "irregular",
+			transactions: []Transaction{
+				{Date: day(2025, 1, 5), Amount: -150, Description: "Coffee"},
+				{Date: day(2025, 1, 9), Amount: -150, Description: "Coffee"},
+				{Date: day(2025, 3, 20), Amount: -150, Description: "Coffee"},
+				{Date: day(2025, 3, 22), Amount: -150, Description: "Coffee"},
+			},
+		},
+		{
+			name: "credits next to debits",
+			transactions: []Transaction{
+				{Date: day(2025, 4, 25), Amount: 50000, Description: "Salary"},
+				{Date: day(2025, 5, 25), Amount: 50000, Description: "Salary"},
+				{Date: day(2025, 6, 25), Amount: 50000, Description: "Salary"},
+				{Date: day(2025, 6, 26), Amount: -150, Description: "Coffee"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.transactions)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %+v, want %+v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxStatementSize = 16 << 20
+
+var (
+	ofxStart = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxEnd   = regexp.MustCompile(`(?i)</STMTTRN>|</BANKTRANLIST>`)
+	ofxField = regexp.MustCompile(`(?i)<(\w+)>([^<\r\n]*)`)
+)
+
+func ParseOFX(r io.Reader) (transactions []Transaction, err error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxStatementSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading ofx error: %v", err)
+	}
+	if len(data) > maxStatementSize {
+		return nil, fmt.Errorf("statement is larger than %d bytes", maxStatementSize)
+	}
+	for _, block := range ofxBlocks(string(data)) {
+		fields := make(map[string]string)
+		for _, f := range ofxField.FindAllStringSubmatch(block, -1) {
+			fields[strings.ToUpper(f[1])] = strings.TrimSpace(f[2])
+		}
+		t, err := ofxToTransaction(fields)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+func ofxToTransaction(fields map[string]string) (t Transaction, err error) {
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return t, fmt.Errorf("invalid ofx transaction date: %q", posted)
+	}
+	t.Date, err = time.Parse("20060102", posted[:8])
+	if err != nil {
+		return t, fmt.Errorf("invalid ofx transaction date: %q", posted)
+	}
+	t.Amount, err = parseAmount(fields["TRNAMT"])
+	if err != nil {
+		return t, fmt.Errorf("invalid ofx transaction amount: %q", fields["TRNAMT"])
+	}
+	t.Description = fields["NAME"]
+	if t.Description == "" {
+		t.Description = fields["MEMO"]
+	}
+	if t.Description == "" {
+		t.Description = fields["PAYEE"]
+	}
+	return t, nil
+}
+
+func ofxBlocks(data string) (blocks []string) {
+	starts := ofxStart.FindAllStringIndex(data, -1)
+	for i, start := range starts {
+		limit := len(data)
+		if i+1 < len(starts) {
+			limit = starts[i+1][0]
+		}
+		block := data[start[1]:limit]
+		if loc := ofxEnd.FindStringIndex(block); loc != nil {
+			block = block[:loc[0]]
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
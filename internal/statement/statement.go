@@ -0,0 +1,57 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	FormatOFX = "ofx"
+	FormatCSV = "csv"
+)
+
+type Transaction struct {
+	Date        time.Time
+	Amount      float64
+	Description string
+}
+
+func Parse(r io.Reader, filename string) (transactions []Transaction, err error) {
+	reader := bufio.NewReader(r)
+	format := DetectFormat(reader, filename)
+	switch format {
+	case FormatOFX:
+		transactions, err = ParseOFX(reader)
+	case FormatCSV:
+		transactions, err = ParseCSV(reader)
+	default:
+		return nil, fmt.Errorf("unsupported statement format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, fmt.Errorf("statement has no transactions")
+	}
+	return transactions, nil
+}
+
+func DetectFormat(r *bufio.Reader, filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".ofx", ".qfx":
+		return FormatOFX
+	case ".csv":
+		return FormatCSV
+	}
+	head, _ := r.Peek(512)
+	upper := bytes.ToUpper(head)
+	if bytes.Contains(upper, []byte("OFXHEADER")) || bytes.Contains(upper, []byte("<OFX>")) {
+		return FormatOFX
+	}
+	return FormatCSV
+}
@@ -0,0 +1,324 @@
+package subctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"main/pkg/client"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// listPageSize is the page size list fetches with, the server allows 1000.
+const listPageSize = 200
+
+var subHeader = []string{"ID", "SERVICE", "PRICE", "USER", "START", "END", "BILLING", "TRIAL END"}
+
+func subRows(subs []client.Subscription) (rows [][]string) {
+	for _, sub := range subs {
+		rows = append(rows, []string{strconv.Itoa(sub.Id), sub.ServiceName, strconv.Itoa(sub.Price),
+			sub.UserId.String(), sub.StartDate, sub.EndDate, sub.BillingPeriod, sub.TrialEndDate})
+	}
+	return rows
+}
+
+func listFlags(fs *flag.FlagSet) {
+	fs.String("user", "", "only subscriptions of this user ID")
+	fs.String("service", "", "only subscriptions to this service")
+	fs.Int("limit", 0, "print at most this many subscriptions, 0 for all")
+	fs.Int("offset", 0, "number of subscriptions to skip")
+}
+
+func runList(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	userId, err := uuidFlag(fs, "user")
+	if err != nil {
+		return err
+	}
+	limit := intFlag(fs, "limit")
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	opts := client.ListOptions{
+		UserId:      userId,
+		ServiceName: stringFlag(fs, "service"),
+		Limit:       listPageSize,
+		Offset:      intFlag(fs, "offset"),
+	}
+	if limit > 0 {
+		opts.Limit = min(limit, listPageSize)
+	}
+	subs := []client.Subscription{}
+	for sub, err := range c.Subscriptions(ctx, opts) {
+		if err != nil {
+			return err
+		}
+		subs = append(subs, sub)
+		if len(subs) == limit {
+			break
+		}
+	}
+	return env.print(subs, subHeader, subRows(subs))
+}
+
+func runGet(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	id, err := idArg(args)
+	if err != nil {
+		return err
+	}
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	sub, err := c.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	return env.print(sub, subHeader, subRows([]client.Subscription{sub}))
+}
+
+func subFlags(fs *flag.FlagSet) {
+	fs.String("service", "", "service name")
+	fs.Int("price", 0, "monthly or yearly price in rubles")
+	fs.String("user", "", "user ID")
+	fs.String("start", "", "start month, MM-YYYY")
+	fs.String("end", "", "end month, MM-YYYY, empty for open-ended")
+	fs.String("billing", "", "billing period: monthly (default) or yearly")
+	fs.String("trial", "", "last month of the free trial, MM-YYYY")
+}
+
+// applySubFlags sets the fields of the given flags only, so update leaves
+// the others as they are.
+func applySubFlags(fs *flag.FlagSet, sub *client.SubRequest) (err error) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "service":
+			sub.ServiceName = f.Value.String()
+		case "price":
+			sub.Price = intFlag(fs, "price")
+		case "user":
+			sub.UserId, err = uuid.Parse(f.Value.String())
+			if err != nil {
+				err = fmt.Errorf("invalid -user, user ID required")
+			}
+		case "start":
+			sub.StartDate = f.Value.String()
+		case "end":
+			sub.EndDate = f.Value.String()
+		case "billing":
+			sub.BillingPeriod = f.Value.String()
+		case "trial":
+			sub.TrialEndDate = f.Value.String()
+		}
+	})
+	return err
+}
+
+func runCreate(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	data := client.SubRequest{}
+	if err := applySubFlags(fs, &data); err != nil {
+		return err
+	}
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	sub, warnings, err := c.CreateSubscription(ctx, data)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		env.warn("%s", w)
+	}
+	return env.print(sub, subHeader, subRows([]client.Subscription{sub}))
+}
+
+func runUpdate(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	id, err := idArg(args)
+	if err != nil {
+		return err
+	}
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	sub, err := c.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	data := client.SubRequest{
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price,
+		UserId:        sub.UserId,
+		StartDate:     sub.StartDate,
+		EndDate:       sub.EndDate,
+		BillingPeriod: sub.BillingPeriod,
+		TrialEndDate:  sub.TrialEndDate,
+	}
+	if err = applySubFlags(fs, &data); err != nil {
+		return err
+	}
+	sub, warnings, err := c.UpdateSubscription(ctx, id, data)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		env.warn("%s", w)
+	}
+	return env.print(sub, subHeader, subRows([]client.Subscription{sub}))
+}
+
+// runDelete stops at the first failure, the IDs deleted before it are
+// printed.
+func runDelete(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("subscription ID required")
+	}
+	ids := []int{}
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid subscription ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	deleted, rows := []int{}, [][]string{}
+	for _, id := range ids {
+		if err = c.DeleteSubscription(ctx, id); err != nil {
+			err = fmt.Errorf("deleting subscription %d error: %w", id, err)
+			break
+		}
+		deleted = append(deleted, id)
+		rows = append(rows, []string{strconv.Itoa(id)})
+	}
+	if printErr := env.print(map[string][]int{"deleted": deleted}, []string{"DELETED"}, rows); err == nil {
+		err = printErr
+	}
+	return err
+}
+
+func costFlags(fs *flag.FlagSet) {
+	fs.String("user", "", "user ID (required)")
+	fs.String("service", "", "service name (required)")
+	fs.String("start", "", "first month, MM-YYYY (required)")
+	fs.String("end", "", "last month, MM-YYYY (required)")
+}
+
+func runCost(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	userId, err := uuidFlag(fs, "user")
+	if err != nil {
+		return err
+	}
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	cost, err := c.Cost(ctx, client.CostOptions{
+		UserId:      userId,
+		ServiceName: stringFlag(fs, "service"),
+		Start:       stringFlag(fs, "start"),
+		End:         stringFlag(fs, "end"),
+	})
+	if err != nil {
+		return err
+	}
+	return env.print(cost, []string{"USER", "SERVICE", "START", "END", "COST"}, [][]string{{
+		cost.UserId.String(), cost.ServiceName, cost.StartDate, cost.EndDate, strconv.Itoa(cost.Cost),
+	}})
+}
+
+func reportFlags(fs *flag.FlagSet) {
+	fs.String("from", "", "first month, MM-YYYY (required)")
+	fs.String("to", "", "last month, MM-YYYY (required)")
+	fs.String("user", "", "only the spend of this user ID")
+	fs.Bool("by-user", false, "a row per user and month")
+}
+
+// runReport prints a row per month and service, the JSON output keeps the
+// monthly totals of the server.
+func runReport(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	userId, err := uuidFlag(fs, "user")
+	if err != nil {
+		return err
+	}
+	byUser := stringFlag(fs, "by-user") == "true"
+	c, err := env.Client()
+	if err != nil {
+		return err
+	}
+	months, err := c.SpendReport(ctx, client.SpendOptions{
+		From:        stringFlag(fs, "from"),
+		To:          stringFlag(fs, "to"),
+		UserId:      userId,
+		GroupByUser: byUser,
+	})
+	if err != nil {
+		return err
+	}
+	header := []string{"MONTH", "SERVICE", "TOTAL"}
+	if byUser {
+		header = []string{"MONTH", "USER", "SERVICE", "TOTAL"}
+	}
+	rows := [][]string{}
+	for _, month := range months {
+		for _, service := range month.Services {
+			row := []string{month.Month, service.ServiceName, strconv.Itoa(service.Total)}
+			if byUser {
+				user := ""
+				if month.UserId != nil {
+					user = month.UserId.String()
+				}
+				row = []string{month.Month, user, service.ServiceName, strconv.Itoa(service.Total)}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return env.print(months, header, rows)
+}
+
+func idArg(args []string) (id int, err error) {
+	if len(args) != 1 {
+		return id, fmt.Errorf("one subscription ID required")
+	}
+	id, err = strconv.Atoi(args[0])
+	if err != nil {
+		return id, fmt.Errorf("invalid subscription ID %q", args[0])
+	}
+	return id, nil
+}
+
+func isSet(fs *flag.FlagSet, name string) (set bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func stringFlag(fs *flag.FlagSet, name string) string {
+	return fs.Lookup(name).Value.String()
+}
+
+// intFlag reads a flag defined with fs.Int, which the flag package already
+// checked.
+func intFlag(fs *flag.FlagSet, name string) int {
+	n, _ := strconv.Atoi(stringFlag(fs, name))
+	return n
+}
+
+func uuidFlag(fs *flag.FlagSet, name string) (id uuid.UUID, err error) {
+	s := stringFlag(fs, name)
+	if s == "" {
+		return id, nil
+	}
+	if id, err = uuid.Parse(s); err != nil {
+		return id, fmt.Errorf("invalid -%s, user ID required", name)
+	}
+	return id, nil
+}
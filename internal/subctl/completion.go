@@ -0,0 +1,154 @@
+package subctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completeCommand is run by the completion scripts with the words typed so
+// far, the last one being the word to complete. It prints one candidate per
+// line, so the scripts stay small and always match the commands and
+// profiles of the binary.
+const completeCommand = "__complete"
+
+var completionScripts = map[string]string{
+	"bash": `_subctl() {
+	local IFS=$'\n'
+	COMPREPLY=($(subctl __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _subctl subctl
+`,
+	"zsh": `#compdef subctl
+_subctl() {
+	local -a candidates
+	candidates=("${(@f)$(subctl __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	compadd -a candidates
+}
+compdef _subctl subctl
+`,
+	"fish": `function __subctl_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	subctl __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c subctl -f -a '(__subctl_complete)'
+`,
+}
+
+var profileActions = []string{"list", "show", "set", "use", "delete"}
+
+func runCompletion(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	if len(args) != 1 || completionScripts[args[0]] == "" {
+		return fmt.Errorf("shell required: bash, zsh or fish")
+	}
+	_, err := io.WriteString(env.Stdout, completionScripts[args[0]])
+	return err
+}
+
+func runComplete(env *Env, words []string) error {
+	for _, candidate := range env.complete(words) {
+		fmt.Fprintln(env.Stdout, candidate)
+	}
+	return nil
+}
+
+func (env *Env) complete(words []string) (candidates []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	if len(words) == 1 {
+		return withPrefix(append(commandNames(), "help"), current)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == words[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		return nil
+	}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	env.commonFlags(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	// Find the positional arguments before the current word and whether it
+	// is the value of a flag.
+	positional, valueOf := []string{}, ""
+	for _, word := range words[1 : len(words)-1] {
+		if valueOf != "" {
+			valueOf = ""
+			continue
+		}
+		name, ok := flagName(word)
+		if !ok {
+			positional = append(positional, word)
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !strings.Contains(word, "=") && !isBoolFlag(f) {
+			valueOf = name
+		}
+	}
+
+	switch {
+	case valueOf == "profile":
+		return withPrefix(env.profileNames(), current)
+	case valueOf == "o":
+		return withPrefix(outputs, current)
+	case valueOf == "billing":
+		return withPrefix([]string{"monthly", "yearly"}, current)
+	case valueOf != "":
+		return nil
+	case strings.HasPrefix(current, "-"):
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "-"+f.Name)
+		})
+		return withPrefix(candidates, current)
+	}
+	switch {
+	case cmd.name == "completion" && len(positional) == 0:
+		return withPrefix([]string{"bash", "fish", "zsh"}, current)
+	case cmd.name == "profile" && len(positional) == 0:
+		return withPrefix(profileActions, current)
+	case cmd.name == "profile" && len(positional) == 1 && positional[0] != "list":
+		return withPrefix(env.profileNames(), current)
+	}
+	return nil
+}
+
+func (env *Env) profileNames() []string {
+	cfg, err := loadConfig(env.ConfigPath)
+	if err != nil {
+		return nil
+	}
+	return cfg.names()
+}
+
+// flagName accepts -name, --name and -name=value.
+func flagName(word string) (name string, ok bool) {
+	if len(word) < 2 || word[0] != '-' {
+		return name, false
+	}
+	name = strings.TrimPrefix(word[1:], "-")
+	name, _, _ = strings.Cut(name, "=")
+	return name, name != ""
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func withPrefix(words []string, prefix string) (matches []string) {
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,53 @@
+package subctl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+var outputs = []string{outputTable, outputJSON, outputCSV}
+
+func validOutput(output string) error {
+	for _, o := range outputs {
+		if o == output {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, %s required", output, strings.Join(outputs, ", "))
+}
+
+// print writes value as indented JSON, or the rows under the header as an
+// aligned table or CSV.
+func (env *Env) print(value any, header []string, rows [][]string) error {
+	switch env.output {
+	case outputJSON:
+		encoder := json.NewEncoder(env.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputCSV:
+		w := csv.NewWriter(env.Stdout)
+		w.Write(header)
+		w.WriteAll(rows)
+		return w.Error()
+	}
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// warn writes to stderr, so warnings do not end up in JSON or CSV output.
+func (env *Env) warn(format string, args ...any) {
+	fmt.Fprintf(env.Stderr, "warning: "+format+"\n", args...)
+}
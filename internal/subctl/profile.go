@@ -0,0 +1,173 @@
+package subctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Profile is a server subctl talks to.
+type Profile struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+}
+
+type Config struct {
+	Current  string             `json:"current"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// DefaultConfigPath is $SUBCTL_CONFIG or subctl/config.json in the user
+// config directory, e.g. ~/.config/subctl/config.json on Linux.
+func DefaultConfigPath() string {
+	if path := os.Getenv("SUBCTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "subctl", "config.json")
+}
+
+// loadConfig returns an empty config when the file does not exist yet.
+func loadConfig(path string) (cfg Config, err error) {
+	cfg.Profiles = map[string]Profile{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("reading profiles error: %v", err)
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("reading profiles error, %s: %v", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the file readable by the user only, as it holds tokens.
+func saveConfig(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("saving profiles error: %v", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("saving profiles error: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("saving profiles error: %v", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("saving profiles error: %v", err)
+	}
+	return nil
+}
+
+func (cfg Config) names() (names []string) {
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runProfile manages the profile file. "set" takes the URL and token from
+// the -url and -token flags, and the first profile set becomes the current
+// one.
+func runProfile(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error {
+	action, name := "list", ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if len(args) > 1 {
+		name = args[1]
+	}
+	if action != "list" && action != "show" && name == "" {
+		return fmt.Errorf("profile %s needs a profile name", action)
+	}
+	cfg, err := loadConfig(env.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		type listed struct {
+			Name    string `json:"name"`
+			URL     string `json:"url"`
+			Current bool   `json:"current"`
+		}
+		list, rows := []listed{}, [][]string{}
+		for _, n := range cfg.names() {
+			list = append(list, listed{Name: n, URL: cfg.Profiles[n].URL, Current: n == cfg.Current})
+			current := ""
+			if n == cfg.Current {
+				current = "*"
+			}
+			rows = append(rows, []string{current, n, cfg.Profiles[n].URL})
+		}
+		return env.print(list, []string{"CURRENT", "NAME", "URL"}, rows)
+	case "show":
+		if name == "" {
+			name = cfg.Current
+		}
+		if name == "" {
+			return fmt.Errorf("no current profile, see subctl profile use")
+		}
+		profile, ok := cfg.Profiles[name]
+		if !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		token := ""
+		if profile.Token != "" {
+			token = "set"
+		}
+		return env.print(map[string]string{"name": name, "url": profile.URL, "token": token},
+			[]string{"NAME", "URL", "TOKEN"}, [][]string{{name, profile.URL, token}})
+	case "set":
+		profile := cfg.Profiles[name]
+		// Only the flags given count, not their environment defaults.
+		if isSet(fs, "url") {
+			profile.URL = env.url
+		}
+		if isSet(fs, "token") {
+			profile.Token = env.token
+		}
+		if profile.URL == "" {
+			return fmt.Errorf("profile %q needs a server URL, pass -url", name)
+		}
+		cfg.Profiles[name] = profile
+		if cfg.Current == "" {
+			cfg.Current = name
+		}
+		return saveConfig(env.ConfigPath, cfg)
+	case "use":
+		if _, ok := cfg.Profiles[name]; !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		cfg.Current = name
+		return saveConfig(env.ConfigPath, cfg)
+	case "delete":
+		if _, ok := cfg.Profiles[name]; !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		delete(cfg.Profiles, name)
+		if cfg.Current == name {
+			cfg.Current = ""
+		}
+		return saveConfig(env.ConfigPath, cfg)
+	}
+	return fmt.Errorf("unknown profile action %q, list, show, set, use or delete required", action)
+}
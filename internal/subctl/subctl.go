@@ -0,0 +1,167 @@
+// Package subctl implements the subctl command-line tool, which manages
+// subscriptions on a running server through pkg/client.
+package subctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"main/pkg/client"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultURL = "http://127.0.0.1:8000"
+
+type command struct {
+	name  string
+	args  string
+	usage string
+	flags func(fs *flag.FlagSet)
+	run   func(ctx context.Context, env *Env, fs *flag.FlagSet, args []string) error
+}
+
+var commands []command
+
+func init() {
+	// Set here, as completion reads the list itself.
+	commands = []command{
+		{name: "list", usage: "list subscriptions", flags: listFlags, run: runList},
+		{name: "get", args: "<id>", usage: "show a subscription", run: runGet},
+		{name: "create", usage: "create a subscription", flags: subFlags, run: runCreate},
+		{name: "update", args: "<id>", usage: "change the given fields of a subscription", flags: subFlags, run: runUpdate},
+		{name: "delete", args: "<id>...", usage: "delete subscriptions", run: runDelete},
+		{name: "cost", usage: "sum what a user paid for a service", flags: costFlags, run: runCost},
+		{name: "report", usage: "show the spend per month and service", flags: reportFlags, run: runReport},
+		{name: "profile", args: "list|show|set|use|delete [name]", usage: "manage server profiles", run: runProfile},
+		{name: "completion", args: "bash|zsh|fish", usage: "print a shell completion script", run: runCompletion},
+	}
+}
+
+// Env is what a command runs with: the output streams, the chosen output
+// format and, once a command asks for it, the client of the profile.
+type Env struct {
+	Stdout io.Writer
+	Stderr io.Writer
+	// ConfigPath is the profile file, see DefaultConfigPath.
+	ConfigPath string
+
+	output  string
+	profile string
+	url     string
+	token   string
+	timeout time.Duration
+}
+
+// Run parses the command line and runs the command.
+func Run(ctx context.Context, env *Env, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(env.Stdout, Usage())
+		return nil
+	}
+	if args[0] == completeCommand {
+		return runComplete(env, args[1:])
+	}
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+		fs := flag.NewFlagSet("subctl "+c.name, flag.ContinueOnError)
+		fs.SetOutput(env.Stderr)
+		env.commonFlags(fs)
+		if c.flags != nil {
+			c.flags(fs)
+		}
+		fs.Usage = func() {
+			fmt.Fprintf(env.Stderr, "usage: subctl %s [flags] %s\n\n%s\n\nflags:\n", c.name, c.args, c.usage)
+			fs.PrintDefaults()
+		}
+		positional, err := parseArgs(fs, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = validOutput(env.output); err != nil {
+			return err
+		}
+		return c.run(ctx, env, fs, positional)
+	}
+	return fmt.Errorf("unknown command %q, available commands: %s", args[0], strings.Join(commandNames(), ", "))
+}
+
+func Usage() string {
+	var b strings.Builder
+	b.WriteString("usage: subctl <command> [flags] [args]\n\ncommands:\n")
+	for _, c := range commands {
+		b.WriteString(fmt.Sprintf("  %-11s %s\n", c.name, c.usage))
+	}
+	b.WriteString("\nEvery command takes -profile, -url, -token, -o table|json|csv and -timeout.\n")
+	b.WriteString("Run subctl <command> -h for its flags.\n")
+	return b.String()
+}
+
+// parseArgs lets flags follow the arguments, as in subctl get 5 -o json,
+// which the flag package alone stops at.
+func parseArgs(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		if err = fs.Parse(args); err != nil {
+			return positional, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func commandNames() (names []string) {
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+func (env *Env) commonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&env.profile, "profile", os.Getenv("SUBCTL_PROFILE"), "profile to use instead of the current one")
+	fs.StringVar(&env.url, "url", os.Getenv("SUBCTL_URL"), "server URL, overrides the profile")
+	fs.StringVar(&env.token, "token", os.Getenv("SUBCTL_TOKEN"), "bearer token, overrides the profile")
+	fs.StringVar(&env.output, "o", outputTable, "output format: table, json or csv")
+	fs.DurationVar(&env.timeout, "timeout", 30*time.Second, "timeout of each request")
+}
+
+// Client creates the client for the server of the profile, with the URL
+// and token flags taking precedence.
+func (env *Env) Client() (c *client.Client, err error) {
+	cfg, err := loadConfig(env.ConfigPath)
+	if err != nil {
+		return c, err
+	}
+	name := env.profile
+	if name == "" {
+		name = cfg.Current
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok && env.profile != "" {
+		return c, fmt.Errorf("unknown profile %q, see subctl profile list", env.profile)
+	}
+	baseURL := firstSet(env.url, profile.URL, defaultURL)
+	token := firstSet(env.token, profile.Token)
+	return client.New(baseURL, client.WithTimeout(env.timeout), client.WithToken(token),
+		client.WithUserAgent("subctl"))
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
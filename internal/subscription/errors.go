@@ -0,0 +1,16 @@
+package subscription
+
+import (
+	"errors"
+	"main/internal/db"
+)
+
+var (
+	ErrValidation    = errors.New("invalid subscription")
+	ErrInvalidQuery  = errors.New("invalid request")
+	ErrNotFound      = db.ErrNotFound
+	ErrOverlap       = errors.New("subscription overlaps an existing one")
+	ErrInvalidImport = errors.New("import contains invalid rows")
+	ErrBatchFailed   = errors.New("batch contains failed operations")
+	ErrNoSigningKey  = errors.New("privacy signing key is not configured")
+)
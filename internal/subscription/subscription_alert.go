@@ -0,0 +1,67 @@
+package subscription
+
+import (
+	"context"
+	"main/internal/model"
+	"time"
+)
+
+const defaultAlertMonths = 3
+
+func (s *SubscriptionService) PriceAlerts(ctx context.Context, data model.PriceAlertRequest) (alerts model.PriceAlerts, err error) {
+	dto := model.PriceAlertDTO{
+		Since:       s.convertStringToDate(data.Since),
+		UserId:      data.UserId,
+		ServiceName: data.ServiceName,
+		Threshold:   s.Config.Alerts.PriceIncreasePercent,
+	}
+	if dto.Since.IsZero() {
+		now := time.Now().UTC()
+		dto.Since = time.Date(now.Year(), now.Month()-defaultAlertMonths, 1, 0, 0, 0, 0, time.UTC)
+	}
+	alerts.Increases, err = s.Storage.PriceIncreases(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return alerts, err
+	}
+	alerts.Outliers, err = s.Storage.PriceOutliers(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return alerts, err
+	}
+	if alerts.Increases == nil {
+		alerts.Increases = []model.PriceIncrease{}
+	}
+	if alerts.Outliers == nil {
+		alerts.Outliers = []model.PriceOutlier{}
+	}
+	return alerts, nil
+}
+
+// priceIncreaseEvent returns the price change event of an update that raises
+// the price by at least the alert threshold, or nil. It is saved in the
+// transaction of the update, so an event exists exactly when its update was
+// committed.
+func (s *SubscriptionService) priceIncreaseEvent(old, cur model.SubscriptionDTO) *model.EventDTO {
+	if old.Price <= 0 || cur.Price <= old.Price {
+		return nil
+	}
+	percent := float64(cur.Price-old.Price) * 100 / float64(old.Price)
+	if percent < s.Config.Alerts.PriceIncreasePercent {
+		return nil
+	}
+	return &model.EventDTO{
+		Type:           model.EventPriceChange,
+		SubscriptionId: cur.Id,
+		UserId:         cur.UserId,
+		ServiceName:    cur.ServiceName,
+		OldPrice:       old.Price,
+		NewPrice:       cur.Price,
+	}
+}
+
+func (s *SubscriptionService) logPriceIncrease(event *model.EventDTO) {
+	if event != nil {
+		s.Logger.Warnf("price increase of %s for user %s: %d -> %d", event.ServiceName, event.UserId, event.OldPrice, event.NewPrice)
+	}
+}
@@ -0,0 +1,94 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+)
+
+const (
+	defaultAnalyticsLimit = 10
+	maxAnalyticsLimit     = 100
+)
+
+func (s *SubscriptionService) PopularServices(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePopularity, err error) {
+	dto, err := s.mapperAnalyticsToDTO(data)
+	if err != nil {
+		return list, err
+	}
+	if dto.OrderBy == "" {
+		dto.OrderBy = "subscribers"
+	}
+	if dto.OrderBy != "subscribers" && dto.OrderBy != "revenue" {
+		return list, fmt.Errorf("%w: invalid order, subscribers or revenue required", ErrInvalidQuery)
+	}
+	if dto.Limit <= 0 {
+		dto.Limit = defaultAnalyticsLimit
+	}
+	if dto.Limit > maxAnalyticsLimit {
+		dto.Limit = maxAnalyticsLimit
+	}
+	list, err = s.Storage.PopularServices(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return list, err
+	}
+	return list, nil
+}
+
+func (s *SubscriptionService) PriceStats(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePriceStats, err error) {
+	dto, err := s.mapperAnalyticsToDTO(data)
+	if err != nil {
+		return list, err
+	}
+	list, err = s.Storage.PriceStats(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return list, err
+	}
+	return list, nil
+}
+
+func (s *SubscriptionService) Churn(ctx context.Context, data model.AnalyticsRequest) (list []model.MonthChurn, err error) {
+	dto, err := s.mapperAnalyticsToDTO(data)
+	if err != nil {
+		return list, err
+	}
+	dtos, err := s.Storage.Churn(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return list, err
+	}
+	for _, d := range dtos {
+		list = append(list, model.MonthChurn{
+			Month:     s.convertDateToString(d.Month),
+			New:       d.New,
+			Cancelled: d.Cancelled,
+		})
+	}
+	return list, nil
+}
+
+func (s *SubscriptionService) Lifetime(ctx context.Context, data model.AnalyticsRequest) (lifetime model.SubscriptionLifetime, err error) {
+	dto, err := s.mapperAnalyticsToDTO(data)
+	if err != nil {
+		return lifetime, err
+	}
+	lifetime, err = s.Storage.Lifetime(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return lifetime, err
+	}
+	return lifetime, nil
+}
+
+func (s *SubscriptionService) mapperAnalyticsToDTO(data model.AnalyticsRequest) (dto model.AnalyticsDTO, err error) {
+	dto = model.AnalyticsDTO{
+		StartDate: s.convertStringToDate(data.StartDate),
+		EndDate:   s.convertStringToDate(data.EndDate),
+		OrderBy:   data.OrderBy,
+		Limit:     data.Limit,
+	}
+	err = s.checkDateRange(dto.StartDate, dto.EndDate)
+	return dto, err
+}
@@ -0,0 +1,246 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/internal/db"
+	"main/internal/model"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const maxBatchOperations = 5000
+
+type batchEntry struct {
+	dto   model.SubscriptionDTO
+	index int
+}
+
+type batchState struct {
+	old      map[int]model.SubscriptionDTO
+	existing map[uuid.UUID][]batchEntry
+}
+
+func (s *SubscriptionService) Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) (results []model.BatchResult, err error) {
+	if len(ops) == 0 {
+		return results, fmt.Errorf("%w: batch has no operations", ErrValidation)
+	}
+	if len(ops) > maxBatchOperations {
+		return results, fmt.Errorf("%w: batch has more than %d operations", ErrValidation, maxBatchOperations)
+	}
+
+	state, err := s.loadBatchState(ctx, ops)
+	if err != nil {
+		return results, err
+	}
+	results = make([]model.BatchResult, len(ops))
+	dtos := make([]model.BatchOperationDTO, len(ops))
+	failed := 0
+	for i, op := range ops {
+		results[i] = model.BatchResult{Index: i, Op: op.Op, Id: op.Id}
+		dtos[i], err = s.prepareOperation(ctx, i, op, state)
+		if err != nil {
+			s.failOperation(&results[i], err)
+			failed++
+		}
+	}
+	if atomic && failed > 0 {
+		s.skipOperations(results)
+		return results, fmt.Errorf("%w: %d of %d operations", ErrBatchFailed, failed, len(ops))
+	}
+
+	valid := make([]model.BatchOperationDTO, 0, len(ops)-failed)
+	indexes := make([]int, 0, len(ops)-failed)
+	for i := range dtos {
+		if results[i].Status == 0 {
+			if ops[i].Op == model.BatchUpdate {
+				dtos[i].Event = s.priceIncreaseEvent(state.old[ops[i].Id], dtos[i].Sub)
+			}
+			valid = append(valid, dtos[i])
+			indexes = append(indexes, i)
+		}
+	}
+	applied, err := s.Storage.ApplyBatch(ctx, valid, atomic)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return results, err
+	}
+	for j, res := range applied {
+		i := indexes[j]
+		if res.Err != nil {
+			s.Logger.Errorln(res.Err)
+			s.failOperation(&results[i], res.Err)
+			failed++
+			continue
+		}
+		results[i].Id = res.Id
+		results[i].Status = http.StatusOK
+		if ops[i].Op == model.BatchCreate {
+			results[i].Status = http.StatusCreated
+		}
+	}
+	if atomic && failed > 0 {
+		s.skipOperations(results)
+		return results, fmt.Errorf("%w: %d of %d operations", ErrBatchFailed, failed, len(ops))
+	}
+	for _, i := range indexes {
+		if results[i].Error != "" {
+			continue
+		}
+		switch ops[i].Op {
+		case model.BatchCreate:
+			dtos[i].Sub.Id = results[i].Id
+			s.notifyWebhooks(ctx, model.WebhookSubscriptionCreated, dtos[i].Sub)
+		case model.BatchUpdate:
+			s.logPriceIncrease(dtos[i].Event)
+			s.notifyWebhooks(ctx, model.WebhookSubscriptionUpdated, dtos[i].Sub)
+		case model.BatchDelete:
+			s.notifyWebhooks(ctx, model.WebhookSubscriptionDeleted, state.old[ops[i].Id])
+		}
+	}
+	return results, nil
+}
+
+func (s *SubscriptionService) loadBatchState(ctx context.Context, ops []model.BatchOperation) (state batchState, err error) {
+	state = batchState{
+		old:      make(map[int]model.SubscriptionDTO),
+		existing: make(map[uuid.UUID][]batchEntry),
+	}
+	ids := []int{}
+	for _, op := range ops {
+		if op.Op == model.BatchUpdate || op.Op == model.BatchDelete {
+			ids = append(ids, op.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return state, nil
+	}
+	dtos, err := s.Storage.LoadListByIds(ctx, ids)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return state, errors.New("loading existing subs error")
+	}
+	for _, dto := range dtos {
+		state.old[dto.Id] = dto
+	}
+	return state, nil
+}
+
+func (s *SubscriptionService) prepareOperation(ctx context.Context, index int, op model.BatchOperation,
+	state batchState) (dto model.BatchOperationDTO, err error) {
+	dto.Op = op.Op
+	switch op.Op {
+	case model.BatchCreate:
+		op.Subscription.Id = 0
+	case model.BatchUpdate, model.BatchDelete:
+		if op.Id <= 0 {
+			return dto, fmt.Errorf("%w: sub id is required", ErrValidation)
+		}
+		if _, ok := state.old[op.Id]; !ok {
+			return dto, db.ErrNotFound
+		}
+		op.Subscription.Id = op.Id
+	default:
+		return dto, fmt.Errorf("%w: unknown operation %q, create, update or delete required", ErrValidation, op.Op)
+	}
+
+	if op.Op == model.BatchDelete {
+		dto.Sub.Id = op.Id
+		old := state.old[op.Id]
+		if err = s.loadBatchUser(ctx, state, old.UserId); err != nil {
+			return dto, err
+		}
+		state.remove(old)
+		return dto, nil
+	}
+
+	dto.Sub, err = s.validate(op.Subscription)
+	if err != nil {
+		return dto, err
+	}
+	if err = s.loadBatchUser(ctx, state, dto.Sub.UserId); err != nil {
+		return dto, err
+	}
+	overlaps := []string{}
+	for _, e := range state.existing[dto.Sub.UserId] {
+		if (dto.Sub.Id != 0 && e.dto.Id == dto.Sub.Id) || !s.isOverlap(dto.Sub, e.dto) {
+			continue
+		}
+		if e.index < 0 {
+			overlaps = append(overlaps, fmt.Sprintf("id: %d", e.dto.Id))
+		} else {
+			overlaps = append(overlaps, fmt.Sprintf("operation: %d", e.index))
+		}
+	}
+	if len(overlaps) > 0 {
+		if s.Config.Subscriptions.OverlapPolicy == OverlapReject {
+			return dto, fmt.Errorf("%w, overlapping %s", ErrOverlap, strings.Join(overlaps, ", "))
+		}
+		s.Logger.Warnf("%s sub of user %s overlaps %s", dto.Sub.ServiceName, dto.Sub.UserId, strings.Join(overlaps, ", "))
+	}
+	if op.Op == model.BatchUpdate {
+		old := state.old[op.Id]
+		if err = s.loadBatchUser(ctx, state, old.UserId); err != nil {
+			return dto, err
+		}
+		state.remove(old)
+	}
+	state.existing[dto.Sub.UserId] = append(state.existing[dto.Sub.UserId], batchEntry{dto: dto.Sub, index: index})
+	return dto, nil
+}
+
+func (s *SubscriptionService) loadBatchUser(ctx context.Context, state batchState, userId uuid.UUID) error {
+	if _, ok := state.existing[userId]; ok {
+		return nil
+	}
+	dtos, err := s.Storage.LoadListByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return errors.New("loading existing subs error")
+	}
+	entries := make([]batchEntry, 0, len(dtos))
+	for _, dto := range dtos {
+		entries = append(entries, batchEntry{dto: dto, index: -1})
+	}
+	state.existing[userId] = entries
+	return nil
+}
+
+func (state batchState) remove(dto model.SubscriptionDTO) {
+	entries := state.existing[dto.UserId]
+	for i, e := range entries {
+		if e.dto.Id == dto.Id {
+			state.existing[dto.UserId] = append(entries[:i:i], entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *SubscriptionService) failOperation(result *model.BatchResult, err error) {
+	result.Error = err.Error()
+	switch {
+	case errors.Is(err, ErrValidation):
+		result.Status = http.StatusBadRequest
+	case errors.Is(err, db.ErrNotFound):
+		result.Status = http.StatusNotFound
+	case errors.Is(err, ErrOverlap):
+		result.Status = http.StatusConflict
+	default:
+		result.Status = http.StatusInternalServerError
+	}
+}
+
+func (s *SubscriptionService) skipOperations(results []model.BatchResult) {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Error = "not applied, batch rolled back"
+			if results[i].Op == model.BatchCreate {
+				results[i].Id = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"main/internal/model"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	changeBufferSize  = 256
+	changePageSize    = 500
+	changeRetryDelay  = time.Second
+	changePrunePeriod = time.Hour
+)
+
+// ErrChangesLagging ends a watch whose consumer did not keep up with the
+// changes. The consumer resumes from the last change it received.
+var ErrChangesLagging = errors.New("change stream fell behind")
+
+// changeHub shares one database listener among all watchers of the process.
+type changeHub struct {
+	once        sync.Once
+	mu          sync.Mutex
+	subscribers map[chan model.SubscriptionChange]struct{}
+	lastId      int64
+}
+
+func newChangeHub() *changeHub {
+	return &changeHub{subscribers: make(map[chan model.SubscriptionChange]struct{})}
+}
+
+// WatchChanges calls fn with the changes after filter.AfterId that are still
+// kept, then with every new change until the context is done. Changes of
+// other users are skipped when filter.UserId is set.
+func (s *SubscriptionService) WatchChanges(ctx context.Context, filter model.ChangeFilter, fn func(change model.SubscriptionChange) error) (err error) {
+	changes, unsubscribe := s.subscribeChanges()
+	defer unsubscribe()
+
+	sent := make(map[int64]bool)
+	for filter.AfterId > 0 {
+		page, err := s.Storage.LoadChanges(ctx, model.ChangeFilter{
+			AfterId: filter.AfterId,
+			UserId:  filter.UserId,
+			Limit:   changePageSize,
+		})
+		if err != nil {
+			s.Logger.Errorln(err)
+			return err
+		}
+		for _, change := range page {
+			if err = fn(change); err != nil {
+				return err
+			}
+			sent[change.Id] = true
+			filter.AfterId = change.Id
+		}
+		if len(page) < changePageSize {
+			break
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-changes:
+			if !ok {
+				return ErrChangesLagging
+			}
+			if sent[change.Id] || (filter.UserId != uuid.Nil && change.UserId != filter.UserId) {
+				continue
+			}
+			if err = fn(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *SubscriptionService) subscribeChanges() (changes chan model.SubscriptionChange, unsubscribe func()) {
+	hub := s.changes
+	hub.once.Do(func() {
+		go s.listenChanges()
+		go s.pruneChanges()
+	})
+	changes = make(chan model.SubscriptionChange, changeBufferSize)
+	hub.mu.Lock()
+	hub.subscribers[changes] = struct{}{}
+	hub.mu.Unlock()
+	return changes, func() {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		if _, ok := hub.subscribers[changes]; ok {
+			delete(hub.subscribers, changes)
+			close(changes)
+		}
+	}
+}
+
+// listenChanges keeps the listener connected. After a reconnect it sends the
+// changes committed while the listener was down.
+func (s *SubscriptionService) listenChanges() {
+	ctx := context.Background()
+	for {
+		err := s.Storage.ListenChanges(ctx, func() {
+			s.catchUpChanges(ctx)
+		}, func(id int64) {
+			s.loadChanges(ctx, model.ChangeFilter{Ids: []int64{id}, Limit: 1})
+		})
+		s.Logger.Errorln(err)
+		time.Sleep(changeRetryDelay)
+	}
+}
+
+func (s *SubscriptionService) catchUpChanges(ctx context.Context) {
+	s.changes.mu.Lock()
+	lastId := s.changes.lastId
+	s.changes.mu.Unlock()
+	if lastId == 0 {
+		return
+	}
+	for s.loadChanges(ctx, model.ChangeFilter{AfterId: lastId, Limit: changePageSize}) == changePageSize {
+		s.changes.mu.Lock()
+		lastId = s.changes.lastId
+		s.changes.mu.Unlock()
+	}
+}
+
+// loadChanges sends the matching changes to the watchers and returns their
+// number. A watcher whose buffer is full is dropped.
+func (s *SubscriptionService) loadChanges(ctx context.Context, filter model.ChangeFilter) int {
+	list, err := s.Storage.LoadChanges(ctx, filter)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return 0
+	}
+	hub := s.changes
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for _, change := range list {
+		hub.lastId = max(hub.lastId, change.Id)
+		for ch := range hub.subscribers {
+			select {
+			case ch <- change:
+			default:
+				delete(hub.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+	return len(list)
+}
+
+func (s *SubscriptionService) pruneChanges() {
+	ticker := time.NewTicker(changePrunePeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		_, err := s.Storage.PruneChanges(context.Background(), time.Now().Add(-s.Config.Events.Retention))
+		if err != nil {
+			s.Logger.Errorln(err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package subscription
+
+import (
+	"context"
+	"main/internal/export"
+	"main/internal/model"
+	"time"
+)
+
+var subscriptionColumns = []export.Column{
+	{Name: "id", Type: export.Integer},
+	{Name: "service_name", Type: export.String},
+	{Name: "price", Type: export.Integer},
+	{Name: "user_id", Type: export.String},
+	{Name: "start_date", Type: export.Month},
+	{Name: "end_date", Type: export.Month},
+	{Name: "billing_period", Type: export.String},
+	{Name: "trial_end_date", Type: export.Month},
+}
+
+func (s *SubscriptionService) ExportList(ctx context.Context, filter model.ListFilter, w export.Writer) (err error) {
+	headerWritten := false
+	err = s.Storage.IterateList(ctx, filter, func(dto model.SubscriptionDTO) error {
+		if !headerWritten {
+			if err := w.WriteHeader(subscriptionColumns); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		return w.WriteRow(dto.Id, dto.ServiceName, dto.Price, dto.UserId.String(),
+			dto.StartDate, s.exportEndDate(dto), dto.BillingPeriod, dto.TrialEndDate)
+	})
+	if err != nil {
+		s.Logger.Errorln(err)
+		return err
+	}
+	if !headerWritten {
+		return w.WriteHeader(subscriptionColumns)
+	}
+	return nil
+}
+
+func (s *SubscriptionService) ExportCost(ctx context.Context, data model.CostRequest, w export.Writer) (err error) {
+	cost, err := s.Cost(ctx, data)
+	if err != nil {
+		return err
+	}
+	dto := s.mapperCostToDTO(data)
+	err = w.WriteHeader([]export.Column{
+		{Name: "user_id", Type: export.String},
+		{Name: "service_name", Type: export.String},
+		{Name: "start", Type: export.Month},
+		{Name: "end", Type: export.Month},
+		{Name: "cost", Type: export.Integer},
+	})
+	if err != nil {
+		return err
+	}
+	return w.WriteRow(dto.UserId.String(), dto.ServiceName, dto.StartDate, dto.EndDate, cost)
+}
+
+func (s *SubscriptionService) ExportSpendReport(ctx context.Context, data model.SpendReportRequest, w export.Writer) (err error) {
+	dto := model.SpendReportDTO{
+		StartDate:   s.convertStringToDate(data.StartDate),
+		EndDate:     s.convertStringToDate(data.EndDate),
+		UserId:      data.UserId,
+		GroupByUser: data.GroupByUser,
+	}
+	if err = s.checkDateRange(dto.StartDate, dto.EndDate); err != nil {
+		return err
+	}
+	months, err := s.Storage.SpendReport(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return err
+	}
+	columns := []export.Column{{Name: "month", Type: export.Month}}
+	if data.GroupByUser {
+		columns = append(columns, export.Column{Name: "user_id", Type: export.String})
+	}
+	columns = append(columns,
+		export.Column{Name: "service_name", Type: export.String},
+		export.Column{Name: "total", Type: export.Integer},
+	)
+	if err = w.WriteHeader(columns); err != nil {
+		return err
+	}
+	for _, m := range months {
+		for _, service := range m.Services {
+			values := []any{m.Month}
+			if data.GroupByUser {
+				values = append(values, m.UserId.String())
+			}
+			values = append(values, service.ServiceName, service.Total)
+			if err = w.WriteRow(values...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SubscriptionService) exportEndDate(dto model.SubscriptionDTO) time.Time {
+	if dto.EndDate.Before(dto.StartDate) {
+		return time.Time{}
+	}
+	return dto.EndDate
+}
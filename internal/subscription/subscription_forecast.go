@@ -0,0 +1,179 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"time"
+)
+
+const (
+	defaultForecastMonths = 12
+	maxForecastMonths     = 120
+)
+
+func (s *SubscriptionService) Forecast(ctx context.Context, data model.ForecastRequest) (forecast model.Forecast, err error) {
+	if data.Months == 0 {
+		data.Months = defaultForecastMonths
+	}
+	if data.Months < 0 || data.Months > maxForecastMonths {
+		return forecast, fmt.Errorf("invalid months, 1 to %d required", maxForecastMonths)
+	}
+
+	dtos, err := s.Storage.LoadListByUser(ctx, data.UserId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return forecast, err
+	}
+	prices, err := s.Storage.LoadScheduledPricesByUser(ctx, data.UserId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return forecast, err
+	}
+	schedule := make(map[int][]model.ScheduledPriceDTO)
+	for _, p := range prices {
+		schedule[p.SubscriptionId] = append(schedule[p.SubscriptionId], p)
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, data.Months-1, 0)
+
+	forecast = model.Forecast{
+		UserId:        data.UserId,
+		From:          s.convertDateToString(from),
+		To:            s.convertDateToString(to),
+		Months:        make([]model.ForecastMonth, data.Months),
+		Subscriptions: []model.ForecastContribution{},
+		Assumptions: []string{
+			fmt.Sprintf("forecast covers %s to %s, starting with the month after the current one",
+				s.convertDateToString(from), s.convertDateToString(to)),
+			"subscriptions without an end date continue for the whole period",
+			"monthly subscriptions are charged every month, yearly subscriptions once a year in their start month",
+			"no charges are made up to and including the trial end month",
+			"prices stay constant unless a price change is scheduled",
+		},
+	}
+	for i := range forecast.Months {
+		forecast.Months[i].Month = s.convertDateToString(from.AddDate(0, i, 0))
+	}
+
+	for _, dto := range dtos {
+		hasEnd := !dto.EndDate.IsZero() && !dto.EndDate.Before(dto.StartDate)
+		if hasEnd && dto.EndDate.Before(from) {
+			continue
+		}
+		contribution := model.ForecastContribution{
+			SubscriptionId: dto.Id,
+			ServiceName:    dto.ServiceName,
+			BillingPeriod:  dto.BillingPeriod,
+		}
+		for i := range forecast.Months {
+			month := from.AddDate(0, i, 0)
+			if !s.isCharged(dto, month) {
+				continue
+			}
+			price := s.priceAt(dto, schedule[dto.Id], month)
+			forecast.Months[i].Total += price
+			forecast.Total += price
+			contribution.Charges++
+			contribution.Total += price
+		}
+		forecast.Subscriptions = append(forecast.Subscriptions, contribution)
+		forecast.Assumptions = append(forecast.Assumptions, s.forecastNotes(dto, schedule[dto.Id], from, to)...)
+	}
+	return forecast, nil
+}
+
+func (s *SubscriptionService) SaveScheduledPrice(ctx context.Context, subID int, data model.ScheduledPriceRequest) (id int, err error) {
+	dto := model.ScheduledPriceDTO{
+		SubscriptionId: subID,
+		Price:          data.Price,
+		EffectiveDate:  s.convertStringToDate(data.EffectiveDate),
+	}
+	if dto.Price <= 0 {
+		return id, fmt.Errorf("invalid price, positive value required")
+	}
+	if dto.EffectiveDate.IsZero() {
+		return id, fmt.Errorf("invalid date, MM-YYYY format required")
+	}
+	_, err = s.Storage.Load(ctx, subID)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return id, err
+	}
+	id, err = s.Storage.SaveScheduledPrice(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return id, err
+	}
+	return id, nil
+}
+
+func (s *SubscriptionService) LoadScheduledPrices(ctx context.Context, subID int) (prices []model.ScheduledPrice, err error) {
+	_, err = s.Storage.Load(ctx, subID)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return prices, err
+	}
+	dtos, err := s.Storage.LoadScheduledPrices(ctx, subID)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return prices, err
+	}
+	prices = []model.ScheduledPrice{}
+	for _, dto := range dtos {
+		prices = append(prices, model.ScheduledPrice{
+			Id:             dto.Id,
+			SubscriptionId: dto.SubscriptionId,
+			Price:          dto.Price,
+			EffectiveDate:  s.convertDateToString(dto.EffectiveDate),
+		})
+	}
+	return prices, nil
+}
+
+func (s *SubscriptionService) isCharged(dto model.SubscriptionDTO, month time.Time) bool {
+	if month.Before(dto.StartDate) {
+		return false
+	}
+	if !dto.EndDate.IsZero() && !dto.EndDate.Before(dto.StartDate) && month.After(dto.EndDate) {
+		return false
+	}
+	if !dto.TrialEndDate.IsZero() && !month.After(dto.TrialEndDate) {
+		return false
+	}
+	if dto.BillingPeriod == model.BillingYearly && month.Month() != dto.StartDate.Month() {
+		return false
+	}
+	return true
+}
+
+func (s *SubscriptionService) priceAt(dto model.SubscriptionDTO, schedule []model.ScheduledPriceDTO, month time.Time) int {
+	price := dto.Price
+	for _, p := range schedule {
+		if p.EffectiveDate.After(month) {
+			break
+		}
+		price = p.Price
+	}
+	return price
+}
+
+func (s *SubscriptionService) forecastNotes(dto model.SubscriptionDTO, schedule []model.ScheduledPriceDTO, from, to time.Time) (notes []string) {
+	name := fmt.Sprintf("%s (id %d)", dto.ServiceName, dto.Id)
+	if !dto.EndDate.IsZero() && !dto.EndDate.Before(dto.StartDate) && !dto.EndDate.After(to) {
+		notes = append(notes, fmt.Sprintf("%s ends %s", name, s.convertDateToString(dto.EndDate)))
+	}
+	if !dto.TrialEndDate.IsZero() && !dto.TrialEndDate.Before(from) {
+		notes = append(notes, fmt.Sprintf("%s trial ends %s", name, s.convertDateToString(dto.TrialEndDate)))
+	}
+	for _, p := range schedule {
+		if p.EffectiveDate.Before(from) || p.EffectiveDate.After(to) {
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("%s price changes to %d from %s", name, p.Price,
+			s.convertDateToString(p.EffectiveDate)))
+	}
+	return notes
+}
@@ -0,0 +1,216 @@
+package subscription
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"main/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const maxImportRows = 10000
+
+var importColumns = []string{
+	"service_name",
+	"price",
+	"user_id",
+	"start_date",
+	"end_date",
+	"billing_period",
+	"trial_end_date",
+}
+
+var requiredImportColumns = []string{
+	"service_name",
+	"price",
+	"user_id",
+	"start_date",
+}
+
+type importRecord struct {
+	row model.ImportRow
+	dto model.SubscriptionDTO
+}
+
+func (s *SubscriptionService) Import(ctx context.Context, r io.Reader, dryRun bool) (report model.ImportReport, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return report, fmt.Errorf("reading csv header error: %v", err)
+	}
+	columns, err := s.mapImportColumns(header)
+	if err != nil {
+		return report, err
+	}
+
+	existing := make(map[uuid.UUID][]model.SubscriptionDTO)
+	records := []importRecord{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("reading csv error: %v", err)
+		}
+		if len(records) == maxImportRows {
+			return report, fmt.Errorf("csv file has more than %d rows", maxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		record, err := s.parseImportRecord(ctx, line, fields, columns, existing, records)
+		if err != nil {
+			return report, err
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return report, fmt.Errorf("csv file has no rows")
+	}
+
+	report = model.ImportReport{
+		DryRun: dryRun,
+		Total:  len(records),
+		Rows:   make([]model.ImportRow, 0, len(records)),
+	}
+	dtos := make([]model.SubscriptionDTO, 0, len(records))
+	for _, record := range records {
+		if len(record.row.Errors) == 0 {
+			report.Valid++
+		}
+		report.Rows = append(report.Rows, record.row)
+		dtos = append(dtos, record.dto)
+	}
+	if report.Valid != report.Total {
+		return report, fmt.Errorf("%w: %d of %d rows", ErrInvalidImport, report.Total-report.Valid, report.Total)
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	events := make([]*model.EventDTO, len(dtos))
+	for i, dto := range dtos {
+		if prev, ok := s.previousSub(existing[dto.UserId], dto); ok {
+			events[i] = s.priceIncreaseEvent(prev, dto)
+		}
+	}
+	ids, err := s.Storage.SaveList(ctx, dtos, events)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return report, err
+	}
+	for i, id := range ids {
+		report.Rows[i].Id = id
+		dtos[i].Id = id
+		if events[i] != nil {
+			events[i].SubscriptionId = id
+		}
+		s.logPriceIncrease(events[i])
+		s.notifyWebhooks(ctx, model.WebhookSubscriptionCreated, dtos[i])
+	}
+	report.Imported = len(ids)
+	return report, nil
+}
+
+func (s *SubscriptionService) mapImportColumns(header []string) (columns map[string]int, err error) {
+	columns = make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		known := false
+		for _, c := range importColumns {
+			known = known || c == name
+		}
+		if !known {
+			return columns, fmt.Errorf("unknown csv column: %q", header[i])
+		}
+		if _, ok := columns[name]; ok {
+			return columns, fmt.Errorf("duplicate csv column: %q", header[i])
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return columns, fmt.Errorf("required csv column is missing: %q", name)
+		}
+	}
+	return columns, nil
+}
+
+func (s *SubscriptionService) parseImportRecord(ctx context.Context, line int, fields []string, columns map[string]int,
+	existing map[uuid.UUID][]model.SubscriptionDTO, previous []importRecord) (record importRecord, err error) {
+	record.row.Row = line
+	get := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	sub := model.Subscription{
+		ServiceName:   get("service_name"),
+		StartDate:     get("start_date"),
+		EndDate:       get("end_date"),
+		BillingPeriod: get("billing_period"),
+		TrialEndDate:  get("trial_end_date"),
+	}
+	if sub.Price, err = strconv.Atoi(get("price")); err != nil {
+		record.row.Errors = append(record.row.Errors, "invalid price, integer value required")
+	}
+	sub.UserId, _ = uuid.Parse(get("user_id"))
+	record.dto = s.mapperToDTO(sub)
+	record.row.Errors = append(record.row.Errors, s.validationErrors(sub, record.dto)...)
+	if len(record.row.Errors) > 0 {
+		return record, nil
+	}
+
+	userSubs, ok := existing[record.dto.UserId]
+	if !ok {
+		userSubs, err = s.Storage.LoadListByUser(ctx, record.dto.UserId)
+		if err != nil {
+			s.Logger.Errorln(err)
+			return record, errors.New("loading existing subs error")
+		}
+		existing[record.dto.UserId] = userSubs
+	}
+	overlaps := []string{}
+	for _, d := range userSubs {
+		if s.isOverlap(record.dto, d) {
+			overlaps = append(overlaps, fmt.Sprintf("overlaps %s sub with id: %d", d.ServiceName, d.Id))
+		}
+	}
+	for _, p := range previous {
+		if len(p.row.Errors) == 0 && s.isOverlap(record.dto, p.dto) {
+			overlaps = append(overlaps, fmt.Sprintf("overlaps %s sub in row: %d", p.dto.ServiceName, p.row.Row))
+		}
+	}
+	if s.Config.Subscriptions.OverlapPolicy == OverlapReject {
+		record.row.Errors = overlaps
+	} else {
+		record.row.Warnings = overlaps
+	}
+	return record, nil
+}
+
+func (s *SubscriptionService) previousSub(userSubs []model.SubscriptionDTO, dto model.SubscriptionDTO) (prev model.SubscriptionDTO, ok bool) {
+	for _, d := range userSubs {
+		if !strings.EqualFold(strings.TrimSpace(d.ServiceName), strings.TrimSpace(dto.ServiceName)) {
+			continue
+		}
+		if d.StartDate.After(dto.StartDate) {
+			continue
+		}
+		if !ok || d.StartDate.After(prev.StartDate) {
+			prev, ok = d, true
+		}
+	}
+	return prev, ok
+}
@@ -2,14 +2,52 @@ package subscription
 
 import (
 	"context"
+	"io"
+	"main/internal/export"
 	"main/internal/model"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type SubscriptionInterface interface {
-	Save(ctx context.Context, sub model.Subscription) (id int, err error)
+	Save(ctx context.Context, sub model.Subscription) (id int, overlaps []model.Subscription, err error)
 	Load(ctx context.Context, subID int) (sub model.Subscription, err error)
-	LoadList(ctx context.Context) (subs []model.Subscription, err error)
+	LoadList(ctx context.Context, filter model.ListFilter) (subs []model.Subscription, err error)
+	LoadPage(ctx context.Context, filter model.ListFilter) (page model.SubscriptionPage, err error)
+	StreamList(ctx context.Context, filter model.ListFilter, fn func(sub model.Subscription) error) (err error)
+	Search(ctx context.Context, data model.SearchRequest) (results []model.SearchResult, err error)
+	LoadListByIds(ctx context.Context, ids []int) (subs []model.Subscription, err error)
+	LoadListByUsers(ctx context.Context, userIds []uuid.UUID) (subs []model.Subscription, err error)
 	Delete(ctx context.Context, subID int) (err error)
-	Update(ctx context.Context, sub model.Subscription) (err error)
+	Update(ctx context.Context, sub model.Subscription) (overlaps []model.Subscription, err error)
 	Cost(ctx context.Context, data model.CostRequest) (cost int, err error)
+	SpendReport(ctx context.Context, data model.SpendReportRequest) (report []model.MonthSpend, err error)
+	PopularServices(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePopularity, err error)
+	PriceStats(ctx context.Context, data model.AnalyticsRequest) (list []model.ServicePriceStats, err error)
+	Churn(ctx context.Context, data model.AnalyticsRequest) (list []model.MonthChurn, err error)
+	Lifetime(ctx context.Context, data model.AnalyticsRequest) (lifetime model.SubscriptionLifetime, err error)
+	Forecast(ctx context.Context, data model.ForecastRequest) (forecast model.Forecast, err error)
+	SaveScheduledPrice(ctx context.Context, subID int, data model.ScheduledPriceRequest) (id int, err error)
+	LoadScheduledPrices(ctx context.Context, subID int) (prices []model.ScheduledPrice, err error)
+	PriceAlerts(ctx context.Context, data model.PriceAlertRequest) (alerts model.PriceAlerts, err error)
+	Duplicates(ctx context.Context, userId uuid.UUID) (pairs []model.DuplicatePair, err error)
+	Batch(ctx context.Context, ops []model.BatchOperation, atomic bool) (results []model.BatchResult, err error)
+	Import(ctx context.Context, r io.Reader, dryRun bool) (report model.ImportReport, err error)
+	ExportList(ctx context.Context, filter model.ListFilter, w export.Writer) (err error)
+	ExportCost(ctx context.Context, data model.CostRequest, w export.Writer) (err error)
+	ExportSpendReport(ctx context.Context, data model.SpendReportRequest, w export.Writer) (err error)
+	ExportUserData(ctx context.Context, userId uuid.UUID, w io.Writer) (err error)
+	EraseUserData(ctx context.Context, userId uuid.UUID) (receipt model.ErasureReceipt, err error)
+	ExportWarehouse(ctx context.Context, data model.WarehouseExportRequest) (result model.WarehouseExport, err error)
+	DetectCharges(ctx context.Context, r io.Reader, filename string, userId uuid.UUID) (candidates []model.ChargeCandidate, err error)
+	CreateWebhook(ctx context.Context, data model.WebhookRequest) (webhook model.Webhook, err error)
+	LoadWebhook(ctx context.Context, id int) (webhook model.Webhook, err error)
+	LoadWebhooks(ctx context.Context) (webhooks []model.Webhook, err error)
+	UpdateWebhook(ctx context.Context, id int, data model.WebhookRequest) (webhook model.Webhook, err error)
+	DeleteWebhook(ctx context.Context, id int) (err error)
+	LoadDeliveries(ctx context.Context, filter model.DeliveryFilter) (list []model.WebhookDelivery, err error)
+	ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (id int, err error)
+	NotifyExpired(ctx context.Context, now time.Time) (count int, err error)
+	WatchChanges(ctx context.Context, filter model.ChangeFilter, fn func(change model.SubscriptionChange) error) (err error)
 }
@@ -0,0 +1,86 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OverlapReject = "reject"
+	OverlapWarn   = "warn"
+)
+
+func (s *SubscriptionService) Duplicates(ctx context.Context, userId uuid.UUID) (pairs []model.DuplicatePair, err error) {
+	dtos, err := s.Storage.LoadListByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return pairs, err
+	}
+	pairs = []model.DuplicatePair{}
+	for i := range dtos {
+		for j := i + 1; j < len(dtos); j++ {
+			if s.isOverlap(dtos[i], dtos[j]) {
+				pairs = append(pairs, model.DuplicatePair{
+					First:  s.mapperToSub(dtos[i]),
+					Second: s.mapperToSub(dtos[j]),
+				})
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// checkOverlaps returns the subscriptions the sub overlaps, so that they can
+// be reported as warnings without loading them again.
+func (s *SubscriptionService) checkOverlaps(ctx context.Context, dto model.SubscriptionDTO) (subs []model.Subscription, err error) {
+	overlaps, err := s.findOverlaps(ctx, dto)
+	if err != nil {
+		return subs, err
+	}
+	if len(overlaps) == 0 {
+		return subs, nil
+	}
+	ids := make([]string, 0, len(overlaps))
+	for _, o := range overlaps {
+		ids = append(ids, fmt.Sprint(o.Id))
+		subs = append(subs, s.mapperToSub(o))
+	}
+	if s.Config.Subscriptions.OverlapPolicy == OverlapReject {
+		return nil, fmt.Errorf("%w, overlapping ids: %s", ErrOverlap, strings.Join(ids, ", "))
+	}
+	s.Logger.Warnf("%s sub of user %s overlaps ids: %s", dto.ServiceName, dto.UserId, strings.Join(ids, ", "))
+	return subs, nil
+}
+
+func (s *SubscriptionService) findOverlaps(ctx context.Context, dto model.SubscriptionDTO) (overlaps []model.SubscriptionDTO, err error) {
+	dtos, err := s.Storage.LoadListByUser(ctx, dto.UserId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return overlaps, err
+	}
+	for _, d := range dtos {
+		if d.Id != dto.Id && s.isOverlap(dto, d) {
+			overlaps = append(overlaps, d)
+		}
+	}
+	return overlaps, nil
+}
+
+func (s *SubscriptionService) isOverlap(a, b model.SubscriptionDTO) bool {
+	if a.UserId != b.UserId || !strings.EqualFold(strings.TrimSpace(a.ServiceName), strings.TrimSpace(b.ServiceName)) {
+		return false
+	}
+	return !a.StartDate.After(s.effectiveEnd(b)) && !b.StartDate.After(s.effectiveEnd(a))
+}
+
+func (s *SubscriptionService) effectiveEnd(dto model.SubscriptionDTO) time.Time {
+	if dto.EndDate.IsZero() || dto.EndDate.Before(dto.StartDate) {
+		return time.Date(9999, time.December, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return dto.EndDate
+}
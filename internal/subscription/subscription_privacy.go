@@ -0,0 +1,264 @@
+package subscription
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"main/internal/export"
+	"main/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type userData struct {
+	subs     []model.SubscriptionDTO
+	prices   []model.ScheduledPriceDTO
+	events   []model.EventDTO
+	spend    []model.MonthSpendDTO
+	forecast model.Forecast
+}
+
+type zipFile struct {
+	name  string
+	write func(w io.Writer) error
+}
+
+// ExportUserData writes a ZIP archive with everything stored about the user.
+// All data is loaded before the first byte is written, so a failed export
+// never leaves a partial archive behind.
+func (s *SubscriptionService) ExportUserData(ctx context.Context, userId uuid.UUID, w io.Writer) (err error) {
+	data, err := s.loadUserData(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	subs := make([]model.Subscription, 0, len(data.subs))
+	for _, dto := range data.subs {
+		subs = append(subs, s.mapperToSub(dto))
+	}
+	prices := make([]model.ScheduledPrice, 0, len(data.prices))
+	for _, dto := range data.prices {
+		prices = append(prices, model.ScheduledPrice{
+			Id:             dto.Id,
+			SubscriptionId: dto.SubscriptionId,
+			Price:          dto.Price,
+			EffectiveDate:  s.convertDateToString(dto.EffectiveDate),
+		})
+	}
+	events := make([]model.Event, 0, len(data.events))
+	for _, dto := range data.events {
+		events = append(events, model.Event{
+			Id:             dto.Id,
+			Type:           dto.Type,
+			SubscriptionId: dto.SubscriptionId,
+			ServiceName:    dto.ServiceName,
+			OldPrice:       dto.OldPrice,
+			NewPrice:       dto.NewPrice,
+			CreatedAt:      dto.CreatedAt.UTC(),
+		})
+	}
+	spend := make([]model.MonthSpend, 0, len(data.spend))
+	for _, dto := range data.spend {
+		spend = append(spend, model.MonthSpend{
+			Month:    s.convertDateToString(dto.Month),
+			Total:    dto.Total,
+			Services: dto.Services,
+		})
+	}
+
+	files := []zipFile{
+		{"subscriptions.json", jsonFile(subs)},
+		{"subscriptions.csv", csvFile(subscriptionColumns, len(data.subs), func(i int) []any {
+			dto := data.subs[i]
+			return []any{dto.Id, dto.ServiceName, dto.Price, dto.UserId.String(),
+				dto.StartDate, s.exportEndDate(dto), dto.BillingPeriod, dto.TrialEndDate}
+		})},
+		{"scheduled_prices.json", jsonFile(prices)},
+		{"scheduled_prices.csv", csvFile([]export.Column{
+			{Name: "id", Type: export.Integer},
+			{Name: "subscription_id", Type: export.Integer},
+			{Name: "price", Type: export.Integer},
+			{Name: "effective_date", Type: export.Month},
+		}, len(data.prices), func(i int) []any {
+			dto := data.prices[i]
+			return []any{dto.Id, dto.SubscriptionId, dto.Price, dto.EffectiveDate}
+		})},
+		{"events.json", jsonFile(events)},
+		{"events.csv", csvFile([]export.Column{
+			{Name: "id", Type: export.Integer},
+			{Name: "type", Type: export.String},
+			{Name: "subscription_id", Type: export.Integer},
+			{Name: "service_name", Type: export.String},
+			{Name: "old_price", Type: export.Integer},
+			{Name: "new_price", Type: export.Integer},
+			{Name: "created_at", Type: export.String},
+		}, len(events), func(i int) []any {
+			e := events[i]
+			return []any{e.Id, e.Type, e.SubscriptionId, e.ServiceName, e.OldPrice, e.NewPrice,
+				e.CreatedAt.Format(time.RFC3339)}
+		})},
+		{"spend_report.json", jsonFile(spend)},
+		{"spend_report.csv", s.spendReportFile(data.spend)},
+		{"forecast.json", jsonFile(data.forecast)},
+	}
+	manifest := model.UserExportManifest{
+		UserId:      userId,
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+	files = append([]zipFile{{"manifest.json", jsonFile(manifest)}}, files...)
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: manifest.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err = f.write(fw); err != nil {
+			return fmt.Errorf("writing %s error: %v", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// EraseUserData removes every row tied to the user and returns a receipt
+// signed with HMAC-SHA256 over its JSON encoding without the signature.
+func (s *SubscriptionService) EraseUserData(ctx context.Context, userId uuid.UUID) (receipt model.ErasureReceipt, err error) {
+	key := s.Config.Privacy.SigningKey
+	if key == "" {
+		return receipt, ErrNoSigningKey
+	}
+	erasure, err := s.Storage.EraseUser(ctx, model.ErasureDTO{
+		UserId:   userId,
+		UserHash: UserHash(key, userId),
+	})
+	if err != nil {
+		s.Logger.Errorln(err)
+		return receipt, err
+	}
+	receipt = model.ErasureReceipt{
+		Id:       erasure.Id,
+		UserId:   userId,
+		Removed:  erasure.Removed,
+		ErasedAt: erasure.ErasedAt.UTC(),
+	}
+	payload, err := json.Marshal(receipt)
+	if err != nil {
+		return receipt, err
+	}
+	receipt.Signature = "hmac-sha256:" + sign(key, payload)
+	s.Logger.Infof("user data erased, erasure id: %d", erasure.Id)
+	return receipt, nil
+}
+
+func (s *SubscriptionService) loadUserData(ctx context.Context, userId uuid.UUID) (data userData, err error) {
+	data.subs, err = s.Storage.LoadListByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return data, err
+	}
+	data.prices, err = s.Storage.LoadScheduledPricesByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return data, err
+	}
+	data.events, err = s.Storage.LoadEventsByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return data, err
+	}
+	data.forecast, err = s.Forecast(ctx, model.ForecastRequest{UserId: userId})
+	if err != nil {
+		return data, err
+	}
+	if len(data.subs) == 0 {
+		return data, nil
+	}
+
+	now := time.Now().UTC()
+	dto := model.SpendReportDTO{
+		StartDate: data.subs[0].StartDate,
+		EndDate:   time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		UserId:    userId,
+	}
+	for _, sub := range data.subs {
+		if sub.StartDate.Before(dto.StartDate) {
+			dto.StartDate = sub.StartDate
+		}
+		if sub.EndDate.After(dto.EndDate) {
+			dto.EndDate = sub.EndDate
+		}
+	}
+	data.spend, err = s.Storage.SpendReport(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return data, err
+	}
+	return data, nil
+}
+
+func (s *SubscriptionService) spendReportFile(months []model.MonthSpendDTO) func(w io.Writer) error {
+	rows := [][]any{}
+	for _, m := range months {
+		for _, service := range m.Services {
+			rows = append(rows, []any{m.Month, service.ServiceName, service.Total})
+		}
+	}
+	return csvFile([]export.Column{
+		{Name: "month", Type: export.Month},
+		{Name: "service_name", Type: export.String},
+		{Name: "total", Type: export.Integer},
+	}, len(rows), func(i int) []any {
+		return rows[i]
+	})
+}
+
+// UserHash is the keyed hash of the user id kept in the erasure tombstone,
+// which tells an erased user apart without storing the id.
+func UserHash(key string, userId uuid.UUID) string {
+	return sign(key, []byte(userId.String()))
+}
+
+func sign(key string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func jsonFile(v any) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func csvFile(columns []export.Column, n int, row func(i int) []any) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		cw, err := export.NewWriter(export.FormatCSV, w)
+		if err != nil {
+			return err
+		}
+		if err = cw.WriteHeader(columns); err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err = cw.WriteRow(row(i)...); err != nil {
+				return err
+			}
+		}
+		return cw.Close()
+	}
+}
@@ -0,0 +1,47 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"time"
+)
+
+func (s *SubscriptionService) SpendReport(ctx context.Context, data model.SpendReportRequest) (report []model.MonthSpend, err error) {
+	dto := model.SpendReportDTO{
+		StartDate:   s.convertStringToDate(data.StartDate),
+		EndDate:     s.convertStringToDate(data.EndDate),
+		UserId:      data.UserId,
+		GroupByUser: data.GroupByUser,
+	}
+	if err = s.checkDateRange(dto.StartDate, dto.EndDate); err != nil {
+		return report, err
+	}
+	dtos, err := s.Storage.SpendReport(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return report, err
+	}
+	for _, d := range dtos {
+		month := model.MonthSpend{
+			Month:    s.convertDateToString(d.Month),
+			Total:    d.Total,
+			Services: d.Services,
+		}
+		if data.GroupByUser {
+			month.UserId = d.UserId
+		}
+		report = append(report, month)
+	}
+	return report, nil
+}
+
+func (s *SubscriptionService) checkDateRange(start, end time.Time) error {
+	if start.IsZero() || end.IsZero() {
+		return fmt.Errorf("%w: invalid date, MM-YYYY format required", ErrInvalidQuery)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: invalid date range, from must not be after to", ErrInvalidQuery)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQuery     = 100
+)
+
+func (s *SubscriptionService) Search(ctx context.Context, data model.SearchRequest) (results []model.SearchResult, err error) {
+	dto := model.SearchDTO{
+		Query:  strings.TrimSpace(data.Query),
+		UserId: data.UserId,
+		Limit:  data.Limit,
+	}
+	switch data.Mode {
+	case "", model.SearchFuzzy:
+	case model.SearchPrefix:
+		dto.Prefix = true
+	default:
+		return results, fmt.Errorf("%w: unknown search mode %q, %s or %s required", ErrInvalidQuery,
+			data.Mode, model.SearchFuzzy, model.SearchPrefix)
+	}
+	if dto.Query == "" {
+		return results, fmt.Errorf("%w: search query is required", ErrInvalidQuery)
+	}
+	if utf8.RuneCountInString(dto.Query) > maxSearchQuery {
+		return results, fmt.Errorf("%w: search query is longer than %d characters", ErrInvalidQuery, maxSearchQuery)
+	}
+	if dto.Limit < 0 || dto.Limit > maxSearchLimit {
+		return results, fmt.Errorf("%w: invalid limit, 1 to %d required", ErrInvalidQuery, maxSearchLimit)
+	}
+	if dto.Limit == 0 {
+		dto.Limit = defaultSearchLimit
+	}
+
+	list, err := s.Storage.Search(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return results, err
+	}
+	results = []model.SearchResult{}
+	for _, result := range list {
+		results = append(results, model.SearchResult{
+			Subscription: s.mapperToSub(result.Sub),
+			Score:        result.Score,
+		})
+	}
+	return results, nil
+}
@@ -3,32 +3,55 @@ package subscription
 import (
 	"context"
 	"fmt"
+	"main/internal/config"
 	"main/internal/db"
 	"main/internal/model"
 	"main/pkg/logger"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 1000
 )
 
 type SubscriptionService struct {
 	Storage db.Storage
+	Config  *config.Config
 	Logger  *logger.Logger
+	changes *changeHub
 }
 
-func NewService(s db.Storage, logger *logger.Logger) SubscriptionInterface {
+func NewService(s db.Storage, cfg *config.Config, logger *logger.Logger) SubscriptionInterface {
 	return &SubscriptionService{
 		Storage: s,
+		Config:  cfg,
 		Logger:  logger,
+		changes: newChangeHub(),
 	}
 }
 
-func (s *SubscriptionService) Save(ctx context.Context, sub model.Subscription) (id int, err error) {
-	id, err = s.Storage.Save(ctx, s.mapperToDTO(sub))
+// Save returns the ID of the new subscription and the subscriptions it
+// overlaps, which only the warn overlap policy lets through.
+func (s *SubscriptionService) Save(ctx context.Context, sub model.Subscription) (id int, overlaps []model.Subscription, err error) {
+	dto, err := s.validate(sub)
+	if err != nil {
+		return id, overlaps, err
+	}
+	if overlaps, err = s.checkOverlaps(ctx, dto); err != nil {
+		return id, overlaps, err
+	}
+	id, err = s.Storage.Save(ctx, dto)
 	if err != nil {
 		s.Logger.Errorln(err)
-		return id, err
+		return id, nil, err
 	}
-	return id, nil
+	dto.Id = id
+	s.notifyWebhooks(ctx, model.WebhookSubscriptionCreated, dto)
+	return id, overlaps, nil
 }
 
 func (s *SubscriptionService) Load(ctx context.Context, subID int) (sub model.Subscription, err error) {
@@ -40,8 +63,8 @@ func (s *SubscriptionService) Load(ctx context.Context, subID int) (sub model.Su
 	return s.mapperToSub(dto), nil
 }
 
-func (s *SubscriptionService) LoadList(ctx context.Context) (subs []model.Subscription, err error) {
-	dtos, err := s.Storage.LoadList(ctx)
+func (s *SubscriptionService) LoadList(ctx context.Context, filter model.ListFilter) (subs []model.Subscription, err error) {
+	dtos, err := s.Storage.LoadList(ctx, filter)
 	if err != nil {
 		s.Logger.Errorln(err)
 		return subs, err
@@ -53,8 +76,37 @@ func (s *SubscriptionService) LoadList(ctx context.Context) (subs []model.Subscr
 	return subs, nil
 }
 
-func (s *SubscriptionService) Delete(ctx context.Context, subID int) (err error) {
-	err = s.Storage.Delete(ctx, subID)
+func (s *SubscriptionService) LoadPage(ctx context.Context, filter model.ListFilter) (page model.SubscriptionPage, err error) {
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return page, fmt.Errorf("%w: invalid limit, 1 to %d required", ErrInvalidQuery, maxPageLimit)
+	}
+	if filter.Offset < 0 {
+		return page, fmt.Errorf("%w: invalid offset, non-negative value required", ErrInvalidQuery)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	page = model.SubscriptionPage{
+		Items:  []model.Subscription{},
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	page.Total, err = s.Storage.CountList(ctx, filter)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return page, err
+	}
+	err = s.StreamList(ctx, filter, func(sub model.Subscription) error {
+		page.Items = append(page.Items, sub)
+		return nil
+	})
+	return page, err
+}
+
+func (s *SubscriptionService) StreamList(ctx context.Context, filter model.ListFilter, fn func(sub model.Subscription) error) (err error) {
+	err = s.Storage.IterateList(ctx, filter, func(dto model.SubscriptionDTO) error {
+		return fn(s.mapperToSub(dto))
+	})
 	if err != nil {
 		s.Logger.Errorln(err)
 		return err
@@ -62,19 +114,74 @@ func (s *SubscriptionService) Delete(ctx context.Context, subID int) (err error)
 	return nil
 }
 
-func (s *SubscriptionService) Update(ctx context.Context, sub model.Subscription) (err error) {
-	err = s.Storage.Update(ctx, s.mapperToDTO(sub))
+func (s *SubscriptionService) LoadListByIds(ctx context.Context, ids []int) (subs []model.Subscription, err error) {
+	dtos, err := s.Storage.LoadListByIds(ctx, ids)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return subs, err
+	}
+	for _, dto := range dtos {
+		subs = append(subs, s.mapperToSub(dto))
+	}
+	return subs, nil
+}
+
+func (s *SubscriptionService) LoadListByUsers(ctx context.Context, userIds []uuid.UUID) (subs []model.Subscription, err error) {
+	dtos, err := s.Storage.LoadListByUsers(ctx, userIds)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return subs, err
+	}
+	for _, dto := range dtos {
+		subs = append(subs, s.mapperToSub(dto))
+	}
+	return subs, nil
+}
+
+func (s *SubscriptionService) Delete(ctx context.Context, subID int) (err error) {
+	old, err := s.Storage.Load(ctx, subID)
 	if err != nil {
 		s.Logger.Errorln(err)
 		return err
 	}
+	err = s.Storage.Delete(ctx, subID)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return err
+	}
+	s.notifyWebhooks(ctx, model.WebhookSubscriptionDeleted, old)
 	return nil
 }
 
+// Update returns the subscriptions the updated one overlaps, like Save.
+func (s *SubscriptionService) Update(ctx context.Context, sub model.Subscription) (overlaps []model.Subscription, err error) {
+	dto, err := s.validate(sub)
+	if err != nil {
+		return overlaps, err
+	}
+	old, err := s.Storage.Load(ctx, dto.Id)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return overlaps, err
+	}
+	if overlaps, err = s.checkOverlaps(ctx, dto); err != nil {
+		return overlaps, err
+	}
+	event := s.priceIncreaseEvent(old, dto)
+	err = s.Storage.Update(ctx, dto, event)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return nil, err
+	}
+	s.logPriceIncrease(event)
+	s.notifyWebhooks(ctx, model.WebhookSubscriptionUpdated, dto)
+	return overlaps, nil
+}
+
 func (s *SubscriptionService) Cost(ctx context.Context, data model.CostRequest) (cost int, err error) {
 	dto := s.mapperCostToDTO(data)
 	if dto.StartDate.IsZero() || dto.EndDate.IsZero() {
-		return cost, fmt.Errorf("invalid date, MM-YYYY format required")
+		return cost, fmt.Errorf("%w: invalid date, MM-YYYY format required", ErrInvalidQuery)
 	}
 	cost, err = s.Storage.Cost(ctx, dto)
 	if err != nil {
@@ -84,14 +191,49 @@ func (s *SubscriptionService) Cost(ctx context.Context, data model.CostRequest)
 	return cost, nil
 }
 
+func (s *SubscriptionService) validate(sub model.Subscription) (dto model.SubscriptionDTO, err error) {
+	dto = s.mapperToDTO(sub)
+	problems := s.validationErrors(sub, dto)
+	if len(problems) > 0 {
+		return dto, fmt.Errorf("%w: %s", ErrValidation, strings.Join(problems, "; "))
+	}
+	return dto, nil
+}
+
+func (s *SubscriptionService) validationErrors(sub model.Subscription, dto model.SubscriptionDTO) (problems []string) {
+	if strings.TrimSpace(dto.ServiceName) == "" {
+		problems = append(problems, "service name is required")
+	}
+	if dto.Price < 0 {
+		problems = append(problems, "invalid price, non-negative value required")
+	}
+	if dto.UserId == uuid.Nil {
+		problems = append(problems, "invalid user id, uuid required")
+	}
+	if dto.StartDate.IsZero() {
+		problems = append(problems, "invalid start date, MM-YYYY format required")
+	}
+	if sub.EndDate != "" && dto.EndDate.IsZero() {
+		problems = append(problems, "invalid end date, MM-YYYY format required")
+	}
+	if !dto.StartDate.IsZero() && !dto.EndDate.IsZero() && dto.EndDate.Before(dto.StartDate) {
+		problems = append(problems, "end date must not be before start date")
+	}
+	if sub.TrialEndDate != "" && dto.TrialEndDate.IsZero() {
+		problems = append(problems, "invalid trial end date, MM-YYYY format required")
+	}
+	if dto.BillingPeriod != model.BillingMonthly && dto.BillingPeriod != model.BillingYearly {
+		problems = append(problems, "invalid billing period, monthly or yearly required")
+	}
+	return problems
+}
+
 func (s *SubscriptionService) convertStringToDate(str string) (date time.Time) {
 	minDate := "01-1999"
 	if len(str) == 0 || str <= minDate {
 		return date
 	}
-	split := strings.Split(str, "-")
-	dateStr := fmt.Sprintf("%s-%s-01", split[1], split[0])
-	date, _ = time.Parse("2006-01-02", dateStr)
+	date, _ = time.Parse("01-2006", str)
 
 	return date
 }
@@ -105,24 +247,32 @@ func (s *SubscriptionService) convertDateToString(date time.Time) (str string) {
 }
 
 func (s *SubscriptionService) mapperToDTO(sub model.Subscription) model.SubscriptionDTO {
+	billingPeriod := sub.BillingPeriod
+	if billingPeriod == "" {
+		billingPeriod = model.BillingMonthly
+	}
 	return model.SubscriptionDTO{
-		Id:          sub.Id,
-		ServiceName: sub.ServiceName,
-		Price:       sub.Price,
-		UserId:      sub.UserId,
-		StartDate:   s.convertStringToDate(sub.StartDate),
-		EndDate:     s.convertStringToDate(sub.EndDate),
+		Id:            sub.Id,
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price,
+		UserId:        sub.UserId,
+		StartDate:     s.convertStringToDate(sub.StartDate),
+		EndDate:       s.convertStringToDate(sub.EndDate),
+		BillingPeriod: billingPeriod,
+		TrialEndDate:  s.convertStringToDate(sub.TrialEndDate),
 	}
 }
 
 func (s *SubscriptionService) mapperToSub(dto model.SubscriptionDTO) model.Subscription {
 	return model.Subscription{
-		Id:          dto.Id,
-		ServiceName: dto.ServiceName,
-		Price:       dto.Price,
-		UserId:      dto.UserId,
-		StartDate:   s.convertDateToString(dto.StartDate),
-		EndDate:     s.convertDateToString(dto.EndDate),
+		Id:            dto.Id,
+		ServiceName:   dto.ServiceName,
+		Price:         dto.Price,
+		UserId:        dto.UserId,
+		StartDate:     s.convertDateToString(dto.StartDate),
+		EndDate:       s.convertDateToString(dto.EndDate),
+		BillingPeriod: dto.BillingPeriod,
+		TrialEndDate:  s.convertDateToString(dto.TrialEndDate),
 	}
 }
 
@@ -0,0 +1,54 @@
+package subscription
+
+import (
+	"context"
+	"io"
+	"main/internal/model"
+	"main/internal/statement"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *SubscriptionService) DetectCharges(ctx context.Context, r io.Reader, filename string, userId uuid.UUID) (candidates []model.ChargeCandidate, err error) {
+	transactions, err := statement.Parse(r, filename)
+	if err != nil {
+		return candidates, err
+	}
+	existing, err := s.Storage.LoadListByUser(ctx, userId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return candidates, err
+	}
+
+	candidates = []model.ChargeCandidate{}
+	for _, rec := range statement.Detect(transactions) {
+		sub := model.Subscription{
+			ServiceName:   rec.Merchant,
+			Price:         int(math.Round(rec.Amount)),
+			UserId:        userId,
+			StartDate:     s.convertDateToString(rec.First),
+			BillingPeriod: rec.Period,
+		}
+		if !rec.Active {
+			sub.EndDate = s.convertDateToString(rec.Last)
+		}
+		candidate := model.ChargeCandidate{
+			Subscription: sub,
+			Merchant:     rec.Merchant,
+			Charges:      rec.Charges,
+			LastCharge:   rec.Last.Format(time.DateOnly),
+			Confidence:   rec.Confidence,
+		}
+		dto := s.mapperToDTO(sub)
+		for _, e := range existing {
+			if s.isOverlap(dto, e) {
+				candidate.ExistingId = e.Id
+				break
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
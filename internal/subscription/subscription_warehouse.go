@@ -0,0 +1,28 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"main/internal/model"
+	"main/internal/warehouse"
+	"time"
+)
+
+func (s *SubscriptionService) ExportWarehouse(ctx context.Context, data model.WarehouseExportRequest) (result model.WarehouseExport, err error) {
+	from := s.convertStringToDate(data.From)
+	to := s.convertStringToDate(data.To)
+	if (data.From != "" && from.IsZero()) || (data.To != "" && to.IsZero()) {
+		return result, fmt.Errorf("%w: invalid date, MM-YYYY format required", ErrValidation)
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return result, fmt.Errorf("%w: invalid date range, from must not be after to", ErrValidation)
+	}
+	start := time.Now()
+	result, err = warehouse.Export(ctx, s.Storage, s.Config.Warehouse.Dir, from, to)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return result, err
+	}
+	s.Logger.Infof("warehouse export %s written in %s, %d files", result.Path, time.Since(start).Round(time.Millisecond), len(result.Files))
+	return result, nil
+}
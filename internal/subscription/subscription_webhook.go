@@ -0,0 +1,223 @@
+package subscription
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"main/internal/model"
+	"main/internal/webhook"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+func (s *SubscriptionService) CreateWebhook(ctx context.Context, data model.WebhookRequest) (webhook model.Webhook, err error) {
+	dto := model.WebhookDTO{
+		URL:        data.URL,
+		Secret:     data.Secret,
+		EventTypes: data.EventTypes,
+		Active:     data.Active == nil || *data.Active,
+	}
+	if err = s.validateWebhook(ctx, dto); err != nil {
+		return webhook, err
+	}
+	if dto.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return webhook, err
+		}
+		dto.Secret = hex.EncodeToString(secret)
+	}
+	dto.EventTypes = slices.Compact(slices.Sorted(slices.Values(dto.EventTypes)))
+	dto.Id, err = s.Storage.SaveWebhook(ctx, dto)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return webhook, err
+	}
+	dto, err = s.Storage.LoadWebhook(ctx, dto.Id)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return webhook, err
+	}
+	webhook = s.mapperToWebhook(dto)
+	webhook.Secret = dto.Secret
+	return webhook, nil
+}
+
+func (s *SubscriptionService) LoadWebhook(ctx context.Context, id int) (webhook model.Webhook, err error) {
+	dto, err := s.Storage.LoadWebhook(ctx, id)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return webhook, err
+	}
+	return s.mapperToWebhook(dto), nil
+}
+
+func (s *SubscriptionService) LoadWebhooks(ctx context.Context) (webhooks []model.Webhook, err error) {
+	dtos, err := s.Storage.LoadWebhooks(ctx)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return webhooks, err
+	}
+	webhooks = make([]model.Webhook, 0, len(dtos))
+	for _, dto := range dtos {
+		webhooks = append(webhooks, s.mapperToWebhook(dto))
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook changes only the fields set in the request.
+func (s *SubscriptionService) UpdateWebhook(ctx context.Context, id int, data model.WebhookRequest) (webhook model.Webhook, err error) {
+	dto, err := s.Storage.LoadWebhook(ctx, id)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return webhook, err
+	}
+	if data.URL != "" {
+		dto.URL = data.URL
+	}
+	if data.Secret != "" {
+		dto.Secret = data.Secret
+	}
+	if data.EventTypes != nil {
+		dto.EventTypes = data.EventTypes
+	}
+	if data.Active != nil {
+		dto.Active = *data.Active
+	}
+	if err = s.validateWebhook(ctx, dto); err != nil {
+		return webhook, err
+	}
+	dto.EventTypes = slices.Compact(slices.Sorted(slices.Values(dto.EventTypes)))
+	if err = s.Storage.UpdateWebhook(ctx, dto); err != nil {
+		s.Logger.Errorln(err)
+		return webhook, err
+	}
+	return s.mapperToWebhook(dto), nil
+}
+
+func (s *SubscriptionService) DeleteWebhook(ctx context.Context, id int) (err error) {
+	if err = s.Storage.DeleteWebhook(ctx, id); err != nil {
+		s.Logger.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (s *SubscriptionService) LoadDeliveries(ctx context.Context, filter model.DeliveryFilter) (list []model.WebhookDelivery, err error) {
+	if filter.Limit < 0 || filter.Limit > maxDeliveryLimit {
+		return list, fmt.Errorf("%w: invalid limit, 1 to %d required", ErrInvalidQuery, maxDeliveryLimit)
+	}
+	if filter.Offset < 0 {
+		return list, fmt.Errorf("%w: invalid offset, non-negative value required", ErrInvalidQuery)
+	}
+	switch filter.Status {
+	case "", model.DeliveryPending, model.DeliverySucceeded, model.DeliveryFailed:
+	default:
+		return list, fmt.Errorf("%w: invalid status, pending, succeeded or failed required", ErrInvalidQuery)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDeliveryLimit
+	}
+	if _, err = s.Storage.LoadWebhook(ctx, filter.WebhookId); err != nil {
+		s.Logger.Errorln(err)
+		return list, err
+	}
+	list, err = s.Storage.LoadDeliveries(ctx, filter)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return list, err
+	}
+	if list == nil {
+		list = []model.WebhookDelivery{}
+	}
+	return list, nil
+}
+
+// ReplayDelivery queues the event of a delivery to its webhook again,
+// whatever the status of the original delivery.
+func (s *SubscriptionService) ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (id int, err error) {
+	id, err = s.Storage.ReplayDelivery(ctx, webhookId, deliveryId)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return id, err
+	}
+	return id, nil
+}
+
+// NotifyExpired sends the expired event for subs that ended since the last
+// call and returns their number.
+func (s *SubscriptionService) NotifyExpired(ctx context.Context, now time.Time) (count int, err error) {
+	dtos, err := s.Storage.ExpireSubscriptions(ctx, now)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return count, err
+	}
+	for _, dto := range dtos {
+		s.notifyWebhooks(ctx, model.WebhookSubscriptionExpired, dto)
+	}
+	return len(dtos), nil
+}
+
+func (s *SubscriptionService) validateWebhook(ctx context.Context, dto model.WebhookDTO) error {
+	problems := []string{}
+	u, err := url.Parse(dto.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		problems = append(problems, "invalid url, absolute http or https url required")
+	} else if err = webhook.CheckHost(ctx, u.Hostname()); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid url, %v", err))
+	}
+	if len(dto.EventTypes) == 0 {
+		problems = append(problems, "at least one event type is required")
+	}
+	for _, eventType := range dto.EventTypes {
+		if !slices.Contains(model.WebhookEventTypes, eventType) {
+			problems = append(problems, fmt.Sprintf("unknown event type %q, %s required",
+				eventType, strings.Join(model.WebhookEventTypes, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// notifyWebhooks queues the event for every webhook subscribed to it. The
+// mutation it reports is already stored, so a failure is logged and does not
+// fail the request.
+func (s *SubscriptionService) notifyWebhooks(ctx context.Context, eventType string, dto model.SubscriptionDTO) {
+	event := model.WebhookEvent{
+		Id:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       s.mapperToSub(dto),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.Logger.Errorln(err)
+		return
+	}
+	if _, err = s.Storage.EnqueueDeliveries(ctx, event.Id, eventType, payload); err != nil {
+		s.Logger.Errorln(err)
+	}
+}
+
+func (s *SubscriptionService) mapperToWebhook(dto model.WebhookDTO) model.Webhook {
+	return model.Webhook{
+		Id:         dto.Id,
+		URL:        dto.URL,
+		EventTypes: dto.EventTypes,
+		Active:     dto.Active,
+		CreatedAt:  dto.CreatedAt,
+	}
+}
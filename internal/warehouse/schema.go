@@ -0,0 +1,59 @@
+package warehouse
+
+import "time"
+
+// SchemaVersion changes only when a column is removed or changes its type.
+// New columns are appended to the end of the row structs.
+const SchemaVersion = 1
+
+const (
+	DatasetSubscriptions = "subscriptions"
+	DatasetPriceHistory  = "price_history"
+	DatasetMonthlySpend  = "monthly_spend"
+)
+
+const (
+	PriceSourceScheduled = "scheduled"
+	PriceSourceChange    = "change"
+)
+
+// Dates are days since the Unix epoch, money is a decimal with two digits
+// after the point stored in minor units.
+type subscriptionRow struct {
+	Id            int64  `parquet:"id"`
+	UserId        string `parquet:"user_id"`
+	ServiceName   string `parquet:"service_name"`
+	Price         int64  `parquet:"price,decimal(2:18)"`
+	BillingPeriod string `parquet:"billing_period"`
+	StartDate     int32  `parquet:"start_date,date"`
+	EndDate       int32  `parquet:"end_date,optional,date"`
+	TrialEndDate  int32  `parquet:"trial_end_date,optional,date"`
+}
+
+type priceHistoryRow struct {
+	SubscriptionId int64  `parquet:"subscription_id"`
+	UserId         string `parquet:"user_id"`
+	ServiceName    string `parquet:"service_name"`
+	Source         string `parquet:"source"`
+	EffectiveDate  int32  `parquet:"effective_date,date"`
+	OldPrice       int64  `parquet:"old_price,optional,decimal(2:18)"`
+	Price          int64  `parquet:"price,decimal(2:18)"`
+}
+
+type monthlySpendRow struct {
+	Month       int32  `parquet:"month,date"`
+	UserId      string `parquet:"user_id"`
+	ServiceName string `parquet:"service_name"`
+	Amount      int64  `parquet:"amount,decimal(2:18)"`
+}
+
+func date(t time.Time) int32 {
+	if t.IsZero() {
+		return 0
+	}
+	return int32(t.Unix() / 86400)
+}
+
+func money(amount int) int64 {
+	return int64(amount) * 100
+}
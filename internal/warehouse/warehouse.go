@@ -0,0 +1,248 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"main/internal/db"
+	"main/internal/model"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+type subscriptionInfo struct {
+	userId      string
+	serviceName string
+}
+
+// exportMu runs the exports of the process one at a time, they would only
+// compete for the database and the disk.
+var exportMu sync.Mutex
+
+// Export writes every dataset as Parquet files partitioned by month:
+//
+//	<dir>/export-<time>-<random>/<dataset>/month=YYYY-MM/part-0.parquet
+//
+// Files are written to a hidden directory that is renamed once the export is
+// complete, so loaders only ever see finished exports marked with _SUCCESS.
+// The random suffix keeps exports started in the same second apart.
+func Export(ctx context.Context, storage db.Storage, dir string, from, to time.Time) (result model.WarehouseExport, err error) {
+	exportMu.Lock()
+	defer exportMu.Unlock()
+
+	now := time.Now().UTC()
+	if err = os.MkdirAll(dir, 0750); err != nil {
+		return result, fmt.Errorf("creating export directory error: %v", err)
+	}
+	tmp, err := os.MkdirTemp(dir, ".export-"+now.Format("20060102T150405Z")+"-*")
+	if err != nil {
+		return result, fmt.Errorf("creating export directory error: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+	// MkdirTemp creates the directory for the owner only.
+	if err = os.Chmod(tmp, 0750); err != nil {
+		return result, fmt.Errorf("creating export directory error: %v", err)
+	}
+	name := strings.TrimPrefix(filepath.Base(tmp), ".")
+
+	subs := newPartitions[subscriptionRow](tmp, DatasetSubscriptions)
+	defer subs.abort()
+	info := make(map[int]subscriptionInfo)
+	first := time.Time{}
+	err = storage.IterateList(ctx, model.ListFilter{}, func(dto model.SubscriptionDTO) error {
+		info[dto.Id] = subscriptionInfo{userId: dto.UserId.String(), serviceName: dto.ServiceName}
+		if first.IsZero() || dto.StartDate.Before(first) {
+			first = dto.StartDate
+		}
+		row := subscriptionRow{
+			Id:            int64(dto.Id),
+			UserId:        dto.UserId.String(),
+			ServiceName:   dto.ServiceName,
+			Price:         money(dto.Price),
+			BillingPeriod: dto.BillingPeriod,
+			StartDate:     date(dto.StartDate),
+			TrialEndDate:  date(dto.TrialEndDate),
+		}
+		if !dto.EndDate.Before(dto.StartDate) {
+			row.EndDate = date(dto.EndDate)
+		}
+		return subs.write(dto.StartDate, row)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	prices := newPartitions[priceHistoryRow](tmp, DatasetPriceHistory)
+	defer prices.abort()
+	err = storage.IterateScheduledPrices(ctx, func(dto model.ScheduledPriceDTO) error {
+		sub := info[dto.SubscriptionId]
+		return prices.write(dto.EffectiveDate, priceHistoryRow{
+			SubscriptionId: int64(dto.SubscriptionId),
+			UserId:         sub.userId,
+			ServiceName:    sub.serviceName,
+			Source:         PriceSourceScheduled,
+			EffectiveDate:  date(dto.EffectiveDate),
+			Price:          money(dto.Price),
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+	err = storage.IterateEvents(ctx, func(dto model.EventDTO) error {
+		if dto.Type != model.EventPriceChange {
+			return nil
+		}
+		return prices.write(dto.CreatedAt, priceHistoryRow{
+			SubscriptionId: int64(dto.SubscriptionId),
+			UserId:         dto.UserId.String(),
+			ServiceName:    dto.ServiceName,
+			Source:         PriceSourceChange,
+			EffectiveDate:  date(dto.CreatedAt),
+			OldPrice:       money(dto.OldPrice),
+			Price:          money(dto.NewPrice),
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	spend := newPartitions[monthlySpendRow](tmp, DatasetMonthlySpend)
+	defer spend.abort()
+	if from.IsZero() {
+		from = first
+	}
+	if to.IsZero() {
+		to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	if !from.IsZero() && !to.Before(from) {
+		months, err := storage.SpendReport(ctx, model.SpendReportDTO{StartDate: from, EndDate: to, GroupByUser: true})
+		if err != nil {
+			return result, err
+		}
+		for _, m := range months {
+			for _, service := range m.Services {
+				err = spend.write(m.Month, monthlySpendRow{
+					Month:       date(m.Month),
+					UserId:      m.UserId.String(),
+					ServiceName: service.ServiceName,
+					Amount:      money(service.Total),
+				})
+				if err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+
+	result = model.WarehouseExport{
+		Path:          filepath.Join(dir, name),
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     now,
+		From:          from,
+		To:            to,
+	}
+	for _, p := range []interface {
+		close() ([]model.WarehouseFile, error)
+	}{subs, prices, spend} {
+		files, err := p.close()
+		if err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, files...)
+	}
+	manifest, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	if err = os.WriteFile(filepath.Join(tmp, "_manifest.json"), manifest, 0640); err != nil {
+		return result, fmt.Errorf("writing export manifest error: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(tmp, "_SUCCESS"), nil, 0640); err != nil {
+		return result, fmt.Errorf("writing export marker error: %v", err)
+	}
+	if err = os.Rename(tmp, result.Path); err != nil {
+		return result, fmt.Errorf("publishing export error: %v", err)
+	}
+	return result, nil
+}
+
+type partition[T any] struct {
+	file   *os.File
+	writer *parquet.GenericWriter[T]
+	rows   int
+}
+
+type partitions[T any] struct {
+	dir     string
+	dataset string
+	parts   map[string]*partition[T]
+}
+
+func newPartitions[T any](dir, dataset string) *partitions[T] {
+	return &partitions[T]{
+		dir:     dir,
+		dataset: dataset,
+		parts:   make(map[string]*partition[T]),
+	}
+}
+
+func (p *partitions[T]) write(month time.Time, row T) error {
+	key := "month=" + month.Format("2006-01")
+	part, ok := p.parts[key]
+	if !ok {
+		dir := filepath.Join(p.dir, p.dataset, key)
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("creating partition directory error: %v", err)
+		}
+		file, err := os.Create(filepath.Join(dir, "part-0.parquet"))
+		if err != nil {
+			return fmt.Errorf("creating parquet file error: %v", err)
+		}
+		part = &partition[T]{
+			file:   file,
+			writer: parquet.NewGenericWriter[T](file, parquet.Compression(&parquet.Snappy)),
+		}
+		p.parts[key] = part
+	}
+	if _, err := part.writer.Write([]T{row}); err != nil {
+		return fmt.Errorf("writing %s parquet error: %v", p.dataset, err)
+	}
+	part.rows++
+	return nil
+}
+
+func (p *partitions[T]) close() (files []model.WarehouseFile, err error) {
+	keys := make([]string, 0, len(p.parts))
+	for key := range p.parts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		part := p.parts[key]
+		if err = part.writer.Close(); err != nil {
+			return files, fmt.Errorf("writing %s parquet error: %v", p.dataset, err)
+		}
+		if err = part.file.Close(); err != nil {
+			return files, fmt.Errorf("writing %s parquet error: %v", p.dataset, err)
+		}
+		files = append(files, model.WarehouseFile{
+			Dataset: p.dataset,
+			Path:    filepath.Join(p.dataset, key, "part-0.parquet"),
+			Rows:    part.rows,
+		})
+		delete(p.parts, key)
+	}
+	return files, nil
+}
+
+func (p *partitions[T]) abort() {
+	for _, part := range p.parts {
+		part.file.Close()
+	}
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for webhook hosts that resolve to an
+// address of the service's own network, such as loopback, private or
+// link-local ones, so webhooks cannot be used to reach internal services.
+var ErrForbiddenAddress = errors.New("webhook host resolves to a non-public address")
+
+// CheckHost resolves the host and fails unless all its addresses are public.
+func CheckHost(ctx context.Context, host string) error {
+	_, err := resolvePublic(ctx, host)
+	return err
+}
+
+// dialPublic connects only to public addresses. The check is made on the
+// resolved addresses the connection goes to, so a name that resolves to
+// another address since it was registered is refused as well.
+func dialPublic(timeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := resolvePublic(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var conn net.Conn
+		for _, ip := range ips {
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+func resolvePublic(ctx context.Context, host string) (ips []netip.Addr, err error) {
+	if ip, parseErr := netip.ParseAddr(host); parseErr == nil {
+		ips = []netip.Addr{ip}
+	} else if ips, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	for _, ip := range ips {
+		if !isPublic(ip.Unmap()) {
+			return nil, fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+	}
+	return ips, nil
+}
+
+func isPublic(ip netip.Addr) bool {
+	return ip.IsValid() && !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() && !sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not covered by
+// netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"main/internal/config"
+	"main/internal/db"
+	"main/internal/model"
+	"main/pkg/logger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Delivery request headers. The signature is an HMAC-SHA256 of the
+// timestamp, a dot and the raw body, keyed with the webhook secret:
+//
+//	X-Webhook-Signature: sha256=<hex>
+//
+// Receivers should reject requests whose timestamp is too old to prevent
+// replays by third parties.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	claimLimit = 20
+	maxBackoff = 6 * time.Hour
+)
+
+// Expirer sends the expired events of subs that ended since its last call.
+type Expirer interface {
+	NotifyExpired(ctx context.Context, now time.Time) (count int, err error)
+}
+
+// Dispatcher delivers the queued webhook events. A delivery that fails or
+// gets a non-2xx response is retried with exponential backoff until it runs
+// out of attempts, so receivers must accept the same event more than once.
+type Dispatcher struct {
+	storage      db.Storage
+	expirer      Expirer
+	client       *http.Client
+	logger       *logger.Logger
+	pollInterval time.Duration
+	timeout      time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+}
+
+func NewDispatcher(storage db.Storage, expirer Expirer, cfg *config.Config, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		storage:      storage,
+		expirer:      expirer,
+		client:       newClient(cfg.Webhooks.Timeout),
+		logger:       logger,
+		pollInterval: cfg.Webhooks.PollInterval,
+		timeout:      cfg.Webhooks.Timeout,
+		maxAttempts:  max(cfg.Webhooks.MaxAttempts, 1),
+		backoff:      cfg.Webhooks.Backoff,
+	}
+}
+
+// Run polls for expired subs and due deliveries until the context is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		d.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) poll(ctx context.Context) {
+	if _, err := d.expirer.NotifyExpired(ctx, time.Now()); err != nil {
+		d.logger.Errorln(err)
+	}
+	for ctx.Err() == nil {
+		// The lease covers every send of the batch, so a claimed delivery is
+		// not claimed again while this dispatcher still works on it.
+		deliveries, err := d.storage.ClaimDeliveries(ctx, claimLimit, claimLimit*d.timeout+time.Minute)
+		if err != nil {
+			d.logger.Errorln(err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+		for _, delivery := range deliveries {
+			result := d.send(ctx, delivery)
+			if err = d.storage.SaveDeliveryResult(context.WithoutCancel(ctx), result); err != nil {
+				d.logger.Errorln(err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery model.DeliveryDTO) (result model.DeliveryResultDTO) {
+	result = model.DeliveryResultDTO{Id: delivery.Id}
+	code, err := d.post(ctx, delivery)
+	result.ResponseCode = code
+	if err == nil {
+		result.Status = model.DeliverySucceeded
+		return result
+	}
+	result.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		d.logger.Warnf("webhook %d delivery %d failed after %d attempts: %v", delivery.WebhookId, delivery.Id, delivery.Attempts, err)
+		result.Status = model.DeliveryFailed
+		return result
+	}
+	result.Status = model.DeliveryPending
+	result.NextAttemptAt = time.Now().Add(d.retryDelay(delivery.Attempts))
+	return result
+}
+
+func (d *Dispatcher) post(ctx context.Context, delivery model.DeliveryDTO) (code int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return code, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sub_service-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.EventId)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return code, err
+	}
+	// The body is not kept, the delivery log only records the status.
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newClient returns a client that only connects to public addresses, without
+// a proxy that would connect on its behalf, and does not follow redirects,
+// which would lead deliveries to unchecked URLs. A redirect response counts
+// as a failed delivery.
+func newClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialPublic(timeout)
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// retryDelay doubles the backoff with every failed attempt.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+// Sign returns the signature header value of a delivery body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period TEXT NOT NULL DEFAULT 'monthly',
+    ADD COLUMN trial_end_date DATE;
+
+CREATE TABLE scheduled_prices (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    price INTEGER NOT NULL,
+    effective_date DATE NOT NULL
+);
+
+CREATE INDEX scheduled_prices_subscription_id_idx ON scheduled_prices (subscription_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scheduled_prices;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS trial_end_date,
+    DROP COLUMN IF EXISTS billing_period;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE subscription_events (
+    id SERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    subscription_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    service_name TEXT NOT NULL,
+    old_price INTEGER,
+    new_price INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscription_events_type_created_at_idx ON subscription_events (type, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_events;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_erasures (
+    id SERIAL PRIMARY KEY,
+    user_hash TEXT NOT NULL,
+    removed JSONB NOT NULL,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX user_erasures_user_hash_idx ON user_erasures (user_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_erasures;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE subscription_changes (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    subscription_id INTEGER NOT NULL,
+    user_id TEXT,
+    payload JSONB NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX subscription_changes_user_id_idx ON subscription_changes (user_id, id);
+CREATE INDEX subscription_changes_changed_at_idx ON subscription_changes (changed_at);
+
+-- Every row change is logged and announced on the subscription_changes
+-- channel with the id of the log entry, so changes made by any replica or
+-- directly in SQL reach the listeners. Notifications are sent on commit.
+CREATE FUNCTION notify_subscription_change() RETURNS trigger AS $$
+DECLARE
+    r subscriptions%ROWTYPE;
+    change_type TEXT;
+    change_id BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+        change_type := 'subscription.deleted';
+    ELSIF TG_OP = 'UPDATE' THEN
+        r := NEW;
+        change_type := 'subscription.updated';
+    ELSE
+        r := NEW;
+        change_type := 'subscription.created';
+    END IF;
+
+    INSERT INTO subscription_changes (type, subscription_id, user_id, payload)
+    VALUES (change_type, r.id, r.user_id, jsonb_build_object(
+        'id', r.id,
+        'service_name', r.service_name,
+        'price', r.price,
+        'user_id', r.user_id,
+        'start_date', to_char(r.start_date, 'MM-YYYY'),
+        'end_date', CASE WHEN r.end_date >= r.start_date THEN to_char(r.end_date, 'MM-YYYY') ELSE '' END,
+        'billing_period', r.billing_period,
+        'trial_end_date', COALESCE(to_char(r.trial_end_date, 'MM-YYYY'), '')
+    ))
+    RETURNING id INTO change_id;
+
+    PERFORM pg_notify('subscription_changes', change_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscriptions_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+    FOR EACH ROW EXECUTE FUNCTION notify_subscription_change();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS subscriptions_notify_change ON subscriptions;
+DROP FUNCTION IF EXISTS notify_subscription_change();
+DROP TABLE IF EXISTS subscription_changes;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Serves both search modes: the word similarity operator of the fuzzy search
+-- and the ILIKE of the prefix search. Trigrams are case-insensitive.
+CREATE INDEX subscriptions_service_name_trgm_idx ON subscriptions USING GIN (service_name gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The extension stays, other objects may have come to depend on it.
+DROP INDEX IF EXISTS subscriptions_service_name_trgm_idx;
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Readers resume the change log after the last id they saw. A sequence hands
+-- out ids before commit, so a change with a lower id could commit after a
+-- higher one was read and be skipped. The trigger now takes a transaction
+-- lock before logging a change, which serializes the writers of subscriptions
+-- from their first change to their commit.
+CREATE OR REPLACE FUNCTION notify_subscription_change() RETURNS trigger AS $$
+DECLARE
+    r subscriptions%ROWTYPE;
+    change_type TEXT;
+    change_id BIGINT;
+BEGIN
+    -- Held until commit, so the log ids are taken in commit order and a
+    -- reader that has seen an id has seen every change before it.
+    PERFORM pg_advisory_xact_lock(7120002);
+
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+        change_type := 'subscription.deleted';
+    ELSIF TG_OP = 'UPDATE' THEN
+        r := NEW;
+        change_type := 'subscription.updated';
+    ELSE
+        r := NEW;
+        change_type := 'subscription.created';
+    END IF;
+
+    INSERT INTO subscription_changes (type, subscription_id, user_id, payload)
+    VALUES (change_type, r.id, r.user_id, jsonb_build_object(
+        'id', r.id,
+        'service_name', r.service_name,
+        'price', r.price,
+        'user_id', r.user_id,
+        'start_date', to_char(r.start_date, 'MM-YYYY'),
+        'end_date', CASE WHEN r.end_date >= r.start_date THEN to_char(r.end_date, 'MM-YYYY') ELSE '' END,
+        'billing_period', r.billing_period,
+        'trial_end_date', COALESCE(to_char(r.trial_end_date, 'MM-YYYY'), '')
+    ))
+    RETURNING id INTO change_id;
+
+    PERFORM pg_notify('subscription_changes', change_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_subscription_change() RETURNS trigger AS $$
+DECLARE
+    r subscriptions%ROWTYPE;
+    change_type TEXT;
+    change_id BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+        change_type := 'subscription.deleted';
+    ELSIF TG_OP = 'UPDATE' THEN
+        r := NEW;
+        change_type := 'subscription.updated';
+    ELSE
+        r := NEW;
+        change_type := 'subscription.created';
+    END IF;
+
+    INSERT INTO subscription_changes (type, subscription_id, user_id, payload)
+    VALUES (change_type, r.id, r.user_id, jsonb_build_object(
+        'id', r.id,
+        'service_name', r.service_name,
+        'price', r.price,
+        'user_id', r.user_id,
+        'start_date', to_char(r.start_date, 'MM-YYYY'),
+        'end_date', CASE WHEN r.end_date >= r.start_date THEN to_char(r.end_date, 'MM-YYYY') ELSE '' END,
+        'billing_period', r.billing_period,
+        'trial_end_date', COALESCE(to_char(r.trial_end_date, 'MM-YYYY'), '')
+    ))
+    RETURNING id INTO change_id;
+
+    PERFORM pg_notify('subscription_changes', change_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -0,0 +1,286 @@
+// Package client is a typed Go client for the subscription API.
+//
+// Subscriptions are managed through the /api/v1 routes, which cover the
+// unversioned legacy CRUD routes. Every method takes a context, failed
+// requests come back as *Error, and idempotent requests are retried on
+// network errors and on 429, 502, 503 and 504 responses.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	defaultRetries  = 2
+	defaultBackoff  = 200 * time.Millisecond
+	maxBackoff      = 10 * time.Second
+	maxErrorBody    = 1 << 20
+	headerRequestId = "X-Request-ID"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	token      string
+	userAgent  string
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient replaces the default http.Client. Its Timeout is kept
+// unless WithTimeout comes after it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		copied := *httpClient
+		c.httpClient = &copied
+	}
+}
+
+// WithTimeout limits each attempt of a request, 0 means no limit. Event
+// streams are not limited.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithRetries sets how many times an idempotent request is retried and the
+// delay before the first retry, which doubles for each next one. A
+// Retry-After header from the server takes precedence.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = max(retries, 0)
+		c.backoff = backoff
+	}
+}
+
+// WithToken sends the token as a bearer token, which the warehouse export
+// and the webhook routes require. Both accept the same token only when
+// WAREHOUSE_EXPORT_TOKEN and WEBHOOK_TOKEN are set to the same value.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (c *Client, err error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return c, fmt.Errorf("client error, invalid base url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return c, fmt.Errorf("client error, invalid base url %q, http or https url required", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	c = &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+		userAgent:  "sub_service-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request is kept as bytes, so it can be sent again on retry.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        []byte
+	contentType string
+}
+
+func jsonRequest(method, path string, query url.Values, body any) (req request, err error) {
+	req = request{method: method, path: path, query: query}
+	if body == nil {
+		return req, nil
+	}
+	req.body, err = json.Marshal(body)
+	if err != nil {
+		return req, fmt.Errorf("client error, failed to encode request: %v", err)
+	}
+	req.contentType = "application/json"
+	return req, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, req request) (r *http.Request, err error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	r, err = http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return r, fmt.Errorf("client error, failed to create request: %v", err)
+	}
+	r.Header.Set("Accept", "application/json")
+	r.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	for key, values := range req.header {
+		r.Header[key] = values
+	}
+	return r, nil
+}
+
+// send makes the request, retrying idempotent ones, and returns the
+// response of a 2xx status. Any other status is returned as *Error.
+func (c *Client) send(ctx context.Context, httpClient *http.Client, req request) (resp *http.Response, err error) {
+	retries := 0
+	if idempotent(req.method) {
+		retries = c.retries
+	}
+	for attempt := 0; ; attempt++ {
+		r, err := c.newRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = httpClient.Do(r)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= retries {
+				return nil, fmt.Errorf("client error, %s %s: %w", req.method, req.path, err)
+			}
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return resp, nil
+		case attempt >= retries || !retryable(resp.StatusCode):
+			return nil, decodeError(resp)
+		default:
+			wait = retryAfter(resp.Header)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+		if wait == 0 {
+			wait = min(c.backoff<<attempt, maxBackoff)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("client error, %s %s: %w", req.method, req.path, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// do sends a request to an /api/v1 route and decodes the data of the
+// envelope into out.
+func (c *Client) do(ctx context.Context, req request, out any) (meta meta, err error) {
+	resp, err := c.send(ctx, c.httpClient, req)
+	if err != nil {
+		return meta, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return meta, nil
+	}
+	env := envelope{Data: out}
+	if err = json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return meta, fmt.Errorf("client error, failed to decode response: %v", err)
+	}
+	return env.Meta, nil
+}
+
+// doLegacy sends a request to an unversioned route and decodes the message
+// of the response into out.
+func (c *Client) doLegacy(ctx context.Context, req request, out any) (warnings []string, err error) {
+	resp, err := c.send(ctx, c.httpClient, req)
+	if err != nil {
+		return warnings, err
+	}
+	defer resp.Body.Close()
+	msg := respMsgSuccess{Message: out}
+	if err = json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return warnings, fmt.Errorf("client error, failed to decode response: %v", err)
+	}
+	return msg.Warnings, nil
+}
+
+type envelope struct {
+	Data  any       `json:"data"`
+	Error *apiError `json:"error"`
+	Meta  meta      `json:"meta"`
+}
+
+type apiError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details"`
+}
+
+type meta struct {
+	RequestId  string      `json:"request_id"`
+	Pagination *pagination `json:"pagination"`
+	Warnings   []string    `json:"warnings"`
+}
+
+type pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+type respMsgSuccess struct {
+	Message  any      `json:"message"`
+	Warnings []string `json:"warnings"`
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date.
+func retryAfter(header http.Header) time.Duration {
+	s := header.Get("Retry-After")
+	if s == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(s); err == nil && seconds >= 0 {
+		return min(time.Duration(seconds)*time.Second, maxBackoff)
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return min(max(time.Until(t), 0), maxBackoff)
+	}
+	return 0
+}
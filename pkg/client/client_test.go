@@ -0,0 +1,313 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient starts a server with the handler and returns a client for
+// it with the options.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+const subscriptionBody = `{"data":{"id":1,"service_name":"Yandex Plus","price":400},"error":null,"meta":{"request_id":"r1"}}`
+
+func TestRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch attempts.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, `{}`)
+		case 2:
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusServiceUnavailable, `{}`)
+		default:
+			writeJSON(w, http.StatusOK, subscriptionBody)
+		}
+	}, WithRetries(2, time.Minute))
+
+	// The backoff alone would outlast the context.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sub, err := c.GetSubscription(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Id != 1 || attempts.Load() != 3 {
+		t.Errorf("got sub %d after %d attempts, want sub 1 after 3", sub.Id, attempts.Load())
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusServiceUnavailable, `{"success":false,"message":"access token is not configured"}`)
+	}, WithRetries(2, time.Millisecond))
+
+	_, err := c.GetSubscription(context.Background(), 1)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("got %v, want ErrUnavailable", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("got %d attempts, want 3", attempts.Load())
+	}
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusServiceUnavailable, `{}`)
+	}, WithRetries(2, time.Millisecond))
+
+	_, _, err := c.CreateSubscription(context.Background(), SubRequest{ServiceName: "Yandex Plus", Price: 400})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("got %v, want ErrUnavailable", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("got %d attempts, want 1", attempts.Load())
+	}
+}
+
+func TestTimeoutPerAttempt(t *testing.T) {
+	var attempts atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+		writeJSON(w, http.StatusOK, subscriptionBody)
+	}
+
+	c := newTestClient(t, handler, WithTimeout(100*time.Millisecond), WithRetries(1, time.Millisecond))
+	if _, err := c.GetSubscription(context.Background(), 1); err != nil {
+		t.Fatalf("got %v, want the retry to succeed", err)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("got %d attempts, want 2", attempts.Load())
+	}
+
+	attempts.Store(0)
+	c = newTestClient(t, handler, WithTimeout(100*time.Millisecond), WithRetries(0, time.Millisecond))
+	start := time.Now()
+	_, err := c.GetSubscription(context.Background(), 1)
+	if err == nil {
+		t.Fatal("got no error, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request took %v, want it cut off after the timeout", elapsed)
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		call   func(c *Client) error
+		want   Error
+		is     error
+	}{
+		{
+			name:   "legacy",
+			status: http.StatusNotFound,
+			body:   `{"success":false,"message":"scheduled prices error, sub not found"}`,
+			call: func(c *Client) error {
+				_, err := c.ScheduledPrices(context.Background(), 7)
+				return err
+			},
+			want: Error{StatusCode: http.StatusNotFound, Message: "scheduled prices error, sub not found", RequestId: "h1"},
+			is:   ErrNotFound,
+		},
+		{
+			name:   "v1 envelope",
+			status: http.StatusNotFound,
+			body:   `{"data":null,"error":{"code":"not_found","message":"sub not found"},"meta":{"request_id":"r1"}}`,
+			call: func(c *Client) error {
+				_, err := c.GetSubscription(context.Background(), 7)
+				return err
+			},
+			want: Error{StatusCode: http.StatusNotFound, Code: "not_found", Message: "sub not found", RequestId: "r1"},
+			is:   ErrNotFound,
+		},
+		{
+			name:   "v1 envelope conflict",
+			status: http.StatusConflict,
+			body:   `{"data":null,"error":{"code":"conflict","message":"subscription overlaps an existing one"},"meta":{"request_id":"r2"}}`,
+			call: func(c *Client) error {
+				_, _, err := c.UpdateSubscription(context.Background(), 7, SubRequest{})
+				return err
+			},
+			want: Error{StatusCode: http.StatusConflict, Code: "conflict", Message: "subscription overlaps an existing one", RequestId: "r2"},
+			is:   ErrConflict,
+		},
+		{
+			name:   "graphql",
+			status: http.StatusBadRequest,
+			body:   `{"errors":[{"message":"unknown field"},{"message":"query too deep"}]}`,
+			call: func(c *Client) error {
+				return c.GraphQL(context.Background(), "{ subscription }", nil, nil)
+			},
+			want: Error{StatusCode: http.StatusBadRequest, Message: "unknown field; query too deep", RequestId: "h1"},
+			is:   ErrBadRequest,
+		},
+		{
+			name:   "graphql with 200",
+			status: http.StatusOK,
+			body:   `{"data":null,"errors":[{"message":"sub not found"}]}`,
+			call: func(c *Client) error {
+				return c.GraphQL(context.Background(), "{ subscription(id: 7) { id } }", nil, nil)
+			},
+			want: Error{StatusCode: http.StatusOK, Message: "sub not found", RequestId: "h1"},
+		},
+		{
+			name:   "plain text",
+			status: http.StatusBadGateway,
+			body:   "upstream down\n",
+			call: func(c *Client) error {
+				return c.DeleteSubscription(context.Background(), 7)
+			},
+			want: Error{StatusCode: http.StatusBadGateway, Message: "upstream down", RequestId: "h1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(headerRequestId, "h1")
+				writeJSON(w, tt.status, tt.body)
+			}, WithRetries(0, 0))
+
+			err := tt.call(c)
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %v, want *Error", err)
+			}
+			if apiErr.StatusCode != tt.want.StatusCode || apiErr.Code != tt.want.Code ||
+				apiErr.Message != tt.want.Message || apiErr.RequestId != tt.want.RequestId {
+				t.Errorf("got %+v, want %+v", *apiErr, tt.want)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("errors.Is(%v, %v) is false", err, tt.is)
+			}
+			if tt.is != ErrNotFound && errors.Is(err, ErrNotFound) {
+				t.Errorf("errors.Is(%v, ErrNotFound) is true", err)
+			}
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnprocessableEntity, `{"success":false,"message":"batch contains failed operations",`+
+			`"details":[{"index":0,"op":"create","id":3,"status":200},{"index":1,"op":"delete","status":404,"error":"sub not found"}]}`)
+	})
+
+	_, err := c.Batch(context.Background(), []BatchOperation{{Op: "create"}, {Op: "delete", Id: 9}}, true)
+	if !errors.Is(err, ErrUnprocessable) {
+		t.Fatalf("got %v, want ErrUnprocessable", err)
+	}
+	results := []BatchResult{}
+	if err = err.(*Error).DecodeDetails(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[1].Status != http.StatusNotFound {
+		t.Errorf("got %+v, want the two batch results", results)
+	}
+}
+
+func TestSubscriptionsPagination(t *testing.T) {
+	const total = 5
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		items := ""
+		for id := offset + 1; id <= min(offset+limit, total); id++ {
+			if items != "" {
+				items += ","
+			}
+			items += fmt.Sprintf(`{"id":%d}`, id)
+		}
+		writeJSON(w, http.StatusOK, fmt.Sprintf(`{"data":[%s],"error":null,"meta":{"pagination":{"limit":%d,"offset":%d,"total":%d}}}`,
+			items, limit, offset, total))
+	})
+
+	ids := []int{}
+	for sub, err := range c.Subscriptions(context.Background(), ListOptions{Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, sub.Id)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("got ids %v, want [1 2 3 4 5]", ids)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("got %d requests, want 3", requests.Load())
+	}
+
+	// Breaking out of the loop fetches no more pages.
+	requests.Store(0)
+	for sub, err := range c.Subscriptions(context.Background(), ListOptions{Limit: 2, Offset: 1}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.Id != 2 {
+			t.Errorf("got id %d first, want 2", sub.Id)
+		}
+		break
+	}
+	if requests.Load() != 1 {
+		t.Errorf("got %d requests, want 1", requests.Load())
+	}
+}
+
+func TestSubscriptionsPaginationError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			writeJSON(w, http.StatusBadRequest, `{"data":null,"error":{"code":"validation_error","message":"bad offset"},"meta":{}}`)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"data":[{"id":1},{"id":2}],"error":null,"meta":{"pagination":{"limit":2,"offset":0,"total":4}}}`)
+	})
+
+	ids := []int{}
+	var last error
+	for sub, err := range c.Subscriptions(context.Background(), ListOptions{Limit: 2}) {
+		if err != nil {
+			last = err
+			continue
+		}
+		ids = append(ids, sub.Id)
+	}
+	if fmt.Sprint(ids) != "[1 2]" || !errors.Is(last, ErrBadRequest) {
+		t.Errorf("got ids %v and %v, want [1 2] and a bad request", ids, last)
+	}
+}
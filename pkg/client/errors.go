@@ -0,0 +1,110 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The sentinel errors match an *Error by its status, e.g.
+// errors.Is(err, client.ErrNotFound).
+var (
+	ErrBadRequest    = errors.New("bad request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrNotFound      = errors.New("not found")
+	ErrConflict      = errors.New("conflict")
+	ErrUnprocessable = errors.New("unprocessable")
+	ErrUnavailable   = errors.New("unavailable")
+)
+
+// Error is a response with a non-2xx status. It is decoded from the
+// RespMsgError of the unversioned routes, the envelope error of the /api/v1
+// routes and the errors of a GraphQL response.
+type Error struct {
+	StatusCode int
+	// Code is the error code of the /api/v1 routes, e.g. validation_error.
+	Code    string
+	Message string
+	// Details holds the per item results of a rejected batch or import.
+	Details   json.RawMessage
+	RequestId string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnprocessable:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// DecodeDetails decodes the details of the error into v, e.g. the
+// []BatchResult of a rejected batch.
+func (e *Error) DecodeDetails(v any) error {
+	if len(e.Details) == 0 {
+		return fmt.Errorf("client error, the error has no details")
+	}
+	return json.Unmarshal(e.Details, v)
+}
+
+func decodeError(resp *http.Response) error {
+	defer resp.Body.Close()
+	e := &Error{StatusCode: resp.StatusCode, RequestId: resp.Header.Get(headerRequestId)}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	payload := struct {
+		Message json.RawMessage `json:"message"`
+		Details json.RawMessage `json:"details"`
+		Error   *apiError       `json:"error"`
+		Meta    meta            `json:"meta"`
+		Errors  []GraphQLError  `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		switch {
+		case payload.Error != nil:
+			e.Code = payload.Error.Code
+			e.Message = payload.Error.Message
+			e.Details = payload.Error.Details
+			if payload.Meta.RequestId != "" {
+				e.RequestId = payload.Meta.RequestId
+			}
+		case len(payload.Errors) > 0:
+			messages := make([]string, 0, len(payload.Errors))
+			for _, gqlErr := range payload.Errors {
+				messages = append(messages, gqlErr.Message)
+			}
+			e.Message = strings.Join(messages, "; ")
+		default:
+			if json.Unmarshal(payload.Message, &e.Message) != nil {
+				e.Message = string(payload.Message)
+			}
+			e.Details = payload.Details
+		}
+	}
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEventSize bounds a line of the change stream.
+const maxEventSize = 1 << 20
+
+var errStopped = errors.New("stopped")
+
+// Changes yields the subscription changes the server streams over
+// Server-Sent Events, from opts.AfterId on. A dropped stream is resumed
+// after the last change received, the error is yielded once the retries of
+// the client fail in a row. The iteration ends when ctx is done.
+func (c *Client) Changes(ctx context.Context, opts ChangeOptions) iter.Seq2[SubscriptionChange, error] {
+	return func(yield func(SubscriptionChange, error) bool) {
+		// The stream lasts as long as ctx, not the timeout of a request.
+		httpClient := *c.httpClient
+		httpClient.Timeout = 0
+		lastId := opts.AfterId
+		wait := c.backoff
+		failures := 0
+		for {
+			err := c.readChanges(ctx, &httpClient, opts, lastId, func(change SubscriptionChange, retry time.Duration) bool {
+				if retry > 0 {
+					wait = retry
+					return true
+				}
+				lastId = change.Id
+				failures = 0
+				return yield(change, nil)
+			})
+			var apiErr *Error
+			switch {
+			case errors.Is(err, errStopped), ctx.Err() != nil:
+				return
+			case errors.As(err, &apiErr):
+				yield(SubscriptionChange{}, err)
+				return
+			}
+			if failures++; failures > c.retries {
+				yield(SubscriptionChange{}, err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// readChanges reads one connection of the change stream and passes each
+// change, or the reconnect delay sent by the server, to handle. It always
+// returns an error, as the server does not end the stream on its own.
+func (c *Client) readChanges(ctx context.Context, httpClient *http.Client, opts ChangeOptions, lastId int64,
+	handle func(change SubscriptionChange, retry time.Duration) bool) error {
+	query := url.Values{}
+	setUUID(query, "user_id", opts.UserId)
+	req := request{method: http.MethodGet, path: "/subscriptions/events", query: query,
+		header: http.Header{"Accept": {"text/event-stream"}}}
+	if lastId > 0 {
+		req.header.Set("Last-Event-ID", strconv.FormatInt(lastId, 10))
+	}
+	resp, err := c.send(ctx, httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxEventSize)
+	event, data := "", []string{}
+	for scanner.Scan() {
+		field, value, _ := strings.Cut(scanner.Text(), ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 && !handle(SubscriptionChange{}, time.Duration(ms)*time.Millisecond) {
+				return errStopped
+			}
+		case "":
+			// A blank line ends the event, comment lines start with a colon.
+			if scanner.Text() != "" {
+				continue
+			}
+			if len(data) == 0 {
+				event = ""
+				continue
+			}
+			if event == "error" {
+				return fmt.Errorf("client error, change stream interrupted: %s", strings.Join(data, "\n"))
+			}
+			change := SubscriptionChange{}
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &change); err != nil {
+				return fmt.Errorf("client error, failed to decode change: %v", err)
+			}
+			if !handle(change, 0) {
+				return errStopped
+			}
+			event, data = "", data[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("client error, change stream dropped: %w", err)
+	}
+	return fmt.Errorf("client error, change stream dropped: %w", io.ErrUnexpectedEOF)
+}
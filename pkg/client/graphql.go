@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQL runs a query and decodes its data into out. Errors of a query
+// that the server answered with 200 are returned as *Error too, with the
+// partial data still decoded.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	req, err := jsonRequest(http.MethodPost, "/graphql", nil, GraphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, c.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	result := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("client error, failed to decode response: %v", err)
+	}
+	if out != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		if err = json.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("client error, failed to decode data: %v", err)
+		}
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, e.Message)
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: strings.Join(messages, "; "),
+			RequestId: resp.Header.Get(headerRequestId)}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Spend report formats besides the default JSON.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// SpendReport returns the spend per month and service, and per user with
+// opts.GroupByUser.
+func (c *Client) SpendReport(ctx context.Context, opts SpendOptions) (months []MonthSpend, err error) {
+	req := request{method: http.MethodGet, path: "/reports/spend", query: spendQuery(opts)}
+	_, err = c.doLegacy(ctx, req, &months)
+	return months, err
+}
+
+// DownloadSpendReport writes the spend report as FormatCSV or FormatXLSX
+// to w.
+func (c *Client) DownloadSpendReport(ctx context.Context, opts SpendOptions, format string, w io.Writer) error {
+	query := spendQuery(opts)
+	query.Set("format", format)
+	return c.download(ctx, request{method: http.MethodGet, path: "/reports/spend", query: query}, w)
+}
+
+func spendQuery(opts SpendOptions) url.Values {
+	query := url.Values{}
+	setString(query, "from", opts.From)
+	setString(query, "to", opts.To)
+	setUUID(query, "user_id", opts.UserId)
+	if opts.GroupByUser {
+		query.Set("group_by", "user")
+	}
+	return query
+}
+
+// PopularServices ranks the services by subscribers or revenue.
+func (c *Client) PopularServices(ctx context.Context, opts AnalyticsOptions) (services []ServicePopularity, err error) {
+	query := analyticsQuery(opts)
+	setString(query, "by", opts.By)
+	setInt(query, "limit", opts.Limit)
+	_, err = c.doLegacy(ctx, request{method: http.MethodGet, path: "/analytics/popular-services", query: query}, &services)
+	return services, err
+}
+
+func (c *Client) PriceStats(ctx context.Context, opts AnalyticsOptions) (stats []ServicePriceStats, err error) {
+	req := request{method: http.MethodGet, path: "/analytics/prices", query: analyticsQuery(opts)}
+	_, err = c.doLegacy(ctx, req, &stats)
+	return stats, err
+}
+
+func (c *Client) Churn(ctx context.Context, opts AnalyticsOptions) (months []MonthChurn, err error) {
+	req := request{method: http.MethodGet, path: "/analytics/churn", query: analyticsQuery(opts)}
+	_, err = c.doLegacy(ctx, req, &months)
+	return months, err
+}
+
+func (c *Client) Lifetime(ctx context.Context, opts AnalyticsOptions) (lifetime SubscriptionLifetime, err error) {
+	req := request{method: http.MethodGet, path: "/analytics/lifetime", query: analyticsQuery(opts)}
+	_, err = c.doLegacy(ctx, req, &lifetime)
+	return lifetime, err
+}
+
+func analyticsQuery(opts AnalyticsOptions) url.Values {
+	query := url.Values{}
+	setString(query, "from", opts.From)
+	setString(query, "to", opts.To)
+	return query
+}
+
+// PriceIncreases returns the price increases and the prices far above the
+// service median.
+func (c *Client) PriceIncreases(ctx context.Context, opts AlertOptions) (alerts PriceAlerts, err error) {
+	query := url.Values{}
+	setString(query, "since", opts.Since)
+	setUUID(query, "user_id", opts.UserId)
+	setString(query, "service_name", opts.ServiceName)
+	_, err = c.doLegacy(ctx, request{method: http.MethodGet, path: "/alerts/price-increases", query: query}, &alerts)
+	return alerts, err
+}
+
+// ExportWarehouse writes the Parquet export to the configured warehouse
+// bucket. It needs the export token set with WithToken.
+func (c *Client) ExportWarehouse(ctx context.Context, opts ExportOptions) (export WarehouseExport, err error) {
+	query := url.Values{}
+	setString(query, "from", opts.From)
+	setString(query, "to", opts.To)
+	_, err = c.doLegacy(ctx, request{method: http.MethodPost, path: "/exports/parquet", query: query}, &export)
+	return export, err
+}
+
+// download copies the body of a file response to w. It is not retried once
+// copying has started.
+func (c *Client) download(ctx context.Context, req request, w io.Writer) error {
+	resp, err := c.send(ctx, c.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if n, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("client error, download failed after %d bytes: %v", n, err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const subscriptionsPath = "/api/v1/subscriptions"
+
+// CreateSubscription returns the created subscription and a warning for
+// every subscription of the user to the same service it overlaps.
+func (c *Client) CreateSubscription(ctx context.Context, sub SubRequest) (created Subscription, warnings []string, err error) {
+	req, err := jsonRequest(http.MethodPost, subscriptionsPath, nil, sub)
+	if err != nil {
+		return created, warnings, err
+	}
+	meta, err := c.do(ctx, req, &created)
+	return created, meta.Warnings, err
+}
+
+func (c *Client) GetSubscription(ctx context.Context, id int) (sub Subscription, err error) {
+	req := request{method: http.MethodGet, path: subscriptionsPath + "/" + strconv.Itoa(id)}
+	_, err = c.do(ctx, req, &sub)
+	return sub, err
+}
+
+// UpdateSubscription replaces the subscription and returns it with the
+// overlap warnings, like CreateSubscription.
+func (c *Client) UpdateSubscription(ctx context.Context, id int, sub SubRequest) (updated Subscription, warnings []string, err error) {
+	req, err := jsonRequest(http.MethodPatch, subscriptionsPath+"/"+strconv.Itoa(id), nil, sub)
+	if err != nil {
+		return updated, warnings, err
+	}
+	meta, err := c.do(ctx, req, &updated)
+	return updated, meta.Warnings, err
+}
+
+func (c *Client) DeleteSubscription(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: subscriptionsPath + "/" + strconv.Itoa(id)}, nil)
+	return err
+}
+
+// ListSubscriptions returns one page of subscriptions, use Subscriptions to
+// go through all of them.
+func (c *Client) ListSubscriptions(ctx context.Context, opts ListOptions) (page Page[Subscription], err error) {
+	query := url.Values{}
+	setUUID(query, "user_id", opts.UserId)
+	setString(query, "service_name", opts.ServiceName)
+	setInt(query, "limit", opts.Limit)
+	setInt(query, "offset", opts.Offset)
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: subscriptionsPath, query: query}, &page.Items)
+	if err != nil {
+		return page, err
+	}
+	if meta.Pagination != nil {
+		page.Limit = meta.Pagination.Limit
+		page.Offset = meta.Pagination.Offset
+		page.Total = meta.Pagination.Total
+	}
+	return page, nil
+}
+
+// Subscriptions yields every subscription matching the options, fetching
+// the pages as it goes from opts.Offset on. It stops after the first error.
+func (c *Client) Subscriptions(ctx context.Context, opts ListOptions) iter.Seq2[Subscription, error] {
+	return paginate(opts.Offset, func(offset int) ([]Subscription, bool, error) {
+		opts.Offset = offset
+		page, err := c.ListSubscriptions(ctx, opts)
+		return page.Items, offset+len(page.Items) >= page.Total, err
+	})
+}
+
+// Cost sums what the user paid for the service over the months.
+func (c *Client) Cost(ctx context.Context, opts CostOptions) (cost CostResult, err error) {
+	query := url.Values{}
+	setUUID(query, "user_id", opts.UserId)
+	setString(query, "service_name", opts.ServiceName)
+	setString(query, "start", opts.Start)
+	setString(query, "end", opts.End)
+	_, err = c.do(ctx, request{method: http.MethodGet, path: subscriptionsPath + "/cost", query: query}, &cost)
+	return cost, err
+}
+
+// Search finds the subscriptions whose service name is like the query, best
+// match first.
+func (c *Client) Search(ctx context.Context, q string, opts SearchOptions) (results []SearchResult, err error) {
+	query := url.Values{"q": {q}}
+	setUUID(query, "user_id", opts.UserId)
+	setString(query, "mode", opts.Mode)
+	setInt(query, "limit", opts.Limit)
+	_, err = c.doLegacy(ctx, request{method: http.MethodGet, path: "/subscriptions/search", query: query}, &results)
+	return results, err
+}
+
+// Batch applies create, update and delete operations in one request. With
+// atomic set, one failed operation rolls back the others and the request
+// fails with ErrUnprocessable, the results are in the details of the error.
+func (c *Client) Batch(ctx context.Context, ops []BatchOperation, atomic bool) (results []BatchResult, err error) {
+	query := url.Values{"atomic": {strconv.FormatBool(atomic)}}
+	req, err := jsonRequest(http.MethodPost, "/subscriptions/batch", query, struct {
+		Operations []BatchOperation `json:"operations"`
+	}{ops})
+	if err != nil {
+		return results, err
+	}
+	_, err = c.doLegacy(ctx, req, &results)
+	return results, err
+}
+
+// Import inserts the subscriptions of a CSV file, or only validates them on
+// a dry run. A file with invalid rows fails with ErrUnprocessable and the
+// ImportReport in the details of the error.
+func (c *Client) Import(ctx context.Context, filename string, csv io.Reader, dryRun bool) (report ImportReport, err error) {
+	req, err := fileRequest(http.MethodPost, "/subscriptions/import", filename, csv)
+	if err != nil {
+		return report, err
+	}
+	req.query = url.Values{"dry_run": {strconv.FormatBool(dryRun)}}
+	_, err = c.doLegacy(ctx, req, &report)
+	return report, err
+}
+
+// SchedulePrice sets a new price of the subscription from a future month on
+// and returns the ID of the scheduled price.
+func (c *Client) SchedulePrice(ctx context.Context, id int, price ScheduledPriceRequest) (priceId int, err error) {
+	req, err := jsonRequest(http.MethodPost, "/subscriptions/"+strconv.Itoa(id)+"/prices", nil, price)
+	if err != nil {
+		return priceId, err
+	}
+	msg := ""
+	if _, err = c.doLegacy(ctx, req, &msg); err != nil {
+		return priceId, err
+	}
+	if _, err = fmt.Sscanf(msg, "created new scheduled price with id: %d", &priceId); err != nil {
+		return priceId, fmt.Errorf("client error, unexpected response %q", msg)
+	}
+	return priceId, nil
+}
+
+func (c *Client) ScheduledPrices(ctx context.Context, id int) (prices []ScheduledPrice, err error) {
+	req := request{method: http.MethodGet, path: "/subscriptions/" + strconv.Itoa(id) + "/prices"}
+	_, err = c.doLegacy(ctx, req, &prices)
+	return prices, err
+}
+
+// paginate yields the items of the pages fetch returns until one is the
+// last or empty.
+func paginate[T any](offset int, fetch func(offset int) (items []T, last bool, err error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, last, err := fetch(offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(items)
+			if last || len(items) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// fileRequest builds a multipart form with the file in the "file" field.
+func fileRequest(method, path, filename string, file io.Reader) (req request, err error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return req, fmt.Errorf("client error, failed to create form: %v", err)
+	}
+	if _, err = io.Copy(part, file); err != nil {
+		return req, fmt.Errorf("client error, failed to read file: %v", err)
+	}
+	if err = form.Close(); err != nil {
+		return req, fmt.Errorf("client error, failed to create form: %v", err)
+	}
+	return request{method: method, path: path, body: body.Bytes(), contentType: form.FormDataContentType()}, nil
+}
+
+func setString(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setInt(query url.Values, key string, value int) {
+	if value != 0 {
+		query.Set(key, strconv.Itoa(value))
+	}
+}
+
+func setUUID(query url.Values, key string, value uuid.UUID) {
+	if value != uuid.Nil {
+		query.Set(key, value.String())
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"main/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// The API types are shared with the server, so they cannot drift apart.
+type (
+	Subscription          = model.Subscription
+	SubRequest            = model.SubRequest
+	CostResult            = model.CostResult
+	SearchResult          = model.SearchResult
+	BatchOperation        = model.BatchOperation
+	BatchResult           = model.BatchResult
+	ImportReport          = model.ImportReport
+	MonthSpend            = model.MonthSpend
+	ServiceSpend          = model.ServiceSpend
+	ServicePopularity     = model.ServicePopularity
+	ServicePriceStats     = model.ServicePriceStats
+	MonthChurn            = model.MonthChurn
+	SubscriptionLifetime  = model.SubscriptionLifetime
+	Forecast              = model.Forecast
+	DuplicatePair         = model.DuplicatePair
+	ErasureReceipt        = model.ErasureReceipt
+	ChargeCandidate       = model.ChargeCandidate
+	ScheduledPrice        = model.ScheduledPrice
+	ScheduledPriceRequest = model.ScheduledPriceRequest
+	PriceAlerts           = model.PriceAlerts
+	WarehouseExport       = model.WarehouseExport
+	Webhook               = model.Webhook
+	WebhookRequest        = model.WebhookRequest
+	WebhookDelivery       = model.WebhookDelivery
+	SubscriptionChange    = model.SubscriptionChange
+	GraphQLRequest        = model.GraphQLRequest
+	GraphQLResponse       = model.GraphQLResponse
+	GraphQLError          = model.GraphQLError
+)
+
+const (
+	BillingMonthly = model.BillingMonthly
+	BillingYearly  = model.BillingYearly
+
+	SearchFuzzy  = model.SearchFuzzy
+	SearchPrefix = model.SearchPrefix
+
+	BatchCreate = model.BatchCreate
+	BatchUpdate = model.BatchUpdate
+	BatchDelete = model.BatchDelete
+)
+
+// Page is one page of a list and the total number of items.
+type Page[T any] struct {
+	Items  []T
+	Limit  int
+	Offset int
+	Total  int
+}
+
+// ListOptions filters the subscriptions, zero values are left out. The
+// server limits a page to 50 items by default and 1000 at most.
+type ListOptions struct {
+	UserId      uuid.UUID
+	ServiceName string
+	Limit       int
+	Offset      int
+}
+
+// CostOptions selects the subscriptions to sum, all fields are required.
+// Months are written MM-YYYY.
+type CostOptions struct {
+	UserId      uuid.UUID
+	ServiceName string
+	Start       string
+	End         string
+}
+
+// SearchOptions narrows a search by service name. Mode is SearchFuzzy
+// (default) or SearchPrefix, the server returns 20 results by default and
+// 100 at most.
+type SearchOptions struct {
+	UserId uuid.UUID
+	Mode   string
+	Limit  int
+}
+
+// DeliveryOptions filters the deliveries of a webhook by status: pending,
+// succeeded or failed. The server limits a page to 50 items by default and
+// 500 at most.
+type DeliveryOptions struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// SpendOptions selects the months of the spend report, From and To are
+// required. GroupByUser returns a row per user and month.
+type SpendOptions struct {
+	From        string
+	To          string
+	UserId      uuid.UUID
+	GroupByUser bool
+}
+
+// AnalyticsOptions selects the months to analyse, From and To are required.
+// By and Limit only apply to the popular services: By ranks them by
+// subscribers (default) or revenue.
+type AnalyticsOptions struct {
+	From  string
+	To    string
+	By    string
+	Limit int
+}
+
+// AlertOptions filters the price alerts, Since defaults to three months ago
+// on the server.
+type AlertOptions struct {
+	Since       string
+	UserId      uuid.UUID
+	ServiceName string
+}
+
+// ExportOptions selects the months of the warehouse export, both are
+// optional.
+type ExportOptions struct {
+	From string
+	To   string
+}
+
+// ChangeOptions filters the change stream. AfterId resumes after a change
+// already received.
+type ChangeOptions struct {
+	UserId  uuid.UUID
+	AfterId int64
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+func userPath(userId uuid.UUID, suffix string) string {
+	return "/users/" + userId.String() + suffix
+}
+
+// Forecast projects the spend of the user for the next months, 12 when
+// months is 0.
+func (c *Client) Forecast(ctx context.Context, userId uuid.UUID, months int) (forecast Forecast, err error) {
+	query := url.Values{}
+	setInt(query, "months", months)
+	_, err = c.doLegacy(ctx, request{method: http.MethodGet, path: userPath(userId, "/forecast"), query: query}, &forecast)
+	return forecast, err
+}
+
+// Duplicates returns the pairs of subscriptions of the user that look like
+// the same subscription entered twice.
+func (c *Client) Duplicates(ctx context.Context, userId uuid.UUID) (pairs []DuplicatePair, err error) {
+	_, err = c.doLegacy(ctx, request{method: http.MethodGet, path: userPath(userId, "/duplicates")}, &pairs)
+	return pairs, err
+}
+
+// ExportUserData writes the zip archive with everything stored about the
+// user to w.
+func (c *Client) ExportUserData(ctx context.Context, userId uuid.UUID, w io.Writer) error {
+	return c.download(ctx, request{method: http.MethodGet, path: userPath(userId, "/export")}, w)
+}
+
+// EraseUserData deletes everything stored about the user.
+func (c *Client) EraseUserData(ctx context.Context, userId uuid.UUID) (receipt ErasureReceipt, err error) {
+	_, err = c.doLegacy(ctx, request{method: http.MethodDelete, path: userPath(userId, "/data")}, &receipt)
+	return receipt, err
+}
+
+// AnalyzeStatement finds recurring charges in an OFX or CSV bank statement
+// that are not tracked as subscriptions yet. The format is taken from the
+// extension of filename.
+func (c *Client) AnalyzeStatement(ctx context.Context, userId uuid.UUID, filename string, statement io.Reader) (candidates []ChargeCandidate, err error) {
+	req, err := fileRequest(http.MethodPost, userPath(userId, "/statements/analyze"), filename, statement)
+	if err != nil {
+		return candidates, err
+	}
+	_, err = c.doLegacy(ctx, req, &candidates)
+	return candidates, err
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const webhooksPath = "/api/v1/webhooks"
+
+// CreateWebhook registers a webhook. The returned webhook holds the signing
+// secret, which is generated when the request has none and is not returned
+// again.
+func (c *Client) CreateWebhook(ctx context.Context, webhook WebhookRequest) (created Webhook, err error) {
+	req, err := jsonRequest(http.MethodPost, webhooksPath, nil, webhook)
+	if err != nil {
+		return created, err
+	}
+	_, err = c.do(ctx, req, &created)
+	return created, err
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) (webhooks []Webhook, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: webhooksPath}, &webhooks)
+	return webhooks, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int) (webhook Webhook, err error) {
+	_, err = c.do(ctx, request{method: http.MethodGet, path: webhooksPath + "/" + strconv.Itoa(id)}, &webhook)
+	return webhook, err
+}
+
+// UpdateWebhook changes only the fields set in the request.
+func (c *Client) UpdateWebhook(ctx context.Context, id int, webhook WebhookRequest) (updated Webhook, err error) {
+	req, err := jsonRequest(http.MethodPatch, webhooksPath+"/"+strconv.Itoa(id), nil, webhook)
+	if err != nil {
+		return updated, err
+	}
+	_, err = c.do(ctx, req, &updated)
+	return updated, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: webhooksPath + "/" + strconv.Itoa(id)}, nil)
+	return err
+}
+
+// ListDeliveries returns one page of deliveries of the webhook, newest
+// first. The server does not count them, so the Total of the page is 0.
+func (c *Client) ListDeliveries(ctx context.Context, webhookId int, opts DeliveryOptions) (page Page[WebhookDelivery], err error) {
+	query := url.Values{}
+	setString(query, "status", opts.Status)
+	setInt(query, "limit", opts.Limit)
+	setInt(query, "offset", opts.Offset)
+	req := request{method: http.MethodGet, path: webhooksPath + "/" + strconv.Itoa(webhookId) + "/deliveries", query: query}
+	if _, err = c.do(ctx, req, &page.Items); err != nil {
+		return page, err
+	}
+	page.Limit = opts.Limit
+	page.Offset = opts.Offset
+	return page, nil
+}
+
+// Deliveries yields every delivery of the webhook matching the options. The
+// last page is the first one shorter than opts.Limit, 50 when not set.
+func (c *Client) Deliveries(ctx context.Context, webhookId int, opts DeliveryOptions) iter.Seq2[WebhookDelivery, error] {
+	if opts.Limit == 0 {
+		opts.Limit = 50
+	}
+	return paginate(opts.Offset, func(offset int) ([]WebhookDelivery, bool, error) {
+		opts.Offset = offset
+		page, err := c.ListDeliveries(ctx, webhookId, opts)
+		return page.Items, len(page.Items) < opts.Limit, err
+	})
+}
+
+// ReplayDelivery queues the event of a delivery again with the same event ID
+// and returns the ID of the new delivery.
+func (c *Client) ReplayDelivery(ctx context.Context, webhookId, deliveryId int) (newId int, err error) {
+	path := webhooksPath + "/" + strconv.Itoa(webhookId) + "/deliveries/" + strconv.Itoa(deliveryId) + "/replay"
+	_, err = c.do(ctx, request{method: http.MethodPost, path: path}, &newId)
+	return newId, err
+}